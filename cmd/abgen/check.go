@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/origadmin/abgen/internal/diff"
+)
+
+// versionLine matches the line of a generated file header naming the abgen version that
+// generated the file.
+var versionLine = regexp.MustCompile(`(?m)^// versions: (\S+)$`)
+
+// checkFiles compares the generated files with their content on disk, writing a unified diff
+// for each file that is missing or out of date to w. A file generated by another version of
+// abgen is reported as such, and so are the changed mappings of the lockfile. It returns the
+// number of files out of date.
+func checkFiles(w io.Writer, files []*outputFile) (int, error) {
+	stale := 0
+	for _, file := range files {
+		if bytes.Equal(file.Content, file.Existing) {
+			continue
+		}
+		stale++
+		switch {
+		case file.Lock && file.Existing != nil:
+			if _, err := fmt.Fprintf(w, "%s: mappings changed, review them and run abgen -lock to refresh the lockfile\n",
+				file.Path); err != nil {
+				return stale, err
+			}
+		case file.Existing == nil:
+			if _, err := fmt.Fprintf(w, "%s: missing %s\n", file.Path, file.Description); err != nil {
+				return stale, err
+			}
+		default:
+			existingVersion, currentVersion := headerVersion(file.Existing), headerVersion(file.Content)
+			if existingVersion != "" && currentVersion != "" && existingVersion != currentVersion {
+				if _, err := fmt.Fprintf(w, "%s: generated by abgen %s, this is abgen %s\n",
+					file.Path, existingVersion, currentVersion); err != nil {
+					return stale, err
+				}
+			}
+		}
+		if _, err := io.WriteString(w, diff.Unified(file.Path, file.Existing, file.Content)); err != nil {
+			return stale, err
+		}
+	}
+	return stale, nil
+}
+
+// headerVersion returns the abgen version named in the header of a generated file, or an
+// empty string if it names none.
+func headerVersion(content []byte) string {
+	if match := versionLine.FindSubmatch(content); match != nil {
+		return string(match[1])
+	}
+	return ""
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/origadmin/abgen/internal/config"
+)
+
+// dumpConfigs analyzes the directive packages in sourceDirs and writes the configuration each
+// ends up with, once its configuration file and directives are merged, to w in the given
+// format. Several packages are written as separate YAML documents or as a JSON array.
+func dumpConfigs(w io.Writer, sourceDirs []string, format string) error {
+	if format != "yaml" && format != "json" {
+		return fmt.Errorf("unknown configuration format %q, expected yaml or json", format)
+	}
+	analysisResults, err := analyzePackages(sourceDirs)
+	if err != nil {
+		return err
+	}
+
+	files := make([]*config.File, len(analysisResults))
+	for i, analysisResult := range analysisResults {
+		files[i] = config.NewFile(analysisResult.ExecutionPlan.FinalConfig)
+	}
+	if format == "json" {
+		if len(files) == 1 {
+			return files[0].WriteJSON(w)
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(files)
+	}
+	for i, file := range files {
+		if len(files) > 1 {
+			separator := "# " + sourceDirs[i] + "\n"
+			if i > 0 {
+				separator = "---\n" + separator
+			}
+			if _, err := io.WriteString(w, separator); err != nil {
+				return err
+			}
+		}
+		if err := file.WriteYAML(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/origadmin/abgen/internal/analyzer"
+	"github.com/origadmin/abgen/internal/drift"
+	"github.com/origadmin/abgen/internal/generator"
+)
+
+// driftPackages reports the drift from the types of the package at sourcePath to those of the
+// package at targetPath to w in the given format, leaving out the items of the baseline file
+// if any, and returns the number of items reported.
+func driftPackages(w io.Writer, sourcePath, targetPath, format, baselineFile string) (int, error) {
+	if format != "text" && format != "json" && format != "markdown" {
+		return 0, fmt.Errorf("unknown drift format %q, expected text, json or markdown", format)
+	}
+
+	analysisResult, err := analyzer.NewTypeAnalyzer().AnalyzePackagePair(sourcePath, targetPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to analyze %s and %s: %w", sourcePath, targetPath, err)
+	}
+	response, err := generator.Generate(analysisResult)
+	if err != nil {
+		return 0, err
+	}
+	report := drift.Build(sourcePath, targetPath, analysisResult.TypeInfos, response.Conversions)
+
+	if baselineFile != "" {
+		file, err := os.Open(baselineFile)
+		if err != nil {
+			return 0, fmt.Errorf("failed to open the baseline: %w", err)
+		}
+		defer file.Close()
+		baseline, err := drift.Read(file)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read the baseline %s: %w", baselineFile, err)
+		}
+		report = report.Without(baseline)
+	}
+
+	switch format {
+	case "json":
+		err = report.WriteJSON(w)
+	case "markdown":
+		err = report.WriteMarkdown(w)
+	default:
+		err = report.WriteText(w)
+	}
+	return len(report.Items), err
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/origadmin/abgen/internal/config"
+	"github.com/origadmin/abgen/internal/generator"
+	"github.com/origadmin/abgen/internal/model"
+)
+
+// packageExplanation is the conversion plan of a directive package, as printed by explain.
+type packageExplanation struct {
+	Dir         string                    `json:"dir"`
+	Package     string                    `json:"package"`
+	Overrides   []string                  `json:"overrides,omitempty"`
+	Rules       []*ruleExplanation        `json:"rules"`
+	Conversions []*model.ConversionReport `json:"conversions"`
+}
+
+// ruleExplanation is an active rule of the execution plan.
+type ruleExplanation struct {
+	Source     string                     `json:"source"`
+	Target     string                     `json:"target"`
+	Direction  config.ConversionDirection `json:"direction"`
+	Origin     config.RuleOrigin          `json:"origin"`
+	CustomFunc string                     `json:"customFunc,omitempty"`
+}
+
+// explainPackages analyzes and generates the directive packages in sourceDirs without writing
+// anything, and writes their active rules and the decisions taken for each field of their
+// struct conversions to w, as text or as a JSON array.
+func explainPackages(w io.Writer, sourceDirs []string, asJSON bool) error {
+	analysisResults, err := analyzePackages(sourceDirs)
+	if err != nil {
+		return err
+	}
+
+	explanations := make([]*packageExplanation, len(sourceDirs))
+	for i, analysisResult := range analysisResults {
+		response, err := generator.Generate(analysisResult)
+		if err != nil {
+			return fmt.Errorf("%s: %w", sourceDirs[i], err)
+		}
+		explanations[i] = explainPackage(sourceDirs[i], analysisResult.ExecutionPlan, response)
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(explanations)
+	}
+	for i, explanation := range explanations {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if err := writeExplanation(w, explanation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// explainPackage builds the explanation of the plan of the directive package in sourceDir.
+func explainPackage(sourceDir string, plan *model.ExecutionPlan, response *model.GenerationResponse) *packageExplanation {
+	explanation := &packageExplanation{
+		Dir:         sourceDir,
+		Package:     plan.FinalConfig.GenerationContext.PackagePath,
+		Overrides:   plan.FinalConfig.Overrides,
+		Rules:       make([]*ruleExplanation, 0, len(plan.ActiveRules)),
+		Conversions: response.Conversions,
+	}
+	for _, rule := range plan.ActiveRules {
+		explanation.Rules = append(explanation.Rules, &ruleExplanation{
+			Source:     rule.SourceType,
+			Target:     rule.TargetType,
+			Direction:  rule.Direction,
+			Origin:     rule.Origin,
+			CustomFunc: rule.CustomFunc,
+		})
+	}
+	if explanation.Conversions == nil {
+		explanation.Conversions = []*model.ConversionReport{}
+	}
+	return explanation
+}
+
+// writeExplanation writes the explanation of a directive package as aligned text.
+func writeExplanation(w io.Writer, explanation *packageExplanation) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "package %s (%s)\n", explanation.Package, explanation.Dir)
+
+	if len(explanation.Overrides) > 0 {
+		fmt.Fprintf(tw, "\noverrides (%s):\n", config.OriginCLI)
+		for _, override := range explanation.Overrides {
+			fmt.Fprintf(tw, "  %s\n", override)
+		}
+	}
+
+	fmt.Fprintf(tw, "\nrules:\n")
+	for _, rule := range explanation.Rules {
+		fmt.Fprintf(tw, "  %s\t%s -> %s\t%s", rule.Origin, rule.Source, rule.Target, rule.Direction)
+		if rule.CustomFunc != "" {
+			fmt.Fprintf(tw, "\tfunc=%s", rule.CustomFunc)
+		}
+		fmt.Fprintln(tw)
+	}
+
+	for _, conversion := range explanation.Conversions {
+		fmt.Fprintf(tw, "\n%s: %s -> %s", conversion.Function, conversion.Source, conversion.Target)
+		if conversion.Origin != "" {
+			fmt.Fprintf(tw, " (%s)", conversion.Origin)
+		}
+		fmt.Fprintln(tw)
+		for _, field := range conversion.Fields {
+			source, expression := "-", "-"
+			if field.Source != "" {
+				source, expression = "<- "+field.Source, field.Expression
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", field.Target, source, expression, field.Strategy)
+		}
+	}
+	return tw.Flush()
+}
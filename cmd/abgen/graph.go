@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/origadmin/abgen/internal/generator"
+	"github.com/origadmin/abgen/internal/graph"
+	"github.com/origadmin/abgen/internal/model"
+)
+
+// graphPackages analyzes and generates the directive packages in sourceDirs without writing
+// anything, and writes the graph of all their conversions to w in the given format.
+func graphPackages(w io.Writer, sourceDirs []string, format string) error {
+	if format != "dot" && format != "mermaid" {
+		return fmt.Errorf("unknown graph format %q, expected dot or mermaid", format)
+	}
+	analysisResults, err := analyzePackages(sourceDirs)
+	if err != nil {
+		return err
+	}
+
+	var conversions []*model.ConversionReport
+	for i, analysisResult := range analysisResults {
+		response, err := generator.Generate(analysisResult)
+		if err != nil {
+			return fmt.Errorf("%s: %w", sourceDirs[i], err)
+		}
+		conversions = append(conversions, response.Conversions...)
+	}
+
+	g := graph.Build(conversions)
+	if format == "mermaid" {
+		return g.WriteMermaid(w)
+	}
+	return g.WriteDOT(w)
+}
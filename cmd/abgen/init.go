@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/origadmin/abgen/internal/scaffold"
+)
+
+// directivesFile is the name of the directives file written by init.
+const directivesFile = "directives.go"
+
+// initDirectives writes a directives file converting the types of the source package to those
+// of the target package in outDir, and returns its path. An existing file is not overwritten,
+// and outDir is only created once the packages are loaded.
+func initDirectives(sourcePath, targetPath, outDir string) (string, error) {
+	if sourcePath == "" || targetPath == "" {
+		return "", errors.New("both -source and -target packages are required")
+	}
+	path := filepath.Join(outDir, directivesFile)
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("%s already exists", path)
+	}
+
+	content, err := scaffold.Directives(scaffold.Options{SourcePath: sourcePath, TargetPath: targetPath, OutDir: outDir})
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -1,46 +1,122 @@
 package main
 
 import (
+	"bytes"
+	"cmp"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 
 	goversion "github.com/caarlos0/go-version"
 
 	"github.com/origadmin/abgen/internal/analyzer"
 	"github.com/origadmin/abgen/internal/config"
 	"github.com/origadmin/abgen/internal/generator"
+	"github.com/origadmin/abgen/internal/model"
 )
 
+// commandLine is the flag set of all the commands. Errors are returned instead of exiting, so
+// that run reports them with its exit code.
+var commandLine = flag.NewFlagSet(config.Application, flag.ContinueOnError)
+
 var (
 	version      = config.Version
 	commit       = ""
 	treeState    = ""
 	date         = ""
 	builtBy      = ""
-	debug        = flag.Bool("debug", false, "Enable debug logging")
-	output       = flag.String("output", "", "Output file name for the main generated code. Defaults to <package_name>.gen.go.")
-	customOutput = flag.String("custom-output", "custom.gen.go", "Output file name for custom conversion stubs.")
-	logFile      = flag.String("log-file", "", "Path to a file where logs should be written. If empty, logs go to stderr.")
+	debug        = commandLine.Bool("debug", false, "Enable debug logging")
+	output       = commandLine.String("output", "", "Output file name for the main generated code. Defaults to <package_name>.gen.go.")
+	customOutput = commandLine.String("custom-output", "custom.gen.go", "Output file name for custom conversion stubs.")
+	logFile      = commandLine.String("log-file", "", "Path to a file where logs should be written. If empty, logs go to stderr.")
+	configFile   = commandLine.String("config", "", "Configuration file merged with the directives, instead of the abgen.yaml or abgen.json found from each directive package.")
+	lock         = commandLine.Bool("lock", false, "Write or refresh the "+lockFile+" file recording the mappings of the generated conversions.")
+	parallel     = commandLine.Int("parallel", runtime.GOMAXPROCS(0), "Number of directive packages generated in parallel.")
+	jsonOutput   = commandLine.Bool("json", false, "Print the explanation as JSON (explain only).")
+	format       = commandLine.String("format", "", "Output format: dot (default) or mermaid for graph, text (default), json or markdown for drift, yaml (default) or json for config dump.")
+	baseline     = commandLine.String("baseline", "", "JSON drift report of the accepted drift, left out of the report (drift only).")
+	initSource   = commandLine.String("source", "", "Import path of the package converted from (init only).")
+	initTarget   = commandLine.String("target", "", "Import path of the package converted to (init only).")
+	initOut      = commandLine.String("out", ".", "Directory of the directives file to write (init only).")
+)
+
+// overrides are the directives given with -D, parsed after the directives of each package.
+var overrides directiveFlag
+
+func init() {
+	commandLine.Var(&overrides, "D", "Directive overriding those of the packages, such as convert:direction=oneway; may be repeated.")
+}
+
+// directiveFlag collects the values of a flag given several times.
+type directiveFlag []string
+
+func (d *directiveFlag) String() string {
+	return strings.Join(*d, " ")
+}
+
+func (d *directiveFlag) Set(value string) error {
+	if value == "" {
+		return errors.New("empty directive")
+	}
+	*d = append(*d, value)
+	return nil
+}
+
+// lockFile is the name of the mapping lockfile of a directive package.
+const lockFile = "abgen.lock"
+
+const (
+	commandCheck   = "check"
+	commandExplain = "explain"
+	commandGraph   = "graph"
+	commandInit    = "init"
+	commandDrift   = "drift"
+	commandConfig  = "config"
+	// subcommandDump is the config subcommand printing the effective configuration.
+	subcommandDump = "dump"
 )
 
 func main() {
-	flag.Parse()
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
 
-	var logWriter *os.File
+// run runs abgen with the command line arguments args, writing the output of the command to
+// stdout and the logs to stderr, and returns the exit code of the process.
+func run(args []string, stdout, stderr io.Writer) int {
+	command := ""
+	if len(args) > 0 && (args[0] == commandCheck || args[0] == commandExplain || args[0] == commandGraph ||
+		args[0] == commandInit || args[0] == commandDrift) {
+		command, args = args[0], args[1:]
+	}
+	if len(args) > 1 && args[0] == commandConfig && args[1] == subcommandDump {
+		command, args = commandConfig, args[2:]
+	}
+	commandLine.SetOutput(stderr)
+	args, err := parseFlags(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		// The flag set has already reported the error along with the usage of the flags.
+		return 2
+	}
+
+	logWriter := stderr
 	if *logFile != "" {
-		var err error
-		logWriter, err = os.OpenFile(*logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		file, err := os.OpenFile(*logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 		if err != nil {
 			slog.Error("Failed to open log file", "file", *logFile, "error", err)
-			os.Exit(1)
+			return 1
 		}
-		defer logWriter.Close()
-	} else {
-		logWriter = os.Stderr
+		defer file.Close()
+		logWriter = file
 	}
 
 	logLevel := slog.LevelWarn
@@ -52,25 +128,254 @@ func main() {
 		Level: logLevel,
 	})))
 
+	if command == commandInit {
+		path, err := initDirectives(*initSource, *initTarget, *initOut)
+		if err != nil {
+			slog.Error("Failed to write the directives file", "error", err)
+			return 1
+		}
+		fmt.Fprintf(stdout, "abgen init: wrote %s, review it and run abgen %s\n", path, *initOut)
+		return 0
+	}
+
+	if command == commandDrift {
+		if len(args) != 2 {
+			slog.Error("The drift command takes the import paths of a source and a target package", "args", args)
+			fmt.Fprintln(stderr, "Usage: abgen drift [-format text|json|markdown] [-baseline <file>] <source_package> <target_package>")
+			return 2
+		}
+		items, err := driftPackages(stdout, args[0], args[1], cmp.Or(*format, "text"), *baseline)
+		if err != nil {
+			slog.Error("Failed to report the drift", "error", err)
+			return 1
+		}
+		if items > 0 {
+			fmt.Fprintf(stderr, "abgen drift: %d difference(s) from %s to %s\n", items, args[0], args[1])
+			return 1
+		}
+		return 0
+	}
+
 	v := buildVersion(version, commit, date, builtBy, treeState)
-	if len(flag.Args()) == 0 {
-		fmt.Println(v.String())
-		fmt.Println("Usage: abgen [options] <source_directory>")
-		flag.PrintDefaults()
-		return
+	if len(args) == 0 {
+		fmt.Fprintln(stdout, v.String())
+		fmt.Fprintln(stdout, "Usage: abgen [check|explain|graph] [options] [-D directive=value]... <source_directory|pattern/...>...")
+		fmt.Fprintln(stdout, "       abgen init -source <package> -target <package> [-out <directory>]")
+		fmt.Fprintln(stdout, "       abgen config dump [-format yaml|json] <source_directory|pattern/...>...")
+		fmt.Fprintln(stdout, "       abgen drift [-format text|json|markdown] [-baseline <file>] <source_package> <target_package>")
+		fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout, "Commands:")
+		fmt.Fprintln(stdout, "  check\tcompare the generated files with the code generated now, without writing them")
+		fmt.Fprintln(stdout, "  explain\tprint the conversion plan: the active rules and how each target field is set")
+		fmt.Fprintln(stdout, "  graph\tprint the graph of the conversions between types, in the DOT or Mermaid format")
+		fmt.Fprintln(stdout, "  config dump\tprint the configuration of each package, its configuration file merged with its directives")
+		fmt.Fprintln(stdout, "  drift\treport the types and fields that differ between the source and target packages")
+		fmt.Fprintln(stdout, "  init\twrite a directives file converting the types of the source package to the target package")
+		fmt.Fprintln(stdout)
+		commandLine.SetOutput(stdout)
+		commandLine.PrintDefaults()
+		return 0
+	}
+
+	sourceDirs, err := sourceDirectories(args)
+	if err != nil {
+		slog.Error("Failed to find directive packages", "error", err)
+		return 1
+	}
+	if len(sourceDirs) > 1 && (filepath.IsAbs(*output) || filepath.IsAbs(*customOutput)) {
+		slog.Error("Absolute output files cannot be used with several directive packages",
+			"output", *output, "customOutput", *customOutput)
+		return 1
 	}
+	slog.Info("Starting abgen", "command", command, "sourceDirs", sourceDirs)
 
-	sourceDir := flag.Arg(0)
-	slog.Info("Starting abgen", "sourceDir", sourceDir)
+	if command == commandExplain {
+		if err := explainPackages(stdout, sourceDirs, *jsonOutput); err != nil {
+			slog.Error("Failed to explain the conversion plan", "error", err)
+			return 1
+		}
+		return 0
+	}
+	if command == commandConfig {
+		if err := dumpConfigs(stdout, sourceDirs, cmp.Or(*format, "yaml")); err != nil {
+			slog.Error("Failed to dump the configuration", "error", err)
+			return 1
+		}
+		return 0
+	}
+	if command == commandGraph {
+		if err := graphPackages(stdout, sourceDirs, cmp.Or(*format, "dot")); err != nil {
+			slog.Error("Failed to export the conversion graph", "error", err)
+			return 1
+		}
+		return 0
+	}
 
-	// --- 1. Analyze Source Code and Create Execution Plan ---
-	slog.Debug("Analyzing source code and creating execution plan...")
-	typeAnalyzer := analyzer.NewTypeAnalyzer()
-	analysisResult, err := typeAnalyzer.Analyze(sourceDir)
+	files, err := generatePackages(sourceDirs)
 	if err != nil {
-		slog.Error("Failed to analyze source code", "error", err)
-		os.Exit(1)
+		slog.Error("Code generation failed", "error", err)
+		return 1
 	}
+
+	if command == commandCheck {
+		stale, err := checkFiles(stdout, files)
+		if err != nil {
+			slog.Error("Failed to check generated files", "error", err)
+			return 1
+		}
+		if stale > 0 {
+			fmt.Fprintf(stderr, "abgen check: %d generated file(s) out of date, run abgen %s to regenerate them\n",
+				stale, strings.Join(args, " "))
+			return 1
+		}
+		return 0
+	}
+
+	for _, file := range files {
+		if !file.Always && bytes.Equal(file.Content, file.Existing) {
+			continue
+		}
+		if file.Lock && !*lock {
+			slog.Warn("The mappings of the generated conversions changed, review them and run abgen -lock to refresh the lockfile",
+				"file", file.Path)
+			continue
+		}
+		slog.Info("Writing "+file.Description, "file", file.Path)
+		if err := os.WriteFile(file.Path, file.Content, 0644); err != nil {
+			slog.Error("Failed to write "+file.Description, "file", file.Path, "error", err)
+			return 1
+		}
+	}
+
+	slog.Info("abgen finished successfully.")
+	return 0
+}
+
+// parseFlags parses the flags in args and returns the positional arguments. Flags may follow
+// the positional arguments, as in abgen graph <dir> -format=mermaid; the arguments after --
+// are all positional.
+func parseFlags(args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := commandLine.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := commandLine.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		if parsed := len(args) - len(rest); parsed > 0 && args[parsed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional, args = append(positional, rest[0]), rest[1:]
+	}
+}
+
+// sourceDirectories returns the directive package directories named by the command line
+// arguments, expanding the patterns ending with /... to the directive packages below them.
+func sourceDirectories(args []string) ([]string, error) {
+	var sourceDirs []string
+	seen := make(map[string]bool)
+	for _, arg := range args {
+		dirs, err := analyzer.DiscoverDirectivePackages(arg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search %s: %w", arg, err)
+		}
+		for _, dir := range dirs {
+			if !seen[filepath.Clean(dir)] {
+				seen[filepath.Clean(dir)] = true
+				sourceDirs = append(sourceDirs, dir)
+			}
+		}
+	}
+	if len(sourceDirs) == 0 {
+		return nil, fmt.Errorf("no directive package found in %s", strings.Join(args, " "))
+	}
+	return sourceDirs, nil
+}
+
+// outputFile is a file generated by abgen, along with its content on disk.
+type outputFile struct {
+	Path        string
+	Description string
+	Content     []byte
+	Existing    []byte
+	// Always tells whether the file is written even when it is unchanged.
+	Always bool
+	// Lock tells whether the file is the mapping lockfile, only written when -lock is set.
+	Lock bool
+}
+
+// generatePackages analyzes the directive packages in sourceDirs and generates the content
+// of the files abgen writes for them, without writing them. Several directive packages are
+// generated in parallel.
+func generatePackages(sourceDirs []string) ([]*outputFile, error) {
+	// --- 1. Analyze Source Code and Create Execution Plans ---
+	analysisResults, err := analyzePackages(sourceDirs)
+	if err != nil {
+		return nil, err
+	}
+	if len(sourceDirs) == 1 {
+		return generateFiles(sourceDirs[0], analysisResults[0])
+	}
+
+	packageFiles := make([][]*outputFile, len(sourceDirs))
+	errs := make([]error, len(sourceDirs))
+	slots := make(chan struct{}, max(*parallel, 1))
+	var wg sync.WaitGroup
+	for i, sourceDir := range sourceDirs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			packageFiles[i], errs[i] = generateFiles(sourceDir, analysisResults[i])
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("%s: %w", sourceDir, errs[i])
+			}
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	var files []*outputFile
+	for _, pkgFiles := range packageFiles {
+		files = append(files, pkgFiles...)
+	}
+	return files, nil
+}
+
+// analyzePackages analyzes the directive packages in sourceDirs and creates their execution
+// plans. Several directive packages are analyzed together, sharing the packages they load.
+func analyzePackages(sourceDirs []string) ([]*model.AnalysisResult, error) {
+	slog.Debug("Analyzing source code and creating execution plans...")
+	var options []analyzer.Option
+	if *configFile != "" {
+		options = append(options, analyzer.WithConfigFile(*configFile))
+	}
+	if len(overrides) > 0 {
+		options = append(options, analyzer.WithOverrides(overrides))
+	}
+	typeAnalyzer := analyzer.NewTypeAnalyzer(options...)
+	if len(sourceDirs) == 1 {
+		analysisResult, err := typeAnalyzer.Analyze(sourceDirs[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze source code: %w", err)
+		}
+		return []*model.AnalysisResult{analysisResult}, nil
+	}
+	analysisResults, err := typeAnalyzer.AnalyzePackages(sourceDirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze source code: %w", err)
+	}
+	return analysisResults, nil
+}
+
+// generateFiles generates the content of the files abgen writes for the directive package
+// in sourceDir from its analysis, without writing them.
+func generateFiles(sourceDir string, analysisResult *model.AnalysisResult) ([]*outputFile, error) {
 	analysisResult.ExecutionPlan.FinalConfig.Version = version
 
 	// --- 2. Resolve Output File Paths ---
@@ -88,34 +393,70 @@ func main() {
 		customOutputFile = filepath.Join(sourceDir, customOutputFile)
 	}
 	cfg.GenerationContext.CustomOutputFile = customOutputFile
+	cfg.GenerationContext.TestOutputFile = strings.TrimSuffix(mainOutputFile, ".go") + "_test.go"
 
 	// --- 3. Generate Code ---
 	slog.Debug("Generating code...")
 	response, err := generator.Generate(analysisResult)
 	if err != nil {
-		slog.Error("Code generation failed", "error", err)
-		os.Exit(1)
+		return nil, err
 	}
 
-	// --- 4. Write Main Output ---
-	slog.Info("Writing main generated code", "file", cfg.GenerationContext.MainOutputFile)
-	err = os.WriteFile(cfg.GenerationContext.MainOutputFile, response.GeneratedCode, 0644)
+	// --- 4. Main Output ---
+	mainFile, err := readOutputFile(cfg.GenerationContext.MainOutputFile, "main generated code")
 	if err != nil {
-		slog.Error("Failed to write main output file", "error", err)
-		os.Exit(1)
+		return nil, err
 	}
+	mainFile.Content = response.GeneratedCode
+	mainFile.Always = true
+	files := []*outputFile{mainFile}
 
-	// --- 5. Write Custom Stubs Output (if any) ---
-	if len(response.CustomStubs) > 0 {
-		slog.Info("Writing custom conversion stubs", "file", cfg.GenerationContext.CustomOutputFile)
-		err = os.WriteFile(cfg.GenerationContext.CustomOutputFile, response.CustomStubs, 0644)
+	// --- 5. Custom Stubs Output (if any) ---
+	// The stubs file may hold implemented conversions, so new stubs are merged into it.
+	stubsFile, err := readOutputFile(cfg.GenerationContext.CustomOutputFile, "custom conversion stubs")
+	if err != nil {
+		return nil, err
+	}
+	stubsFile.Content, err = generator.MergeCustomStubs(stubsFile.Existing, response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge custom stubs into %s: %w", stubsFile.Path, err)
+	}
+	if len(stubsFile.Content) > 0 {
+		files = append(files, stubsFile)
+	}
+
+	// --- 6. Round-Trip Tests (if enabled) ---
+	if len(response.TestCode) > 0 {
+		testFile, err := readOutputFile(cfg.GenerationContext.TestOutputFile, "round-trip tests")
 		if err != nil {
-			slog.Error("Failed to write custom stubs file", "error", err)
-			os.Exit(1)
+			return nil, err
 		}
+		testFile.Content = response.TestCode
+		testFile.Always = true
+		files = append(files, testFile)
 	}
 
-	slog.Info("abgen finished successfully.")
+	// --- 7. Mapping Lockfile (if enabled or present) ---
+	// Once written, the lockfile is compared by check, but only refreshed on request.
+	mappingFile, err := readOutputFile(filepath.Join(sourceDir, lockFile), "mapping lockfile")
+	if err != nil {
+		return nil, err
+	}
+	if *lock || mappingFile.Existing != nil {
+		mappingFile.Content = generator.LockFile(response)
+		mappingFile.Lock = true
+		files = append(files, mappingFile)
+	}
+	return files, nil
+}
+
+// readOutputFile reads the current content of an output file, if it exists.
+func readOutputFile(path, description string) (*outputFile, error) {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", description, err)
+	}
+	return &outputFile{Path: path, Description: description, Existing: existing}, nil
 }
 
 func buildVersion(version, commit, date, builtBy, treeState string) goversion.Info {
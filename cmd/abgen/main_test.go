@@ -0,0 +1,367 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// nestedDir is a directive package whose conversions call each other.
+const nestedDir = "../../testdata/02_basic_conversions/slice_conversion"
+
+// runAbgen runs abgen with args, its flags reset to their defaults, and returns its exit code,
+// output and logs.
+func runAbgen(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	commandLine.VisitAll(func(f *flag.Flag) {
+		if f.Name != "D" {
+			_ = f.Value.Set(f.DefValue)
+		}
+	})
+	overrides = nil
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantArgs   []string
+		wantFormat string
+		wantErr    bool
+	}{
+		{name: "flags first", args: []string{"-format=mermaid", "dir"}, wantArgs: []string{"dir"}, wantFormat: "mermaid"},
+		{name: "flags last", args: []string{"dir", "--format=mermaid"}, wantArgs: []string{"dir"}, wantFormat: "mermaid"},
+		{name: "flag value last", args: []string{"a", "b", "-format", "json"}, wantArgs: []string{"a", "b"}, wantFormat: "json"},
+		{name: "interspersed", args: []string{"a", "-format=json", "b"}, wantArgs: []string{"a", "b"}, wantFormat: "json"},
+		{name: "terminator", args: []string{"a", "--", "-format=json"}, wantArgs: []string{"a", "-format=json"}},
+		{name: "unknown flag", args: []string{"dir", "-unknown"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runAbgen(t) // Reset the flags.
+			commandLine.SetOutput(&bytes.Buffer{})
+			args, err := parseFlags(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFlags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) || *format != tt.wantFormat {
+				t.Errorf("parseFlags() = %q with format %q, want %q with format %q", args, *format, tt.wantArgs, tt.wantFormat)
+			}
+		})
+	}
+}
+
+func TestRun_Graph(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		want     string
+		wantEdge string
+	}{
+		{name: "default format", args: []string{"graph", nestedDir}, want: "digraph abgen {", wantEdge: `[label="Items"]`},
+		{name: "format first", args: []string{"graph", "--format=mermaid", nestedDir}, want: "flowchart LR", wantEdge: `|"Items"|`},
+		{name: "format last", args: []string{"graph", nestedDir, "--format=mermaid"}, want: "flowchart LR", wantEdge: `|"Items"|`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, stderr := runAbgen(t, tt.args...)
+			if code != 0 {
+				t.Fatalf("run() = %d, want 0\n%s", code, stderr)
+			}
+			if !strings.HasPrefix(stdout, tt.want) || !strings.Contains(stdout, tt.wantEdge) {
+				t.Errorf("run() output does not start with %q or misses the edge %q:\n%s", tt.want, tt.wantEdge, stdout)
+			}
+		})
+	}
+
+	if code, _, _ := runAbgen(t, "graph", nestedDir, "--format=svg"); code != 1 {
+		t.Errorf("run() with an unknown format = %d, want 1", code)
+	}
+}
+
+func TestRun_Explain(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "text", args: []string{"explain", nestedDir}, want: "package github.com/origadmin/abgen/testdata/02_basic_conversions/slice_conversion ("},
+		{name: "json first", args: []string{"explain", "--json", nestedDir}, want: `"dir": "` + nestedDir + `"`},
+		{name: "json last", args: []string{"explain", nestedDir, "--json"}, want: `"dir": "` + nestedDir + `"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, stderr := runAbgen(t, tt.args...)
+			if code != 0 {
+				t.Fatalf("run() = %d, want 0\n%s", code, stderr)
+			}
+			if !strings.Contains(stdout, tt.want) {
+				t.Errorf("run() output does not contain %q:\n%s", tt.want, stdout)
+			}
+		})
+	}
+}
+
+func TestRun_Drift(t *testing.T) {
+	const pkg = "github.com/origadmin/abgen/testdata/02_basic_conversions/slice_conversion"
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		want     string
+	}{
+		{name: "no package", args: []string{"drift"}, wantCode: 2},
+		{name: "one package", args: []string{"drift", pkg + "/source"}, wantCode: 2},
+		{name: "three packages", args: []string{"drift", pkg + "/source", pkg + "/target", pkg}, wantCode: 2},
+		{name: "format after a package", args: []string{"drift", pkg + "/source", "-format", "json"}, wantCode: 2},
+		{name: "text", args: []string{"drift", pkg + "/source", pkg + "/target"}, wantCode: 1, want: "field-type  ContainerVP.Users"},
+		{name: "format last", args: []string{"drift", pkg + "/source", pkg + "/target", "-format", "json"}, wantCode: 1, want: `"type": "ContainerVP"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, stderr := runAbgen(t, tt.args...)
+			if code != tt.wantCode {
+				t.Fatalf("run() = %d, want %d\n%s%s", code, tt.wantCode, stdout, stderr)
+			}
+			if !strings.Contains(stdout, tt.want) {
+				t.Errorf("run() output does not contain %q:\n%s", tt.want, stdout)
+			}
+		})
+	}
+
+	_, report, _ := runAbgen(t, "drift", "-format=json", pkg+"/source", pkg+"/target")
+	baselineFile := filepath.Join(t.TempDir(), "drift.json")
+	if err := os.WriteFile(baselineFile, []byte(report), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if code, stdout, stderr := runAbgen(t, "drift", pkg+"/source", pkg+"/target", "-baseline", baselineFile); code != 0 {
+		t.Errorf("run() with the baseline = %d, want 0\n%s%s", code, stdout, stderr)
+	}
+}
+
+// remapDir is a directive package with a conversion ignoring and remapping fields.
+const remapDir = "../../testdata/02_basic_conversions/field_ignore_remap"
+
+// moduleTempDir returns a new directory of the module, removed at the end of the test, so that
+// the packages of the module can be loaded from it.
+func moduleTempDir(t *testing.T) string {
+	t.Helper()
+	if err := os.MkdirAll("testdata", 0755); err != nil {
+		t.Fatal(err)
+	}
+	dir, err := os.MkdirTemp("testdata", "run-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+		os.Remove("testdata") // Only removed once empty.
+	})
+	return dir
+}
+
+// copyDirectives copies the directives file of the directive package in dir to a new directory
+// of the module, which abgen can generate to, and returns the new directory.
+func copyDirectives(t *testing.T, dir string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(dir, directivesFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	copyDir := moduleTempDir(t)
+	if err := os.WriteFile(filepath.Join(copyDir, directivesFile), content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return copyDir
+}
+
+func TestRun_GenerateAndCheck(t *testing.T) {
+	remap, nested := copyDirectives(t, remapDir), copyDirectives(t, nestedDir)
+	lockPath := filepath.Join(remap, lockFile)
+
+	code, stdout, _ := runAbgen(t, "check", remap, nested)
+	if code != 1 || strings.Count(stdout, ": missing main generated code") != 2 {
+		t.Fatalf("check before generating = %d, want 1 reporting both packages\n%s", code, stdout)
+	}
+
+	// Several directories are generated together, each to its own directory.
+	if code, _, stderr := runAbgen(t, remap, nested); code != 0 {
+		t.Fatalf("run() = %d, want 0\n%s", code, stderr)
+	}
+	for _, dir := range []string{remap, nested} {
+		if files, _ := filepath.Glob(filepath.Join(dir, "*.gen.go")); len(files) != 1 {
+			t.Errorf("generated files in %s = %v, want the main generated code", dir, files)
+		}
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Errorf("%s written without -lock, error = %v", lockPath, err)
+	}
+	if code, stdout, _ := runAbgen(t, "check", remap, nested); code != 0 {
+		t.Errorf("check after generating = %d, want 0\n%s", code, stdout)
+	}
+
+	// The lockfile is written with -lock, then compared by check.
+	if code, _, stderr := runAbgen(t, remap, "-lock"); code != 0 {
+		t.Fatalf("run() -lock = %d, want 0\n%s", code, stderr)
+	}
+	lock, err := os.ReadFile(lockPath)
+	if err != nil || !strings.Contains(string(lock), "\tFullName = from.Name [direct]\n") {
+		t.Fatalf("lockfile = %q, %v, want the FullName mapping", lock, err)
+	}
+	if code, stdout, _ := runAbgen(t, "check", remap); code != 0 {
+		t.Errorf("check with the lockfile = %d, want 0\n%s", code, stdout)
+	}
+
+	// Changed mappings fail check, and are only refreshed with -lock.
+	if err := os.WriteFile(lockPath, []byte(strings.Replace(string(lock), "from.Name", "from.Email", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	code, stdout, _ = runAbgen(t, "check", remap)
+	if code != 1 || !strings.Contains(stdout, "mappings changed, review them and run abgen -lock") {
+		t.Errorf("check with changed mappings = %d, want 1 reporting them\n%s", code, stdout)
+	}
+	if code, _, stderr := runAbgen(t, remap); code != 0 || !strings.Contains(stderr, "refresh the lockfile") {
+		t.Errorf("run() with changed mappings = %d, want 0 with a warning\n%s", code, stderr)
+	}
+	if changed, _ := os.ReadFile(lockPath); bytes.Equal(changed, lock) {
+		t.Errorf("run() without -lock refreshed the lockfile")
+	}
+	if code, _, _ := runAbgen(t, "-lock", remap); code != 0 {
+		t.Errorf("run() -lock = %d, want 0", code)
+	}
+	if refreshed, _ := os.ReadFile(lockPath); !bytes.Equal(refreshed, lock) {
+		t.Errorf("run() -lock did not refresh the lockfile:\n%s", refreshed)
+	}
+}
+
+func TestRun_ConfigDump(t *testing.T) {
+	const dir = "../../testdata/03_advanced_features/config_file"
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "yaml", args: []string{"config", "dump", dir}, want: []string{"targetSuffix: Model\n", "direction: oneway\n"}},
+		{name: "json last", args: []string{"config", "dump", dir, "-format=json"}, want: []string{`"targetSuffix": "Model"`}},
+		{
+			name: "several packages",
+			args: []string{"config", "dump", dir, remapDir},
+			want: []string{"# " + dir + "\n", "---\n# " + remapDir + "\n"},
+		},
+		{
+			name: "overrides",
+			args: []string{"config", "dump", "-D", `convert:target:suffix="PB"`, dir, "-D", "convert:direction=both"},
+			want: []string{"targetSuffix: PB\n", "direction: both\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, stderr := runAbgen(t, tt.args...)
+			if code != 0 {
+				t.Fatalf("run() = %d, want 0\n%s", code, stderr)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(stdout, want) {
+					t.Errorf("run() output does not contain %q:\n%s", want, stdout)
+				}
+			}
+		})
+	}
+}
+
+func TestRun_Overrides(t *testing.T) {
+	code, stdout, stderr := runAbgen(t, "explain", remapDir, "-D", `convert:target:suffix="PB"`,
+		"-D", `//go:abgen:convert="source=source.User,target=target.UserDTO,ignore=Email"`)
+	if code != 0 {
+		t.Fatalf("run() = %d, want 0\n%s", code, stderr)
+	}
+	for _, want := range []string{"overrides (cli):\n  convert:target:suffix=\"PB\"\n", "\nConvertUserToUserDTOPB: ", "  cli  "} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("run() output does not contain %q:\n%s", want, stdout)
+		}
+	}
+
+	if code, _, stderr := runAbgen(t, "explain", remapDir, "-D", ""); code != 2 || !strings.Contains(stderr, "empty directive") {
+		t.Errorf("run() with an empty override = %d, want 2\n%s", code, stderr)
+	}
+	if code, _, stderr := runAbgen(t, "explain", remapDir, "-D", `convert:func:name="{{.Source"`); code != 1 ||
+		!strings.Contains(stderr, "invalid override") {
+		t.Errorf("run() with an invalid override = %d, want 1\n%s", code, stderr)
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+	}{
+		{name: "no argument", args: nil, wantCode: 0},
+		{name: "command without directory", args: []string{"check"}, wantCode: 0},
+		{name: "help", args: []string{"-h"}, wantCode: 0},
+		{name: "unknown flag", args: []string{"-unknown", remapDir}, wantCode: 2},
+		{name: "missing directory", args: []string{"check", "does-not-exist"}, wantCode: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, stdout, stderr := runAbgen(t, tt.args...); code != tt.wantCode {
+				t.Errorf("run() = %d, want %d\n%s%s", code, tt.wantCode, stdout, stderr)
+			}
+		})
+	}
+}
+
+func TestRun_Init(t *testing.T) {
+	const pkg = "github.com/origadmin/abgen/testdata/02_basic_conversions/field_ignore_remap"
+	dir := moduleTempDir(t)
+	apiDir := filepath.Join(dir, "user-api")
+	if err := os.Mkdir(apiDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(apiDir, "api.go"), []byte("package api\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		out         string
+		source      string
+		wantCode    int
+		wantPackage string
+		// wantNoOut tells whether the directory of the file is left uncreated.
+		wantNoOut bool
+	}{
+		{name: "new directory", out: filepath.Join(dir, "convert"), source: pkg + "/source", wantPackage: "package convert\n"},
+		{name: "existing file", out: filepath.Join(dir, "convert"), source: pkg + "/source", wantCode: 1},
+		{name: "existing package", out: apiDir, source: pkg + "/source", wantPackage: "package api\n"},
+		{name: "package not found", out: filepath.Join(dir, "missing"), source: pkg + "/missing", wantCode: 1, wantNoOut: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, stderr := runAbgen(t, "init", "-source", tt.source, "-target", pkg+"/target", "-out", tt.out)
+			if code != tt.wantCode {
+				t.Fatalf("run() = %d, want %d\n%s%s", code, tt.wantCode, stdout, stderr)
+			}
+			if _, err := os.Stat(tt.out); tt.wantNoOut && !os.IsNotExist(err) {
+				t.Errorf("run() left %s behind, error = %v", tt.out, err)
+			}
+			if tt.wantPackage == "" {
+				return
+			}
+			content, err := os.ReadFile(filepath.Join(tt.out, directivesFile))
+			if err != nil || !strings.HasPrefix(string(content), tt.wantPackage) {
+				t.Errorf("directives file = %q, %v, want %q", content, err, tt.wantPackage)
+			}
+		})
+	}
+}
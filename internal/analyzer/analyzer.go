@@ -1,11 +1,14 @@
 package analyzer
 
 import (
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/token"
 	"go/types"
 	"log/slog"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"golang.org/x/tools/go/packages"
@@ -16,18 +19,54 @@ import (
 	"github.com/origadmin/abgen/internal/planner"
 )
 
+// loadMode is the information loaded for the directive packages and the packages they refer to.
+const loadMode = packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedModule |
+	packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps
+
 // TypeAnalyzer is responsible for parsing Go source files, extracting directives,
 // configuring the build, and analyzing all required types.
+// The packages it loads and the types it resolves are kept for the next analyses, so that
+// the directive packages analyzed by one analyzer share them.
 type TypeAnalyzer struct {
-	pkgs      []*packages.Package
+	pkgs      map[string]*packages.Package
 	typeCache map[types.Type]*model.TypeInfo
+	// configFile is the configuration file of every directive package, instead of the one
+	// found from its directory.
+	configFile string
+	// overrides are the directives given on the command line, parsed after the directives of
+	// every directive package.
+	overrides []string
+}
+
+// Option configures a TypeAnalyzer.
+type Option func(*TypeAnalyzer)
+
+// WithConfigFile sets the configuration file merged with the directives of every directive
+// package, instead of the file looked up from the directory of each.
+func WithConfigFile(path string) Option {
+	return func(a *TypeAnalyzer) {
+		a.configFile = path
+	}
+}
+
+// WithOverrides sets the directives parsed after the directives of every directive package,
+// so that they take precedence over them.
+func WithOverrides(overrides []string) Option {
+	return func(a *TypeAnalyzer) {
+		a.overrides = overrides
+	}
 }
 
 // NewTypeAnalyzer creates a new TypeAnalyzer.
-func NewTypeAnalyzer() model.TypeAnalyzer {
-	return &TypeAnalyzer{
+func NewTypeAnalyzer(options ...Option) model.TypeAnalyzer {
+	a := &TypeAnalyzer{
+		pkgs:      make(map[string]*packages.Package),
 		typeCache: make(map[types.Type]*model.TypeInfo),
 	}
+	for _, option := range options {
+		option(a)
+	}
+	return a
 }
 
 // Analyze is the main entry point for the analysis phase. It orchestrates
@@ -42,17 +81,105 @@ func (a *TypeAnalyzer) Analyze(sourceDir string) (*model.AnalysisResult, error)
 		return nil, fmt.Errorf("no initial package found at %s", sourceDir)
 	}
 
-	// 2. Extract directives from the initial package's syntax files.
-	directives := a.extractDirectives(initialPkg)
+	initialConfig, converters, err := a.configure(sourceDir, initialPkg)
+	if err != nil {
+		return nil, err
+	}
+	return a.analyzePackage(initialPkg, initialConfig, converters)
+}
+
+// AnalyzePackages analyzes the directive packages in sourceDirs as Analyze does, but loads
+// the packages they need together: the directive packages in one load, then the packages
+// referred to by any of them in another, so that packages shared by several directive
+// packages are loaded and analyzed once. The results are in the order of sourceDirs; a
+// directory that cannot be analyzed has a nil result, and the errors of all directories
+// are joined in the returned error.
+func (a *TypeAnalyzer) AnalyzePackages(sourceDirs []string) ([]*model.AnalysisResult, error) {
+	results := make([]*model.AnalysisResult, len(sourceDirs))
+	errs := make([]error, len(sourceDirs))
+
+	initialPkgs, err := a.loadInitialPackages(sourceDirs)
+	if err != nil {
+		return results, fmt.Errorf("failed to load initial packages: %w", err)
+	}
+
+	configs := make([]*config.Config, len(sourceDirs))
+	converters := make([][]*model.ConverterInterface, len(sourceDirs))
+	var paths []string
+	for i, sourceDir := range sourceDirs {
+		if initialPkgs[i] == nil {
+			errs[i] = errors.New("no initial package found")
+			continue
+		}
+		configs[i], converters[i], errs[i] = a.configure(sourceDir, initialPkgs[i])
+		if errs[i] != nil {
+			continue
+		}
+		paths = append(paths, a.collectExternalPaths(configs[i])...)
+		paths = append(paths, configs[i].HelperPackages...)
+	}
+	if err := a.loadPackages(paths); err != nil {
+		return results, fmt.Errorf("failed to load external package graph: %w", err)
+	}
+
+	for i, sourceDir := range sourceDirs {
+		if configs[i] == nil {
+			errs[i] = fmt.Errorf("%s: %w", sourceDir, errs[i])
+			continue
+		}
+		results[i], errs[i] = a.analyzePackage(initialPkgs[i], configs[i], converters[i])
+		if errs[i] != nil {
+			errs[i] = fmt.Errorf("%s: %w", sourceDir, errs[i])
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// AnalyzePackagePair analyzes the conversion of the types of the package at sourcePath to
+// those of the package at targetPath as Analyze would for a directive package declaring only
+// their package pair, without a directive package: the conversions are planned for a
+// package of its own.
+func (a *TypeAnalyzer) AnalyzePackagePair(sourcePath, targetPath string) (*model.AnalysisResult, error) {
+	cfg := config.NewConfig()
+	cfg.GenerationContext.PackageName = config.Application
+	cfg.PackagePairs = append(cfg.PackagePairs, &config.PackagePair{SourcePath: sourcePath, TargetPath: targetPath})
+	return a.analyzePackage(nil, cfg, nil)
+}
+
+// configure parses the directives of the directive package into its configuration, after
+// those of its configuration file if any, and adds the rules of the converter interfaces it
+// declares.
+func (a *TypeAnalyzer) configure(sourceDir string, initialPkg *packages.Package) (*config.Config, []*model.ConverterInterface, error) {
+	// 2. Extract directives from the configuration file and the initial package's syntax files.
+	directives, err := a.configFileDirectives(sourceDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	directives = append(directives, a.extractDirectives(initialPkg)...)
 
 	// 3. Parse the extracted directives to build the initial configuration.
 	cfgParser := config.NewParser()
+	cfgParser.SetOverrides(a.overrides)
 	initialConfig, err := cfgParser.ParseDirectives(directives, initialPkg.Name, initialPkg.ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse directives: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse directives: %w", err)
 	}
 	initialConfig.GenerationContext.DirectivePath = sourceDir
 
+	// Converter interfaces add a rule for each struct conversion their methods declare.
+	converters, err := a.discoverConverterInterfaces(initialPkg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to analyze converter interfaces: %w", err)
+	}
+	initialConfig.ConversionRules = append(initialConfig.ConversionRules, converterRules(initialConfig, converters)...)
+	return initialConfig, converters, nil
+}
+
+// analyzePackage analyzes the types the configuration of a directive package refers to and
+// plans its generation.
+func (a *TypeAnalyzer) analyzePackage(
+	initialPkg *packages.Package, initialConfig *config.Config, converters []*model.ConverterInterface,
+) (*model.AnalysisResult, error) {
 	// 4. Analyze all required external packages based on the configuration.
 	resolvedTypes, err := a.analyzeExternalPackages(initialConfig)
 	// On error, we still want to proceed with a partial result for testing config parsing.
@@ -62,6 +189,9 @@ func (a *TypeAnalyzer) Analyze(sourceDir string) (*model.AnalysisResult, error)
 
 	// 5. Discover existing definitions (aliases, functions) in the initial package.
 	existingFuncs, existingAliases := a.discoverExistingDefinitions(initialPkg)
+	conversionFuncs := a.discoverConversionFuncs(initialPkg)
+	helperFuncs := a.discoverHelperFuncs(initialConfig.HelperPackages)
+	customFuncs := a.discoverCustomFuncs(initialPkg, initialConfig.CustomFunctionRules)
 
 	// 6. Create the final execution plan.
 	typeConverter := components.NewTypeConverter()
@@ -73,6 +203,10 @@ func (a *TypeAnalyzer) Analyze(sourceDir string) (*model.AnalysisResult, error)
 		TypeInfos:         resolvedTypes,
 		ExistingFunctions: existingFuncs,
 		ExistingAliases:   existingAliases,
+		ConversionFuncs:   conversionFuncs,
+		HelperFuncs:       helperFuncs,
+		CustomFuncs:       customFuncs,
+		Converters:        converters,
 		ExecutionPlan:     executionPlan,
 	}
 
@@ -82,8 +216,7 @@ func (a *TypeAnalyzer) Analyze(sourceDir string) (*model.AnalysisResult, error)
 // loadInitialPackage loads the package at the given source directory.
 func (a *TypeAnalyzer) loadInitialPackage(sourceDir string) (*packages.Package, error) {
 	initialLoaderCfg := &packages.Config{
-		Mode: packages.NeedName | packages.NeedSyntax | packages.NeedFiles | packages.NeedModule |
-			packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports,
+		Mode:       loadMode,
 		Dir:        sourceDir,
 		Tests:      false,
 		BuildFlags: []string{"-tags=abgen_source"},
@@ -105,6 +238,93 @@ func (a *TypeAnalyzer) loadInitialPackage(sourceDir string) (*packages.Package,
 	return initialPkgs[0], nil
 }
 
+// loadInitialPackages loads the packages at the given source directories in a single load,
+// and returns them in the order of the directories. A directory without a package has none.
+func (a *TypeAnalyzer) loadInitialPackages(sourceDirs []string) ([]*packages.Package, error) {
+	patterns := make([]string, len(sourceDirs))
+	for i, sourceDir := range sourceDirs {
+		absDir, err := filepath.Abs(sourceDir)
+		if err != nil {
+			return nil, err
+		}
+		patterns[i] = absDir
+	}
+
+	initialLoaderCfg := &packages.Config{
+		Mode:       loadMode,
+		Tests:      false,
+		BuildFlags: []string{"-tags=abgen_source"},
+	}
+	initialPkgs, err := packages.Load(initialLoaderCfg, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("error during package loading: %w", err)
+	}
+
+	pkgsByDir := make(map[string]*packages.Package, len(initialPkgs))
+	for _, pkg := range initialPkgs {
+		for _, pkgErr := range pkg.Errors {
+			slog.Warn("Initial package contains errors, analysis may be incomplete",
+				"pkg", pkg.PkgPath, "error", pkgErr.Error(), "pos", pkgErr.Pos)
+		}
+		if len(pkg.GoFiles) > 0 {
+			pkgsByDir[filepath.Dir(pkg.GoFiles[0])] = pkg
+		}
+	}
+	pkgs := make([]*packages.Package, len(patterns))
+	for i, pattern := range patterns {
+		pkgs[i] = pkgsByDir[pattern]
+	}
+	return pkgs, nil
+}
+
+// loadPackages loads the packages at paths that are not loaded yet, with their dependencies.
+func (a *TypeAnalyzer) loadPackages(paths []string) error {
+	var missing []string
+	for _, path := range paths {
+		if _, loaded := a.pkgs[path]; !loaded && !slices.Contains(missing, path) {
+			missing = append(missing, path)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	loadCfg := &packages.Config{
+		Mode:       loadMode,
+		Tests:      false,
+		BuildFlags: []string{"-tags=abgen_source"},
+	}
+	pkgs, err := packages.Load(loadCfg, missing...)
+	if err != nil {
+		return err
+	}
+	for _, pkg := range pkgs {
+		a.pkgs[pkg.PkgPath] = pkg
+	}
+	return nil
+}
+
+// configFileDirectives returns the directives of the configuration file of the directive
+// package in sourceDir: the file set with WithConfigFile, or the one found from sourceDir.
+func (a *TypeAnalyzer) configFileDirectives(sourceDir string) ([]string, error) {
+	path := a.configFile
+	if path == "" {
+		var err error
+		if path, err = config.FindFile(sourceDir); err != nil {
+			return nil, fmt.Errorf("failed to find the configuration file: %w", err)
+		}
+		if path == "" {
+			return nil, nil
+		}
+	}
+	file, err := config.LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	slog.Debug("Merging configuration file", "file", path, "sourceDir", sourceDir)
+	return file.Directives(), nil
+}
+
 // extractDirectives scans all files in a package for abgen directives.
 func (a *TypeAnalyzer) extractDirectives(pkg *packages.Package) []string {
 	var directives []string
@@ -130,18 +350,16 @@ func (a *TypeAnalyzer) analyzeExternalPackages(cfg *config.Config) (map[string]*
 		return make(map[string]*model.TypeInfo), nil
 	}
 
-	loadCfg := &packages.Config{
-		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedModule |
-			packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps,
-		Tests:      false,
-		BuildFlags: []string{"-tags=abgen_source"},
-	}
-	pkgs, err := packages.Load(loadCfg, paths...)
-	if err != nil {
+	if err := a.loadPackages(paths); err != nil {
 		return nil, fmt.Errorf("failed to load external package graph: %w", err)
 	}
-	a.pkgs = pkgs
 
+	var pkgs []*packages.Package
+	for _, path := range paths {
+		if pkg, ok := a.pkgs[path]; ok {
+			pkgs = append(pkgs, pkg)
+		}
+	}
 	for _, pkg := range pkgs {
 		if len(pkg.Errors) > 0 {
 			return nil, fmt.Errorf("error loading external package %s: %v", pkg.PkgPath, pkg.Errors)
@@ -150,7 +368,7 @@ func (a *TypeAnalyzer) analyzeExternalPackages(cfg *config.Config) (map[string]*
 
 	resolvedTypes := make(map[string]*model.TypeInfo)
 
-	for _, pkg := range a.pkgs {
+	for _, pkg := range pkgs {
 		for _, name := range pkg.Types.Scope().Names() {
 			obj := pkg.Types.Scope().Lookup(name)
 			if obj != nil {
@@ -182,6 +400,13 @@ func (a *TypeAnalyzer) analyzeExternalPackages(cfg *config.Config) (map[string]*
 		}
 	}
 
+	if len(cfg.MapTypes) > 0 || slices.ContainsFunc(cfg.ConversionRules, func(rule *config.ConversionRule) bool {
+		return rule.SourceType == config.AnyMapTypeKey || rule.TargetType == config.AnyMapTypeKey
+	}) {
+		mapType := model.AnyMapType()
+		resolvedTypes[mapType.UniqueKey()] = mapType
+	}
+
 	return resolvedTypes, nil
 }
 
@@ -217,6 +442,304 @@ func (a *TypeAnalyzer) discoverExistingDefinitions(pkg *packages.Package) (map[s
 	return existingFunctions, existingAliases
 }
 
+// discoverConversionFuncs type-checks every top-level function of the directive package and
+// indexes those shaped like func(A) B or func(A) (B, error) by their (A, B) pair.
+// Pairs matched by more than one function are ambiguous; they are reported and left out.
+func (a *TypeAnalyzer) discoverConversionFuncs(pkg *packages.Package) map[string]*model.ConversionFunc {
+	if pkg == nil || pkg.Types == nil {
+		return make(map[string]*model.ConversionFunc)
+	}
+	return a.indexConversionFuncs([]*types.Package{pkg.Types}, false)
+}
+
+// discoverHelperFuncs loads the packages named by helpers:package directives and indexes
+// their exported conversion functions. Packages that fail to load are reported and skipped.
+func (a *TypeAnalyzer) discoverHelperFuncs(paths []string) map[string]*model.ConversionFunc {
+	if len(paths) == 0 {
+		return make(map[string]*model.ConversionFunc)
+	}
+
+	if err := a.loadPackages(paths); err != nil {
+		slog.Warn("failed to load helper packages", "packages", paths, "error", err)
+		return make(map[string]*model.ConversionFunc)
+	}
+
+	var helperPkgs []*types.Package
+	for _, path := range paths {
+		pkg, ok := a.pkgs[path]
+		if !ok {
+			slog.Warn("helper package not found, its functions will not be used", "pkg", path)
+			continue
+		}
+		if len(pkg.Errors) > 0 || pkg.Types == nil {
+			slog.Warn("helper package contains errors, its functions will not be used",
+				"pkg", pkg.PkgPath, "errors", pkg.Errors)
+			continue
+		}
+		helperPkgs = append(helperPkgs, pkg.Types)
+	}
+	return a.indexConversionFuncs(helperPkgs, true)
+}
+
+// discoverCustomFuncs resolves the functions named by convert:rule directives in the directive
+// package and indexes them by the conversion they take over. Functions that are not declared,
+// or that do not convert the types of their rule, are reported and left out.
+func (a *TypeAnalyzer) discoverCustomFuncs(pkg *packages.Package, rules map[string]string) map[string]*model.ConversionFunc {
+	customFuncs := make(map[string]*model.ConversionFunc)
+	if pkg == nil || pkg.Types == nil {
+		return customFuncs
+	}
+	for key, name := range rules {
+		fn, ok := pkg.Types.Scope().Lookup(name).(*types.Func)
+		if !ok {
+			slog.Warn("custom conversion function not found, it will not be used", "func", name, "conversion", key)
+			continue
+		}
+		conv := a.toConversionFunc(fn)
+		if conv == nil || conv.SourceType+"->"+conv.TargetType != key {
+			slog.Warn("custom conversion function does not match its rule, it will not be used", "func", name, "conversion", key)
+			continue
+		}
+		customFuncs[key] = conv
+	}
+	return customFuncs
+}
+
+// indexConversionFuncs indexes the conversion functions declared in pkgs by their (A, B) pair.
+// When exportedOnly is set, functions are recorded with their import path so that generated
+// code can call them from another package.
+func (a *TypeAnalyzer) indexConversionFuncs(pkgs []*types.Package, exportedOnly bool) map[string]*model.ConversionFunc {
+	conversionFuncs := make(map[string]*model.ConversionFunc)
+	candidates := make(map[string][]*model.ConversionFunc)
+	var keys []string
+	for _, pkg := range pkgs {
+		scope := pkg.Scope()
+		for _, name := range scope.Names() {
+			fn, ok := scope.Lookup(name).(*types.Func)
+			if !ok || (exportedOnly && !fn.Exported()) {
+				continue
+			}
+			conv := a.toConversionFunc(fn)
+			if conv == nil {
+				continue
+			}
+			if exportedOnly {
+				conv.ImportPath = pkg.Path()
+			}
+			key := conv.SourceType + "->" + conv.TargetType
+			if _, seen := candidates[key]; !seen {
+				keys = append(keys, key)
+			}
+			candidates[key] = append(candidates[key], conv)
+		}
+	}
+
+	for _, key := range keys {
+		matches := candidates[key]
+		if len(matches) > 1 {
+			names := make([]string, len(matches))
+			for i, m := range matches {
+				names[i] = m.QualifiedName()
+			}
+			slog.Warn("ambiguous user conversion functions, none will be used automatically",
+				"conversion", key, "candidates", strings.Join(names, ", "))
+			continue
+		}
+		slog.Debug("Discovered user conversion function", "func", matches[0].QualifiedName(), "conversion", key)
+		conversionFuncs[key] = matches[0]
+	}
+	return conversionFuncs
+}
+
+// toConversionFunc returns a ConversionFunc if fn has a conversion signature, or nil otherwise.
+func (a *TypeAnalyzer) toConversionFunc(fn *types.Func) *model.ConversionFunc {
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok || sig.Recv() != nil {
+		return nil
+	}
+	source, target, returnsError, ok := a.conversionSignature(sig)
+	if !ok {
+		return nil
+	}
+	return &model.ConversionFunc{
+		Name:         fn.Name(),
+		SourceType:   source.UniqueKey(),
+		TargetType:   target.UniqueKey(),
+		ReturnsError: returnsError,
+	}
+}
+
+// conversionSignature resolves the source and target types of a signature shaped like
+// func(A) B or func(A) (B, error). The receiver, if any, is not considered.
+func (a *TypeAnalyzer) conversionSignature(sig *types.Signature) (source, target *model.TypeInfo, returnsError, ok bool) {
+	if sig.TypeParams().Len() > 0 || sig.Variadic() || sig.Params().Len() != 1 {
+		return nil, nil, false, false
+	}
+
+	switch sig.Results().Len() {
+	case 1:
+	case 2:
+		if !types.Identical(sig.Results().At(1).Type(), types.Universe.Lookup("error").Type()) {
+			return nil, nil, false, false
+		}
+		returnsError = true
+	default:
+		return nil, nil, false, false
+	}
+
+	source = a.resolveType(unalias(sig.Params().At(0).Type()))
+	target = a.resolveType(unalias(sig.Results().At(0).Type()))
+	if source == nil || target == nil {
+		return nil, nil, false, false
+	}
+	return source, target, returnsError, true
+}
+
+// discoverConverterInterfaces finds the interfaces of the directive package marked with
+// //go:abgen:implement, optionally followed by the name of the struct to generate.
+// A method without a conversion signature is reported as an error naming the method.
+func (a *TypeAnalyzer) discoverConverterInterfaces(pkg *packages.Package) ([]*model.ConverterInterface, error) {
+	var converters []*model.ConverterInterface
+	if pkg == nil || pkg.Types == nil {
+		return converters, nil
+	}
+
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				doc := typeSpec.Doc
+				if doc == nil && len(genDecl.Specs) == 1 {
+					doc = genDecl.Doc
+				}
+				structName, marked := implementDirective(doc)
+				if !marked {
+					continue
+				}
+				converter, err := a.toConverterInterface(pkg.Types, typeSpec.Name.Name, structName)
+				if err != nil {
+					return nil, err
+				}
+				converters = append(converters, converter)
+			}
+		}
+	}
+	return converters, nil
+}
+
+// implementDirective reports whether doc holds a //go:abgen:implement directive and returns
+// the struct name it gives, if any.
+func implementDirective(doc *ast.CommentGroup) (string, bool) {
+	if doc == nil {
+		return "", false
+	}
+	for _, comment := range doc.List {
+		text := strings.TrimSpace(comment.Text)
+		if text == "//go:abgen:implement" {
+			return "", true
+		}
+		if structName, found := strings.CutPrefix(text, "//go:abgen:implement="); found {
+			return strings.Trim(structName, `"`), true
+		}
+	}
+	return "", false
+}
+
+// toConverterInterface reads the methods of the named interface. The generated struct is
+// named after the interface with an Impl suffix unless structName is given.
+func (a *TypeAnalyzer) toConverterInterface(pkg *types.Package, name, structName string) (*model.ConverterInterface, error) {
+	obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("converter interface %s not found", name)
+	}
+	iface, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return nil, fmt.Errorf("%s is marked with //go:abgen:implement but is not an interface", name)
+	}
+	if structName == "" {
+		structName = name + "Impl"
+	}
+
+	converter := &model.ConverterInterface{Name: name, StructName: structName}
+	for i := 0; i < iface.NumMethods(); i++ {
+		fn := iface.Method(i)
+		source, target, returnsError, ok := a.conversionSignature(fn.Type().(*types.Signature))
+		if !ok {
+			return nil, fmt.Errorf("%s.%s: converter methods must have the signature func(A) B or func(A) (B, error)", name, fn.Name())
+		}
+		converter.Methods = append(converter.Methods, &model.ConverterMethod{
+			Interface:    name,
+			Name:         fn.Name(),
+			Source:       source,
+			Target:       target,
+			ReturnsError: returnsError,
+		})
+	}
+	return converter, nil
+}
+
+// converterRules creates a one-way rule for each struct conversion declared by the methods
+// of the converter interfaces, converting the element types of pointers and slices.
+// Pairs already covered by a rule of cfg are left to that rule.
+func converterRules(cfg *config.Config, converters []*model.ConverterInterface) []*config.ConversionRule {
+	seen := make(map[string]bool)
+	for _, rule := range cfg.ConversionRules {
+		seen[rule.SourceType+"->"+rule.TargetType] = true
+	}
+
+	var rules []*config.ConversionRule
+	for _, converter := range converters {
+		for _, method := range converter.Methods {
+			source := model.GetElementType(method.Source)
+			target := model.GetElementType(method.Target)
+			if !source.IsUltimatelyStruct() || !target.IsUltimatelyStruct() ||
+				source.FQN() == "" || target.FQN() == "" || source.FQN() == target.FQN() {
+				continue
+			}
+			key := source.FQN() + "->" + target.FQN()
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			rules = append(rules, &config.ConversionRule{
+				SourceType: source.FQN(),
+				TargetType: target.FQN(),
+				Direction:  config.DirectionOneway,
+				Origin:     config.OriginConverter,
+				FieldRules: config.FieldRuleSet{
+					Ignore: make(map[string]struct{}),
+					Remap:  make(map[string]string),
+				},
+			})
+		}
+	}
+	return rules
+}
+
+// unalias replaces local type aliases with the types they stand for, so that a signature
+// written against `type User = ent.User` is keyed by ent.User. The predeclared `any` is kept.
+func unalias(typ types.Type) types.Type {
+	if alias, ok := typ.(*types.Alias); ok && alias.Obj().Pkg() == nil {
+		return typ
+	}
+	switch t := types.Unalias(typ).(type) {
+	case *types.Pointer:
+		return types.NewPointer(unalias(t.Elem()))
+	case *types.Slice:
+		return types.NewSlice(unalias(t.Elem()))
+	case *types.Array:
+		return types.NewArray(unalias(t.Elem()), t.Len())
+	case *types.Map:
+		return types.NewMap(unalias(t.Key()), unalias(t.Elem()))
+	default:
+		return t
+	}
+}
+
 // resolveAliasTargetFQN converts an alias's target type expression to a fully qualified name.
 func (a *TypeAnalyzer) resolveAliasTargetFQN(expr ast.Expr, pkg *packages.Package) string {
 	typeStr := types.ExprString(expr)
@@ -260,6 +783,11 @@ func (a *TypeAnalyzer) collectExternalPaths(cfg *config.Config) []string {
 			pathMap[pkgPath] = struct{}{}
 		}
 	}
+	for _, fqn := range slices.Concat(cfg.MapTypes, cfg.DeepCopyTypes) {
+		if pkgPath := getPkgPath(fqn); pkgPath != "" {
+			pathMap[pkgPath] = struct{}{}
+		}
+	}
 	for key := range cfg.CustomFunctionRules {
 		parts := strings.Split(key, "->")
 		if len(parts) == 2 {
@@ -284,20 +812,25 @@ func (a *TypeAnalyzer) collectAllFqns(cfg *config.Config) []string {
 	fqnMap := make(map[string]struct{})
 
 	for _, rule := range cfg.ConversionRules {
-		if rule.SourceType != "" {
+		// Types without a package, such as map[string]any, are not looked up.
+		if getPkgPath(rule.SourceType) != "" {
 			fqnMap[rule.SourceType] = struct{}{}
 		}
-		if rule.TargetType != "" {
+		if getPkgPath(rule.TargetType) != "" {
 			fqnMap[rule.TargetType] = struct{}{}
 		}
 	}
+	for _, fqn := range slices.Concat(cfg.MapTypes, cfg.DeepCopyTypes) {
+		fqnMap[fqn] = struct{}{}
+	}
 	for key := range cfg.CustomFunctionRules {
 		parts := strings.Split(key, "->")
 		if len(parts) == 2 {
-			if parts[0] != "" {
+			// Built-in types have no package to analyze.
+			if getPkgPath(parts[0]) != "" {
 				fqnMap[parts[0]] = struct{}{}
 			}
-			if parts[1] != "" {
+			if getPkgPath(parts[1]) != "" {
 				fqnMap[parts[1]] = struct{}{}
 			}
 		}
@@ -325,16 +858,8 @@ func (a *TypeAnalyzer) find(fqn string) (*model.TypeInfo, error) {
 	}
 	typeName := fqn[len(pkgPath)+1:]
 
-	for _, pkg := range a.pkgs {
-		if pkg.PkgPath == pkgPath {
-			scope := pkg.Types.Scope()
-			if scope == nil {
-				continue
-			}
-			obj := scope.Lookup(typeName)
-			if obj == nil {
-				continue
-			}
+	if pkg, ok := a.pkgs[pkgPath]; ok && pkg.Types != nil {
+		if obj := pkg.Types.Scope().Lookup(typeName); obj != nil {
 			return a.resolveType(obj.Type()), nil
 		}
 	}
@@ -372,6 +897,9 @@ func (a *TypeAnalyzer) resolveType(typ types.Type) *model.TypeInfo {
 			info.ImportPath = obj.Pkg().Path()
 		}
 		info.Original = obj
+		for i := 0; i < t.TypeArgs().Len(); i++ {
+			info.TypeArgs = append(info.TypeArgs, a.resolveType(t.TypeArgs().At(i)))
+		}
 		underlyingInfo := a.resolveType(t.Underlying())
 		if underlyingInfo != nil {
 			if underlyingInfo.Kind == model.Struct {
@@ -400,6 +928,8 @@ func (a *TypeAnalyzer) resolveType(typ types.Type) *model.TypeInfo {
 	case *types.Struct:
 		info.Kind = model.Struct
 		info.Fields = a.parseFields(t)
+		// Anonymous structs keep their type, which Fields does not describe completely.
+		info.Original = types.NewVar(token.NoPos, nil, "", t)
 	case *types.Basic:
 		info.Kind = model.Primitive
 		info.Name = t.Name()
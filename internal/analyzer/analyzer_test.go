@@ -89,3 +89,82 @@ func TestTypeAnalyzer_Analyze_CoreParsing(t *testing.T) {
 		t.Errorf("Expected user rule target to be 'github.com/my/project/pb.User', got '%s'", userRule.TargetType)
 	}
 }
+
+// TestTypeAnalyzer_Analyze_ConverterInterfaces tests that the methods of an interface marked
+// with //go:abgen:implement are read, and turned into rules for the struct conversions.
+func TestTypeAnalyzer_Analyze_ConverterInterfaces(t *testing.T) {
+	testDir, err := filepath.Abs("../../testdata/03_advanced_features/converter_interface")
+	if err != nil {
+		t.Fatalf("Failed to get absolute path for testdata: %v", err)
+	}
+
+	analysisResult, err := NewTypeAnalyzer().Analyze(testDir)
+	if err != nil {
+		t.Fatalf("Analyze() returned an error: %v", err)
+	}
+
+	if len(analysisResult.Converters) != 1 {
+		t.Fatalf("Expected 1 converter interface, got %d", len(analysisResult.Converters))
+	}
+	converter := analysisResult.Converters[0]
+	if converter.Name != "Converter" || converter.StructName != "ConverterImpl" {
+		t.Errorf("Expected Converter implemented by ConverterImpl, got %s implemented by %s", converter.Name, converter.StructName)
+	}
+	methods := make(map[string]bool)
+	for _, method := range converter.Methods {
+		methods[method.QualifiedName()] = method.ReturnsError
+	}
+	expectedMethods := map[string]bool{
+		"Converter.AgeToPB":    false,
+		"Converter.RoleToPB":   false,
+		"Converter.RolesToPB":  false,
+		"Converter.UserFromPB": true,
+		"Converter.UserToPB":   false,
+	}
+	if !reflect.DeepEqual(methods, expectedMethods) {
+		t.Errorf("Expected methods %v, got %v", expectedMethods, methods)
+	}
+
+	const pkg = "github.com/origadmin/abgen/testdata/03_advanced_features/converter_interface/"
+	rules := make(map[string]config.ConversionDirection)
+	for _, rule := range analysisResult.ExecutionPlan.FinalConfig.ConversionRules {
+		rules[strings.TrimPrefix(rule.SourceType, pkg)+"->"+strings.TrimPrefix(rule.TargetType, pkg)] = rule.Direction
+	}
+	for _, key := range []string{"source.User->target.UserPB", "target.UserPB->source.User", "source.Role->target.RolePB"} {
+		if direction, ok := rules[key]; !ok || direction != config.DirectionOneway {
+			t.Errorf("Expected a one-way rule %s, got %q (found: %v)", key, direction, ok)
+		}
+	}
+}
+
+// TestTypeAnalyzer_Analyze_InvalidConverterMethod tests that a converter interface method
+// without a conversion signature is reported by name.
+func TestTypeAnalyzer_Analyze_InvalidConverterMethod(t *testing.T) {
+	testDir, err := filepath.Abs("../../testdata/00_core_parsing/converter_interface_invalid")
+	if err != nil {
+		t.Fatalf("Failed to get absolute path for testdata: %v", err)
+	}
+
+	_, err = NewTypeAnalyzer().Analyze(testDir)
+	if err == nil || !strings.Contains(err.Error(), "Converter.Merge") {
+		t.Errorf("Expected an error naming Converter.Merge, got %v", err)
+	}
+}
+
+// TestTypeAnalyzer_AnalyzePackages_MissingPackage tests that a directory without a package is
+// named once in the error, and does not prevent the analysis of the other directories.
+func TestTypeAnalyzer_AnalyzePackages_MissingPackage(t *testing.T) {
+	testDir, err := filepath.Abs("../../testdata/02_basic_conversions/simple_struct")
+	if err != nil {
+		t.Fatalf("Failed to get absolute path for testdata: %v", err)
+	}
+	missingDir := filepath.Join(t.TempDir(), "missing")
+
+	results, err := NewTypeAnalyzer().AnalyzePackages([]string{testDir, missingDir})
+	if err == nil || err.Error() != missingDir+": no initial package found" {
+		t.Errorf("Expected the error %q, got %v", missingDir+": no initial package found", err)
+	}
+	if len(results) != 2 || results[0] == nil || results[1] != nil {
+		t.Errorf("Expected the result of %s only, got %v", testDir, results)
+	}
+}
@@ -0,0 +1,74 @@
+package analyzer
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// directivePrefix starts every abgen directive comment.
+var directivePrefix = []byte("//go:abgen:")
+
+// DiscoverDirectivePackages returns the directories holding a directive package, in lexical
+// order. A pattern ending with /... is searched recursively like the go command does, skipping
+// testdata and vendor directories and those starting with . or _; any other pattern is a
+// directory returned as is. A directive package is a directory whose non-test Go files contain
+// a //go:abgen: directive.
+func DiscoverDirectivePackages(pattern string) ([]string, error) {
+	root, recursive := strings.CutSuffix(filepath.ToSlash(pattern), "...")
+	if !recursive || root != "" && !strings.HasSuffix(root, "/") {
+		return []string{pattern}, nil
+	}
+	root = filepath.Clean(filepath.FromSlash(root + "."))
+
+	var dirs []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		name := entry.Name()
+		if path != root && (name == "testdata" || name == "vendor" ||
+			strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+			return filepath.SkipDir
+		}
+		found, err := hasDirectives(path)
+		if err != nil {
+			return err
+		}
+		if found {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dirs, nil
+}
+
+// hasDirectives reports whether a non-test Go file of dir contains an abgen directive.
+func hasDirectives(dir string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return false, err
+		}
+		if bytes.Contains(content, directivePrefix) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
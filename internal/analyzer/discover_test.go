@@ -0,0 +1,52 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiscoverDirectivePackages(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"a/directives.go":          "package a\n\n//go:abgen:pair:packages=\"x,y\"\n",
+		"a/b/directives.go":        "package b\n\n//go:abgen:convert=\"X\"\n",
+		"c/plain.go":               "package c\n",
+		"d/directives_test.go":     "package d\n\n//go:abgen:convert=\"X\"\n",
+		"testdata/e/directives.go": "package e\n\n//go:abgen:convert=\"X\"\n",
+		"vendor/f/directives.go":   "package f\n\n//go:abgen:convert=\"X\"\n",
+		".g/directives.go":         "package g\n\n//go:abgen:convert=\"X\"\n",
+		"_h/directives.go":         "package h\n\n//go:abgen:convert=\"X\"\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{pattern: root + "/...", want: []string{filepath.Join(root, "a"), filepath.Join(root, "a", "b")}},
+		{pattern: root + "/a/b/...", want: []string{filepath.Join(root, "a", "b")}},
+		{pattern: root + "/c/...", want: nil},
+		{pattern: root + "/c", want: []string{root + "/c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got, err := DiscoverDirectivePackages(tt.pattern)
+			if err != nil {
+				t.Fatalf("DiscoverDirectivePackages() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiscoverDirectivePackages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
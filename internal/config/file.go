@@ -0,0 +1,387 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileNames are the names of the configuration files looked up by FindFile, in order.
+var FileNames = []string{"abgen.yaml", "abgen.yml", "abgen.json"}
+
+// File is a configuration file, written in YAML or JSON. It holds the settings of the
+// directives: each setting is turned into the directive it stands for, and the directives
+// of a file are parsed before the directives of the directive package, which take precedence
+// over them.
+type File struct {
+	// Packages declares package aliases, as package:path does.
+	Packages []FilePackage `yaml:"packages,omitempty" json:"packages,omitempty"`
+	// Naming holds the naming rules, as the convert:(source|target):(prefix|suffix),
+	// convert:method:(to|from), convert:func:name and naming:* directives do.
+	Naming *FileNaming `yaml:"naming,omitempty" json:"naming,omitempty"`
+	// Behavior holds the global conversion behaviors.
+	Behavior *FileBehavior `yaml:"behavior,omitempty" json:"behavior,omitempty"`
+	// Pairs pairs packages, as pair:packages does.
+	Pairs []FilePair `yaml:"pairs,omitempty" json:"pairs,omitempty"`
+	// Conversions declares conversion rules, as convert does.
+	Conversions []FileConversion `yaml:"conversions,omitempty" json:"conversions,omitempty"`
+	// Functions declares custom conversion functions, as convert:rule does.
+	Functions []FileFunction `yaml:"functions,omitempty" json:"functions,omitempty"`
+	// Helpers names the packages of conversion helpers, as helpers:package does.
+	Helpers []string `yaml:"helpers,omitempty" json:"helpers,omitempty"`
+	// JSONFields names the fields converted to and from JSON, as "<type>#<field>", as
+	// convert:json does.
+	JSONFields []string `yaml:"jsonFields,omitempty" json:"jsonFields,omitempty"`
+	// MapTypes names the types converted to and from maps, as convert:map does.
+	MapTypes []string `yaml:"mapTypes,omitempty" json:"mapTypes,omitempty"`
+	// DeepCopyTypes names the types deep copy functions are generated for, as deepcopy does.
+	DeepCopyTypes []string `yaml:"deepCopyTypes,omitempty" json:"deepCopyTypes,omitempty"`
+	// FieldTime holds the time options of fields, as the convert:time:* directives naming
+	// fields do.
+	FieldTime []FileFieldTime `yaml:"fieldTime,omitempty" json:"fieldTime,omitempty"`
+}
+
+// FilePackage is a package alias of a configuration file.
+type FilePackage struct {
+	Path  string `yaml:"path" json:"path"`
+	Alias string `yaml:"alias,omitempty" json:"alias,omitempty"`
+}
+
+// FilePair is a package pair of a configuration file.
+type FilePair struct {
+	Source string `yaml:"source" json:"source"`
+	Target string `yaml:"target" json:"target"`
+}
+
+// FileConversion is a conversion rule of a configuration file.
+type FileConversion struct {
+	Source    string            `yaml:"source" json:"source"`
+	Target    string            `yaml:"target" json:"target"`
+	Direction string            `yaml:"direction,omitempty" json:"direction,omitempty"`
+	Ignore    []string          `yaml:"ignore,omitempty" json:"ignore,omitempty"`
+	Remap     map[string]string `yaml:"remap,omitempty" json:"remap,omitempty"`
+}
+
+// FileFunction is a custom conversion function of a configuration file.
+type FileFunction struct {
+	Source string `yaml:"source" json:"source"`
+	Target string `yaml:"target" json:"target"`
+	Func   string `yaml:"func" json:"func"`
+}
+
+// FileNaming holds the naming rules of a configuration file.
+type FileNaming struct {
+	SourcePrefix string            `yaml:"sourcePrefix,omitempty" json:"sourcePrefix,omitempty"`
+	SourceSuffix string            `yaml:"sourceSuffix,omitempty" json:"sourceSuffix,omitempty"`
+	TargetPrefix string            `yaml:"targetPrefix,omitempty" json:"targetPrefix,omitempty"`
+	TargetSuffix string            `yaml:"targetSuffix,omitempty" json:"targetSuffix,omitempty"`
+	ToMethod     string            `yaml:"toMethod,omitempty" json:"toMethod,omitempty"`
+	FromMethod   string            `yaml:"fromMethod,omitempty" json:"fromMethod,omitempty"`
+	FuncName     string            `yaml:"funcName,omitempty" json:"funcName,omitempty"`
+	Acronyms     []string          `yaml:"acronyms,omitempty" json:"acronyms,omitempty"`
+	Plurals      map[string]string `yaml:"plurals,omitempty" json:"plurals,omitempty"`
+}
+
+// FileBehavior holds the global conversion behaviors of a configuration file.
+type FileBehavior struct {
+	Direction string    `yaml:"direction,omitempty" json:"direction,omitempty"`
+	Error     string    `yaml:"error,omitempty" json:"error,omitempty"`
+	Helpers   string    `yaml:"helpers,omitempty" json:"helpers,omitempty"`
+	Copy      string    `yaml:"copy,omitempty" json:"copy,omitempty"`
+	MapKey    string    `yaml:"mapKey,omitempty" json:"mapKey,omitempty"`
+	Time      *FileTime `yaml:"time,omitempty" json:"time,omitempty"`
+	Alias     bool      `yaml:"alias,omitempty" json:"alias,omitempty"`
+	Methods   bool      `yaml:"methods,omitempty" json:"methods,omitempty"`
+	Compare   bool      `yaml:"compare,omitempty" json:"compare,omitempty"`
+	Tests     bool      `yaml:"tests,omitempty" json:"tests,omitempty"`
+}
+
+// FileTime holds time options of a configuration file.
+type FileTime struct {
+	Layout string `yaml:"layout,omitempty" json:"layout,omitempty"`
+	Unix   string `yaml:"unix,omitempty" json:"unix,omitempty"`
+	Zone   string `yaml:"zone,omitempty" json:"zone,omitempty"`
+}
+
+// FileFieldTime holds the time options of fields, named as "<type>#<field1>,<field2>".
+type FileFieldTime struct {
+	Fields   string `yaml:"fields" json:"fields"`
+	FileTime `yaml:",inline"`
+}
+
+// FindFile looks up a configuration file in dir, then in its parent directories up to the
+// root of the module dir belongs to. It returns the path of the first file found, or an
+// empty path if there is none.
+func FindFile(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		for _, name := range FileNames {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err == nil {
+				return path, nil
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return "", err
+			}
+		}
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return "", nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// LoadFile reads the configuration file at path, as JSON if its extension is .json and as
+// YAML otherwise. Unknown settings are reported as errors.
+func LoadFile(path string) (*File, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	file := &File{}
+	if filepath.Ext(path) == ".json" {
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(file)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		err = decoder.Decode(file)
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// Directives returns the directives the settings of the file stand for. Package aliases come
+// first and the global behaviors before the conversion rules, whose default direction they
+// set.
+func (f *File) Directives() []string {
+	var directives []string
+	add := func(key, value string) {
+		directives = append(directives, "//go:abgen:"+key+`="`+value+`"`)
+	}
+	addBool := func(key string, value bool) {
+		if value {
+			add(key, "true")
+		}
+	}
+	addString := func(key, value string) {
+		if value != "" {
+			add(key, value)
+		}
+	}
+
+	for _, pkg := range f.Packages {
+		if pkg.Alias != "" {
+			directives = append(directives, "//go:abgen:package:path="+pkg.Path+",alias="+pkg.Alias)
+		} else {
+			directives = append(directives, "//go:abgen:package:path="+pkg.Path)
+		}
+	}
+
+	if naming := f.Naming; naming != nil {
+		addString("convert:source:prefix", naming.SourcePrefix)
+		addString("convert:source:suffix", naming.SourceSuffix)
+		addString("convert:target:prefix", naming.TargetPrefix)
+		addString("convert:target:suffix", naming.TargetSuffix)
+		addString("convert:method:to", naming.ToMethod)
+		addString("convert:method:from", naming.FromMethod)
+		addString("convert:func:name", naming.FuncName)
+		if naming.Acronyms != nil {
+			add("naming:acronyms", strings.Join(naming.Acronyms, ","))
+		}
+		if len(naming.Plurals) > 0 {
+			var plurals []string
+			for _, singular := range slices.Sorted(maps.Keys(naming.Plurals)) {
+				plurals = append(plurals, singular+":"+naming.Plurals[singular])
+			}
+			add("naming:plurals", strings.Join(plurals, ","))
+		}
+	}
+
+	if behavior := f.Behavior; behavior != nil {
+		addString("convert:direction", behavior.Direction)
+		addString("convert:error", behavior.Error)
+		addString("helpers:mode", behavior.Helpers)
+		addString("convert:copy", behavior.Copy)
+		addString("convert:map:key", behavior.MapKey)
+		if behavior.Time != nil {
+			addString("convert:time:layout", behavior.Time.Layout)
+			addString("convert:time:unix", behavior.Time.Unix)
+			addString("convert:time:zone", behavior.Time.Zone)
+		}
+		addBool("convert:alias:generate", behavior.Alias)
+		addBool("convert:methods", behavior.Methods)
+		addBool("compare:generate", behavior.Compare)
+		addBool("test:generate", behavior.Tests)
+	}
+
+	for _, pair := range f.Pairs {
+		add("pair:packages", pair.Source+","+pair.Target)
+	}
+	for _, conversion := range f.Conversions {
+		parts := []string{"source=" + conversion.Source, "target=" + conversion.Target}
+		if conversion.Direction != "" {
+			parts = append(parts, "direction="+conversion.Direction)
+		}
+		if len(conversion.Ignore) > 0 {
+			parts = append(parts, "ignore="+strings.Join(conversion.Ignore, ";"))
+		}
+		if len(conversion.Remap) > 0 {
+			var remaps []string
+			for _, from := range slices.Sorted(maps.Keys(conversion.Remap)) {
+				remaps = append(remaps, from+":"+conversion.Remap[from])
+			}
+			parts = append(parts, "remap="+strings.Join(remaps, ";"))
+		}
+		add("convert", strings.Join(parts, ","))
+	}
+	for _, function := range f.Functions {
+		add("convert:rule", "source:"+function.Source+",target:"+function.Target+",func:"+function.Func)
+	}
+
+	if len(f.Helpers) > 0 {
+		add("helpers:package", strings.Join(f.Helpers, ","))
+	}
+	for _, field := range f.JSONFields {
+		add("convert:json", field)
+	}
+	if len(f.MapTypes) > 0 {
+		add("convert:map", strings.Join(f.MapTypes, ","))
+	}
+	if len(f.DeepCopyTypes) > 0 {
+		add("deepcopy", strings.Join(f.DeepCopyTypes, ","))
+	}
+	for _, fieldTime := range f.FieldTime {
+		if fieldTime.Layout != "" {
+			add("convert:time:layout", fieldTime.Fields+"="+fieldTime.Layout)
+		}
+		if fieldTime.Unix != "" {
+			add("convert:time:unix", fieldTime.Fields+"="+fieldTime.Unix)
+		}
+		if fieldTime.Zone != "" {
+			add("convert:time:zone", fieldTime.Fields+"="+fieldTime.Zone)
+		}
+	}
+	return directives
+}
+
+// NewFile returns the configuration file holding the settings of cfg, with fully qualified
+// type names. The default package aliases and the rules not declared by directives or
+// overrides, such as those of converter interfaces or those added by the planner, are left
+// out.
+func NewFile(cfg *Config) *File {
+	f := &File{
+		Helpers:       slices.Clone(cfg.HelperPackages),
+		MapTypes:      slices.Clone(cfg.MapTypes),
+		DeepCopyTypes: slices.Clone(cfg.DeepCopyTypes),
+	}
+
+	for _, alias := range slices.Sorted(maps.Keys(cfg.PackageAliases)) {
+		if path := cfg.PackageAliases[alias]; defaultPackageAliases[alias] != path {
+			f.Packages = append(f.Packages, FilePackage{Path: path, Alias: alias})
+		}
+	}
+
+	naming := cfg.NamingRules
+	f.Naming = &FileNaming{
+		SourcePrefix: naming.SourcePrefix,
+		SourceSuffix: naming.SourceSuffix,
+		TargetPrefix: naming.TargetPrefix,
+		TargetSuffix: naming.TargetSuffix,
+		ToMethod:     naming.ToMethod,
+		FromMethod:   naming.FromMethod,
+		FuncName:     naming.FuncName,
+		Acronyms:     slices.Clone(naming.Acronyms),
+		Plurals:      maps.Clone(naming.Plurals),
+	}
+	if reflect.ValueOf(*f.Naming).IsZero() {
+		f.Naming = nil
+	}
+
+	behavior := cfg.GlobalBehaviorRules
+	f.Behavior = &FileBehavior{
+		Direction: string(behavior.DefaultDirection),
+		Error:     string(behavior.ErrorMode),
+		Helpers:   string(behavior.HelperMode),
+		Copy:      string(behavior.CopyMode),
+		MapKey:    string(behavior.MapKey),
+		Alias:     behavior.GenerateAlias,
+		Methods:   behavior.GenerateMethods,
+		Compare:   behavior.GenerateCompare,
+		Tests:     behavior.GenerateTests,
+	}
+	if behavior.Time != (TimeOptions{}) {
+		f.Behavior.Time = &FileTime{Layout: behavior.Time.Layout, Unix: behavior.Time.Unix, Zone: behavior.Time.Zone}
+	}
+
+	for _, pair := range cfg.PackagePairs {
+		f.Pairs = append(f.Pairs, FilePair{Source: pair.SourcePath, Target: pair.TargetPath})
+	}
+	for _, rule := range cfg.ConversionRules {
+		if rule.Origin != OriginDirective && rule.Origin != OriginCLI {
+			continue
+		}
+		conversion := FileConversion{Source: rule.SourceType, Target: rule.TargetType, Direction: string(rule.Direction)}
+		if len(rule.FieldRules.Ignore) > 0 {
+			conversion.Ignore = slices.Sorted(maps.Keys(rule.FieldRules.Ignore))
+		}
+		if len(rule.FieldRules.Remap) > 0 {
+			conversion.Remap = maps.Clone(rule.FieldRules.Remap)
+		}
+		f.Conversions = append(f.Conversions, conversion)
+	}
+	for _, key := range slices.Sorted(maps.Keys(cfg.CustomFunctionRules)) {
+		source, target, _ := strings.Cut(key, "->")
+		f.Functions = append(f.Functions, FileFunction{Source: source, Target: target, Func: cfg.CustomFunctionRules[key]})
+	}
+
+	for _, field := range slices.Sorted(maps.Keys(cfg.JSONFields)) {
+		if cfg.JSONFields[field] {
+			f.JSONFields = append(f.JSONFields, field)
+		}
+	}
+	for _, field := range slices.Sorted(maps.Keys(cfg.FieldTimeOptions)) {
+		opts := cfg.FieldTimeOptions[field]
+		f.FieldTime = append(f.FieldTime, FileFieldTime{
+			Fields:   field,
+			FileTime: FileTime{Layout: opts.Layout, Unix: opts.Unix, Zone: opts.Zone},
+		})
+	}
+	return f
+}
+
+// WriteYAML writes the configuration file as YAML.
+func (f *File) WriteYAML(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(f); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// WriteJSON writes the configuration file as indented JSON.
+func (f *File) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(f)
+}
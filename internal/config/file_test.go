@@ -0,0 +1,252 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testConfigYAML = `
+packages:
+  - path: path/to/ent
+    alias: ent
+  - path: path/to/pb
+naming:
+  targetSuffix: PB
+  acronyms: [ID, URL]
+  plurals:
+    Criterion: Criteria
+behavior:
+  direction: oneway
+  error: return
+  time:
+    layout: RFC3339Nano
+  compare: true
+pairs:
+  - source: ent
+    target: pb
+conversions:
+  - source: ent.User
+    target: pb.User
+    ignore: [Password, Salt]
+    remap:
+      CreatedAt: Created
+  - source: ent.Group
+    target: pb.Group
+    direction: both
+functions:
+  - source: ent.User
+    target: pb.User
+    func: ConvertUserWithRoles
+helpers: [github.com/acme/convx]
+jsonFields: [ent.User#Settings]
+mapTypes: [ent.User]
+deepCopyTypes: [ent.Group]
+fieldTime:
+  - fields: ent.User#Birthday,Deadline
+    layout: DateOnly
+`
+
+func writeConfigFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func parseFile(t *testing.T, file *File, directives ...string) *Config {
+	t.Helper()
+	cfg, err := NewParser().ParseDirectives(append(file.Directives(), directives...), mockCurrentPkgName, mockCurrentPkgPath)
+	if err != nil {
+		t.Fatalf("ParseDirectives() error = %v", err)
+	}
+	return cfg
+}
+
+func TestLoadFile(t *testing.T) {
+	file, err := LoadFile(writeConfigFile(t, t.TempDir(), "abgen.yaml", testConfigYAML))
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	cfg := parseFile(t, file)
+
+	if cfg.PackageAliases["ent"] != "path/to/ent" || cfg.PackageAliases["pb"] != "path/to/pb" {
+		t.Errorf("PackageAliases = %v", cfg.PackageAliases)
+	}
+	wantNaming := NamingRules{
+		TargetSuffix: "PB",
+		Acronyms:     []string{"ID", "URL"},
+		Plurals:      map[string]string{"Criterion": "Criteria"},
+	}
+	if !reflect.DeepEqual(cfg.NamingRules, wantNaming) {
+		t.Errorf("NamingRules = %+v, want %+v", cfg.NamingRules, wantNaming)
+	}
+	behavior := cfg.GlobalBehaviorRules
+	if behavior.DefaultDirection != DirectionOneway || behavior.ErrorMode != ErrorModeReturn ||
+		behavior.Time.Layout != "RFC3339Nano" || !behavior.GenerateCompare {
+		t.Errorf("GlobalBehaviorRules = %+v", behavior)
+	}
+	if len(cfg.PackagePairs) != 1 || *cfg.PackagePairs[0] != (PackagePair{SourcePath: "path/to/ent", TargetPath: "path/to/pb"}) {
+		t.Errorf("PackagePairs = %v", cfg.PackagePairs)
+	}
+
+	wantRules := []*ConversionRule{
+		{
+			SourceType: "path/to/ent.User",
+			TargetType: "path/to/pb.User",
+			Direction:  DirectionOneway,
+			FieldRules: FieldRuleSet{
+				Ignore: map[string]struct{}{"Password": {}, "Salt": {}},
+				Remap:  map[string]string{"CreatedAt": "Created"},
+			},
+			CustomFunc: "ConvertUserWithRoles",
+			Origin:     OriginDirective,
+		},
+		{
+			SourceType: "path/to/ent.Group",
+			TargetType: "path/to/pb.Group",
+			Direction:  DirectionBoth,
+			FieldRules: FieldRuleSet{Ignore: map[string]struct{}{}, Remap: map[string]string{}},
+			Origin:     OriginDirective,
+		},
+	}
+	if !reflect.DeepEqual(cfg.ConversionRules, wantRules) {
+		t.Errorf("ConversionRules = %+v, want %+v", cfg.ConversionRules, wantRules)
+	}
+
+	if !reflect.DeepEqual(cfg.HelperPackages, []string{"github.com/acme/convx"}) ||
+		!reflect.DeepEqual(cfg.MapTypes, []string{"path/to/ent.User"}) ||
+		!reflect.DeepEqual(cfg.DeepCopyTypes, []string{"path/to/ent.Group"}) ||
+		!cfg.JSONFields["path/to/ent.User#Settings"] {
+		t.Errorf("helpers, map, deep copy or JSON types = %v %v %v %v",
+			cfg.HelperPackages, cfg.MapTypes, cfg.DeepCopyTypes, cfg.JSONFields)
+	}
+	wantTime := map[string]TimeOptions{
+		"path/to/ent.User#Birthday": {Layout: "DateOnly"},
+		"path/to/ent.User#Deadline": {Layout: "DateOnly"},
+	}
+	if !reflect.DeepEqual(cfg.FieldTimeOptions, wantTime) {
+		t.Errorf("FieldTimeOptions = %v, want %v", cfg.FieldTimeOptions, wantTime)
+	}
+}
+
+func TestLoadFile_JSON(t *testing.T) {
+	dir := t.TempDir()
+	file, err := LoadFile(writeConfigFile(t, dir, "abgen.json",
+		`{"packages": [{"path": "path/to/ent", "alias": "ent"}], "fieldTime": [{"fields": "ent.User#Birthday", "unix": "ms"}]}`))
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	cfg := parseFile(t, file)
+	if got := cfg.FieldTimeOptions["path/to/ent.User#Birthday"]; got.Unix != "ms" {
+		t.Errorf("FieldTimeOptions = %v", cfg.FieldTimeOptions)
+	}
+
+	for name, content := range map[string]string{
+		"abgen.yaml": "pairs:\n  - source: ent\n    target: pb\n    direction: oneway\n",
+		"abgen.json": `{"conversion": []}`,
+	} {
+		if _, err := LoadFile(writeConfigFile(t, dir, name, content)); err == nil {
+			t.Errorf("LoadFile(%s) with an unknown setting succeeded", name)
+		}
+	}
+
+	file, err = LoadFile(writeConfigFile(t, dir, "abgen.yaml", "# nothing yet\n"))
+	if err != nil || len(file.Directives()) != 0 {
+		t.Errorf("LoadFile() of an empty file = %v, %v", file.Directives(), err)
+	}
+}
+
+func TestFile_Precedence(t *testing.T) {
+	file, err := LoadFile(writeConfigFile(t, t.TempDir(), "abgen.yaml", testConfigYAML))
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	cfg := parseFile(t, file,
+		`//go:abgen:package:path=path/to/v2/pb,alias=pb`,
+		`//go:abgen:convert:target:suffix="Proto"`,
+		`//go:abgen:convert:error="ignore"`,
+		`//go:abgen:convert="source=ent.Role,target=pb.Role"`,
+		`//go:abgen:convert="source=ent.Group,target=pb.Group,ignore=Members"`,
+	)
+
+	if got := cfg.PackageAliases["pb"]; got != "path/to/v2/pb" {
+		t.Errorf("directive alias pb = %q, want path/to/v2/pb", got)
+	}
+	if cfg.NamingRules.TargetSuffix != "Proto" || cfg.GlobalBehaviorRules.ErrorMode != ErrorModeIgnore {
+		t.Errorf("directives did not override the file: %+v %+v", cfg.NamingRules, cfg.GlobalBehaviorRules)
+	}
+	if cfg.GlobalBehaviorRules.DefaultDirection != DirectionOneway {
+		t.Errorf("DefaultDirection = %q, want the oneway direction of the file", cfg.GlobalBehaviorRules.DefaultDirection)
+	}
+	if len(cfg.ConversionRules) != 3 || cfg.ConversionRules[2].TargetType != "path/to/v2/pb.Role" {
+		t.Errorf("ConversionRules = %+v, want the rules of the file followed by the directive rule", cfg.ConversionRules)
+	}
+	// The directive rule for the types of a rule of the file replaces it.
+	if group := cfg.ConversionRules[1]; group.SourceType != "path/to/ent.Group" || group.Direction != DirectionOneway ||
+		!reflect.DeepEqual(group.FieldRules.Ignore, map[string]struct{}{"Members": {}}) {
+		t.Errorf("rule of Group = %+v, want the directive rule", group)
+	}
+}
+
+func TestNewFile(t *testing.T) {
+	file, err := LoadFile(writeConfigFile(t, t.TempDir(), "abgen.yaml", testConfigYAML))
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	cfg := parseFile(t, file)
+	cfg.ConversionRules = append(cfg.ConversionRules, &ConversionRule{
+		SourceType: "path/to/ent.Role", TargetType: "path/to/pb.Role", Origin: OriginPackagePair,
+	})
+
+	// The file of a configuration configures the same, with fully qualified names.
+	var buf bytes.Buffer
+	if err := NewFile(cfg).WriteYAML(&buf); err != nil {
+		t.Fatalf("WriteYAML() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "ent.Role") || strings.Contains(buf.String(), "uuid") {
+		t.Errorf("WriteYAML() wrote a planned rule or a default alias:\n%s", buf.String())
+	}
+	dumped, err := LoadFile(writeConfigFile(t, t.TempDir(), "abgen.yaml", buf.String()))
+	if err != nil {
+		t.Fatalf("LoadFile() of the dumped file error = %v\n%s", err, buf.String())
+	}
+	got := parseFile(t, dumped)
+	cfg.ConversionRules = cfg.ConversionRules[:2]
+	if !reflect.DeepEqual(got, cfg) {
+		t.Errorf("dumped configuration = %+v, want %+v", got, cfg)
+	}
+}
+
+func TestFindFile(t *testing.T) {
+	root := t.TempDir()
+	module := filepath.Join(root, "module")
+	dir := filepath.Join(module, "internal", "convert")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeConfigFile(t, module, "go.mod", "module example.com/module\n")
+
+	if got, err := FindFile(dir); err != nil || got != "" {
+		t.Errorf("FindFile() without a file = %q, %v, want none", got, err)
+	}
+	// Files above the module root are not found.
+	writeConfigFile(t, root, "abgen.yaml", "")
+	if got, err := FindFile(dir); err != nil || got != "" {
+		t.Errorf("FindFile() with a file above the module = %q, %v, want none", got, err)
+	}
+
+	want := writeConfigFile(t, module, "abgen.json", "{}")
+	if got, err := FindFile(dir); err != nil || got != want {
+		t.Errorf("FindFile() = %q, %v, want %q", got, err, want)
+	}
+	want = writeConfigFile(t, filepath.Join(module, "internal"), "abgen.yml", "")
+	if got, err := FindFile(dir); err != nil || got != want {
+		t.Errorf("FindFile() = %q, %v, want the nearest file %q", got, err, want)
+	}
+}
@@ -3,7 +3,10 @@ package config
 import (
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
+	"text/template"
+	"time"
 )
 
 // defaultPackageAliases provides a set of commonly used packages.
@@ -15,7 +18,12 @@ var defaultPackageAliases = map[string]string{
 
 // Parser is responsible for parsing abgen directives and building a Config object.
 type Parser struct {
-	config *Config
+	config    *Config
+	overrides []string
+	// origin is the origin of the conversion rules parsed.
+	origin RuleOrigin
+	// defaultDirectionRules are the conversion rules that do not set their direction.
+	defaultDirectionRules []*ConversionRule
 }
 
 // NewParser creates a new instance of a Parser.
@@ -26,6 +34,17 @@ func NewParser() *Parser {
 	}
 	return &Parser{
 		config: config,
+		origin: OriginDirective,
+	}
+}
+
+// SetOverrides sets the directives given on the command line, with or without their
+// //go:abgen: prefix. ParseDirectives parses them after the directives of the package, so
+// that they take precedence over them, and their conversion rules originate from the CLI.
+func (p *Parser) SetOverrides(overrides []string) {
+	p.overrides = make([]string, len(overrides))
+	for i, override := range overrides {
+		p.overrides[i] = "//go:abgen:" + strings.TrimPrefix(strings.TrimSpace(override), "//go:abgen:")
 	}
 }
 
@@ -35,13 +54,13 @@ func (p *Parser) ParseDirectives(directives []string, currentPkgName, currentPkg
 	p.config.GenerationContext.PackageName = currentPkgName
 	p.config.GenerationContext.PackagePath = currentPkgPath
 
-	if len(directives) == 0 {
+	if len(directives) == 0 && len(p.overrides) == 0 {
 		slog.Warn("no abgen directives found, no code will be generated", "package", currentPkgPath)
 		return p.config, nil
 	}
 
 	// First pass: Process only package:path directives to populate aliases.
-	for _, directive := range directives {
+	for _, directive := range slices.Concat(directives, p.overrides) {
 		if strings.Contains(directive, "package:path") {
 			if err := p.parseSingleDirective(directive); err != nil {
 				return nil, err
@@ -58,6 +77,20 @@ func (p *Parser) ParseDirectives(directives []string, currentPkgName, currentPkg
 		}
 	}
 
+	// Overrides are parsed last, so that they take precedence.
+	p.origin = OriginCLI
+	for _, override := range p.overrides {
+		if !strings.Contains(override, "package:path") {
+			if err := p.parseSingleDirective(override); err != nil {
+				return nil, fmt.Errorf("invalid override %s: %w", strings.TrimPrefix(override, "//go:abgen:"), err)
+			}
+		}
+	}
+	p.origin = OriginDirective
+	for _, override := range p.overrides {
+		p.config.Overrides = append(p.config.Overrides, strings.TrimPrefix(override, "//go:abgen:"))
+	}
+
 	p.mergeCustomFuncRules()
 
 	return p.config, nil
@@ -86,14 +119,84 @@ func (p *Parser) parseSingleDirective(directive string) error {
 		p.config.NamingRules.SourcePrefix = value
 	case "convert:target:prefix":
 		p.config.NamingRules.TargetPrefix = value
+	case "convert:methods":
+		p.config.GlobalBehaviorRules.GenerateMethods = value == "true"
+	case "convert:method:to":
+		p.config.NamingRules.ToMethod = value
+	case "convert:method:from":
+		p.config.NamingRules.FromMethod = value
+	case "convert:func:name":
+		if _, err := template.New(key).Parse(value); err != nil {
+			return fmt.Errorf("invalid convert:func:name template %q: %w", value, err)
+		}
+		p.config.NamingRules.FuncName = value
+	case "naming:acronyms":
+		p.config.NamingRules.Acronyms = []string{}
+		for _, acronym := range strings.Split(value, ",") {
+			if acronym = strings.TrimSpace(acronym); acronym != "" {
+				p.config.NamingRules.Acronyms = append(p.config.NamingRules.Acronyms, acronym)
+			}
+		}
+	case "naming:plurals":
+		p.parsePlurals(value)
 	case "convert:alias:generate":
 		p.config.GlobalBehaviorRules.GenerateAlias = value == "true"
+	case "compare:generate":
+		p.config.GlobalBehaviorRules.GenerateCompare = value == "true"
+	case "test:generate":
+		p.config.GlobalBehaviorRules.GenerateTests = value == "true"
 	case "convert:direction":
 		if value == "oneway" {
 			p.config.GlobalBehaviorRules.DefaultDirection = DirectionOneway
 		} else {
 			p.config.GlobalBehaviorRules.DefaultDirection = DirectionBoth
 		}
+		// An override also applies to the rules parsed before it that do not set a direction.
+		if p.origin == OriginCLI {
+			for _, rule := range p.defaultDirectionRules {
+				rule.Direction = p.config.GlobalBehaviorRules.DefaultDirection
+			}
+		}
+	case "convert:error":
+		if value == string(ErrorModeReturn) {
+			p.config.GlobalBehaviorRules.ErrorMode = ErrorModeReturn
+		} else {
+			p.config.GlobalBehaviorRules.ErrorMode = ErrorModeIgnore
+		}
+	case "helpers:mode":
+		if value == string(HelperModeInline) {
+			p.config.GlobalBehaviorRules.HelperMode = HelperModeInline
+		} else {
+			p.config.GlobalBehaviorRules.HelperMode = HelperModeRuntime
+		}
+	case "convert:time:layout":
+		p.parseTimeOption(key, value, func(o *TimeOptions, v string) { o.Layout = v })
+	case "convert:time:unix":
+		p.parseTimeOption(key, value, func(o *TimeOptions, v string) { o.Unix = v })
+	case "convert:time:zone":
+		p.parseTimeOption(key, value, func(o *TimeOptions, v string) { o.Zone = v })
+	case "convert:json":
+		for _, fieldKey := range p.parseFieldKeys(value) {
+			p.config.JSONFields[fieldKey] = true
+		}
+	case "convert:map":
+		p.config.MapTypes = p.appendTypeFQNs(p.config.MapTypes, value)
+	case "convert:copy":
+		if value == string(CopyDeep) {
+			p.config.GlobalBehaviorRules.CopyMode = CopyDeep
+		} else {
+			p.config.GlobalBehaviorRules.CopyMode = CopyShallow
+		}
+	case "deepcopy":
+		p.config.DeepCopyTypes = p.appendTypeFQNs(p.config.DeepCopyTypes, value)
+	case "convert:map:key":
+		if value == string(MapKeyJSON) || value == string(MapKeyField) {
+			p.config.GlobalBehaviorRules.MapKey = MapKeyStrategy(value)
+		} else {
+			slog.Warn("ignoring invalid map key strategy, expected field or json", "value", value)
+		}
+	case "helpers:package":
+		p.parseHelperPackages(value)
 	case "convert":
 		p.parseConvertRule(value)
 	case "convert:rule":
@@ -102,6 +205,23 @@ func (p *Parser) parseSingleDirective(directive string) error {
 	return nil
 }
 
+// parsePlurals parses a naming:plurals directive, a comma-separated list of singular:plural
+// pairs.
+func (p *Parser) parsePlurals(value string) {
+	for _, pair := range strings.Split(value, ",") {
+		singular, plural, found := strings.Cut(pair, ":")
+		singular, plural = strings.TrimSpace(singular), strings.TrimSpace(plural)
+		if !found || singular == "" || plural == "" {
+			slog.Warn("ignoring invalid plural, expected <singular>:<plural>", "value", pair)
+			continue
+		}
+		if p.config.NamingRules.Plurals == nil {
+			p.config.NamingRules.Plurals = make(map[string]string)
+		}
+		p.config.NamingRules.Plurals[singular] = plural
+	}
+}
+
 func (p *Parser) parsePackagePath(value string) {
 	parts := strings.Split(value, ",")
 	path := parts[0]
@@ -115,6 +235,91 @@ func (p *Parser) parsePackagePath(value string) {
 	slog.Debug("Parser.parsePackagePath after processing", "alias", alias, "path", path, "allPackageAliases", p.config.PackageAliases)
 }
 
+// parseHelperPackages records the import paths of packages providing conversion helpers.
+// Package aliases declared with package:path may be used in place of full paths.
+func (p *Parser) parseHelperPackages(value string) {
+	for _, part := range strings.Split(value, ",") {
+		path := p.resolvePackagePath(strings.TrimSpace(part))
+		if path == "" || slices.Contains(p.config.HelperPackages, path) {
+			continue
+		}
+		p.config.HelperPackages = append(p.config.HelperPackages, path)
+	}
+}
+
+// appendTypeFQNs resolves a comma-separated list of type references, as used by convert:map
+// and deepcopy, and appends those not yet present to fqns.
+func (p *Parser) appendTypeFQNs(fqns []string, value string) []string {
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		if fqn := p.resolveTypeFQN(part); !slices.Contains(fqns, fqn) {
+			fqns = append(fqns, fqn)
+		}
+	}
+	return fqns
+}
+
+// parseTimeOption applies a convert:time:* directive either globally ("RFC3339Nano") or to
+// specific fields ("ent.User#Birthday,Deadline=DateOnly"). Invalid values are reported and ignored.
+func (p *Parser) parseTimeOption(key, value string, apply func(*TimeOptions, string)) {
+	var fieldSpec string
+	if typeAndFields, optionValue, found := strings.Cut(value, "="); found && strings.Contains(typeAndFields, "#") {
+		fieldSpec, value = typeAndFields, optionValue
+	}
+	if err := validateTimeOption(key, value); err != nil {
+		slog.Warn("ignoring invalid time directive", "directive", key, "value", value, "error", err)
+		return
+	}
+
+	if fieldSpec == "" {
+		apply(&p.config.GlobalBehaviorRules.Time, value)
+		return
+	}
+	for _, fieldKey := range p.parseFieldKeys(fieldSpec) {
+		opts := p.config.FieldTimeOptions[fieldKey]
+		apply(&opts, value)
+		p.config.FieldTimeOptions[fieldKey] = opts
+	}
+}
+
+// parseFieldKeys expands a "<type>#<field1>,<field2>" spec into one "<type FQN>#<field>" key per field.
+func (p *Parser) parseFieldKeys(spec string) []string {
+	typeName, fields, found := strings.Cut(spec, "#")
+	if !found {
+		slog.Warn("invalid field spec, expected <type>#<field>", "value", spec)
+		return nil
+	}
+	typeFQN := p.resolveTypeFQN(strings.TrimSpace(typeName))
+	var keys []string
+	for _, field := range strings.Split(fields, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			keys = append(keys, typeFQN+"#"+field)
+		}
+	}
+	return keys
+}
+
+// validateTimeOption checks the value of a convert:time:* directive.
+func validateTimeOption(key, value string) error {
+	switch key {
+	case "convert:time:layout":
+		if value == "" {
+			return fmt.Errorf("empty layout")
+		}
+	case "convert:time:unix":
+		if value != "s" && value != "ms" && value != "ns" {
+			return fmt.Errorf("unknown unit %q, expected s, ms or ns", value)
+		}
+	case "convert:time:zone":
+		if _, err := time.LoadLocation(value); err != nil || value == "" {
+			return fmt.Errorf("unknown time zone %q", value)
+		}
+	}
+	return nil
+}
+
 func (p *Parser) resolvePackagePath(identifier string) string {
 	if path, ok := p.config.PackageAliases[identifier]; ok {
 		return path
@@ -141,11 +346,13 @@ func (p *Parser) parseConvertRule(value string) {
 	parts := strings.Split(value, ",")
 	rule := &ConversionRule{
 		Direction: p.config.GlobalBehaviorRules.DefaultDirection,
+		Origin:    p.origin,
 		FieldRules: FieldRuleSet{
 			Ignore: make(map[string]struct{}),
 			Remap:  make(map[string]string),
 		},
 	}
+	explicitDirection := false
 	for _, part := range parts {
 		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
 		if len(kv) != 2 {
@@ -154,14 +361,16 @@ func (p *Parser) parseConvertRule(value string) {
 		key, val := kv[0], kv[1]
 		switch key {
 		case "source":
-			rule.SourceType = p.resolveTypeFQN(val)
+			rule.SourceType = p.resolveConvertTypeFQN(val)
 		case "target":
-			rule.TargetType = p.resolveTypeFQN(val)
+			rule.TargetType = p.resolveConvertTypeFQN(val)
 		case "direction":
 			if val == "oneway" {
 				rule.Direction = DirectionOneway
+				explicitDirection = true
 			} else if val == "both" {
 				rule.Direction = DirectionBoth
+				explicitDirection = true
 			}
 		case "ignore":
 			for _, field := range strings.Split(val, ";") {
@@ -176,9 +385,21 @@ func (p *Parser) parseConvertRule(value string) {
 			}
 		}
 	}
-	if rule.SourceType != "" && rule.TargetType != "" {
-		p.config.ConversionRules = append(p.config.ConversionRules, rule)
+	if rule.SourceType == "" || rule.TargetType == "" {
+		return
+	}
+	if !explicitDirection {
+		p.defaultDirectionRules = append(p.defaultDirectionRules, rule)
 	}
+	// A later rule for the same types replaces the earlier one, so that the directives of the
+	// package take precedence over its configuration file, and overrides over both.
+	for i, existing := range p.config.ConversionRules {
+		if existing.SourceType == rule.SourceType && existing.TargetType == rule.TargetType {
+			p.config.ConversionRules[i] = rule
+			return
+		}
+	}
+	p.config.ConversionRules = append(p.config.ConversionRules, rule)
 }
 
 func (p *Parser) parseCustomFuncRule(value string) {
@@ -200,8 +421,8 @@ func (p *Parser) parseCustomFuncRule(value string) {
 		}
 	}
 	if source != "" && target != "" && funcName != "" {
-		sourceFQN := p.resolveTypeFQN(source)
-		targetFQN := p.resolveTypeFQN(target)
+		sourceFQN := p.resolveRuleTypeFQN(source)
+		targetFQN := p.resolveRuleTypeFQN(target)
 		mapKey := fmt.Sprintf("%s->%s", sourceFQN, targetFQN)
 		p.config.CustomFunctionRules[mapKey] = funcName
 	}
@@ -216,6 +437,28 @@ func (p *Parser) mergeCustomFuncRules() {
 	}
 }
 
+// AnyMapTypeKey is the unique key of map[string]any, which convert directives may name to
+// set the field rules of a struct to map conversion.
+const AnyMapTypeKey = "map[string]interface{}"
+
+// resolveConvertTypeFQN resolves a type of a convert directive, where map[string]any is
+// written as it is.
+func (p *Parser) resolveConvertTypeFQN(typeStr string) string {
+	if typeStr == "map[string]any" || typeStr == AnyMapTypeKey {
+		return AnyMapTypeKey
+	}
+	return p.resolveTypeFQN(typeStr)
+}
+
+// resolveRuleTypeFQN resolves a type of a convert:rule directive, where built-in types are
+// written builtin.<type> and resolve to their name.
+func (p *Parser) resolveRuleTypeFQN(typeStr string) string {
+	if name, ok := strings.CutPrefix(typeStr, "builtin."); ok {
+		return name
+	}
+	return p.resolveTypeFQN(typeStr)
+}
+
 func (p *Parser) resolveTypeFQN(typeStr string) string {
 	lastDot := strings.LastIndex(typeStr, ".")
 	if lastDot == -1 {
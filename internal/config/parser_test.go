@@ -2,6 +2,7 @@ package config
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -41,6 +42,7 @@ func TestParser_Comprehensive(t *testing.T) {
 							Remap:  map[string]string{"CreatedAt": "Created"},
 						},
 						CustomFunc: "ConvertUserWithPermissions",
+						Origin:     OriginDirective,
 					},
 				},
 				NamingRules: NamingRules{
@@ -49,6 +51,106 @@ func TestParser_Comprehensive(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Helper Packages",
+			directives: []string{
+				`//go:abgen:package:path=github.com/acme/convx,alias=convx`,
+				`//go:abgen:helpers:package="convx,github.com/acme/money"`,
+				`//go:abgen:helpers:package="github.com/acme/convx"`,
+			},
+			currentPkgPath: mockCurrentPkgPath,
+			expectedConfig: &Config{
+				PackageAliases: map[string]string{
+					"convx": "github.com/acme/convx",
+				},
+				HelperPackages: []string{"github.com/acme/convx", "github.com/acme/money"},
+			},
+		},
+		{
+			name: "Field Options",
+			directives: []string{
+				`//go:abgen:package:path=path/to/ent,alias=ent`,
+				`//go:abgen:convert:time:layout="RFC3339Nano"`,
+				`//go:abgen:convert:time:layout="ent.User#Birthday,Deadline=2006-01-02"`,
+				`//go:abgen:convert:time:unix="ent.User#LastSeen=ms"`,
+				`//go:abgen:convert:time:unix="minutes"`,
+				`//go:abgen:convert:time:zone="UTC"`,
+				`//go:abgen:convert:time:zone="ent.User#Birthday=Asia/Shanghai"`,
+				`//go:abgen:convert:time:zone="Mars/Olympus"`,
+				`//go:abgen:convert:json="ent.User#Settings, Tags"`,
+			},
+			currentPkgPath: mockCurrentPkgPath,
+			expectedConfig: &Config{
+				PackageAliases: map[string]string{
+					"ent": "path/to/ent",
+				},
+				FieldTimeOptions: map[string]TimeOptions{
+					"path/to/ent.User#Birthday": {Layout: "2006-01-02", Zone: "Asia/Shanghai"},
+					"path/to/ent.User#Deadline": {Layout: "2006-01-02"},
+					"path/to/ent.User#LastSeen": {Unix: "ms"},
+				},
+				JSONFields: map[string]bool{
+					"path/to/ent.User#Settings": true,
+					"path/to/ent.User#Tags":     true,
+				},
+				GlobalBehaviorRules: BehaviorRules{
+					Time: TimeOptions{Layout: "RFC3339Nano", Zone: "UTC"},
+				},
+			},
+		},
+		{
+			name: "Map and Copy Options",
+			directives: []string{
+				`//go:abgen:package:path=path/to/ent,alias=ent`,
+				`//go:abgen:convert:map="ent.User, ent.Group,ent.User"`,
+				`//go:abgen:convert:map="AuditEntry"`,
+				`//go:abgen:convert:map:key="json"`,
+				`//go:abgen:convert:map:key="yaml"`,
+				`//go:abgen:deepcopy="ent.User,AuditEntry"`,
+				`//go:abgen:convert:copy="deep"`,
+				`//go:abgen:compare:generate="true"`,
+				`//go:abgen:test:generate="true"`,
+			},
+			currentPkgPath: mockCurrentPkgPath,
+			expectedConfig: &Config{
+				PackageAliases: map[string]string{
+					"ent": "path/to/ent",
+				},
+				MapTypes:      []string{"path/to/ent.User", "path/to/ent.Group", mockCurrentPkgPath + ".AuditEntry"},
+				DeepCopyTypes: []string{"path/to/ent.User", mockCurrentPkgPath + ".AuditEntry"},
+				GlobalBehaviorRules: BehaviorRules{
+					MapKey:          MapKeyJSON,
+					CopyMode:        CopyDeep,
+					GenerateCompare: true,
+					GenerateTests:   true,
+				},
+			},
+		},
+		{
+			name: "Conversion Methods And Naming",
+			directives: []string{
+				`//go:abgen:convert:methods="true"`,
+				`//go:abgen:convert:method:to="ToPB"`,
+				`//go:abgen:convert:method:from="FromPB"`,
+				`//go:abgen:convert:func:name="{{if eq .Direction "reverse"}}{{.Target}}From{{else}}{{.Source}}To{{end}}Proto"`,
+				`//go:abgen:naming:acronyms="ID, URL,PB"`,
+				`//go:abgen:naming:plurals="Criterion:Criteria,Invalid"`,
+			},
+			currentPkgPath: mockCurrentPkgPath,
+			expectedConfig: &Config{
+				PackageAliases: map[string]string{},
+				NamingRules: NamingRules{
+					ToMethod:   "ToPB",
+					FromMethod: "FromPB",
+					FuncName:   `{{if eq .Direction "reverse"}}{{.Target}}From{{else}}{{.Source}}To{{end}}Proto`,
+					Acronyms:   []string{"ID", "URL", "PB"},
+					Plurals:    map[string]string{"Criterion": "Criteria"},
+				},
+				GlobalBehaviorRules: BehaviorRules{
+					GenerateMethods: true,
+				},
+			},
+		},
 		{
 			name: "Custom Func Rule Before Main Convert Rule (No PackagePath)",
 			directives: []string{
@@ -67,6 +169,7 @@ func TestParser_Comprehensive(t *testing.T) {
 						TargetType: "Role",
 						Direction:  DirectionBoth,
 						CustomFunc: "ConvertRoleFunc",
+						Origin:     OriginDirective,
 						FieldRules: FieldRuleSet{
 							Ignore: make(map[string]struct{}),
 							Remap:  make(map[string]string),
@@ -93,6 +196,7 @@ func TestParser_Comprehensive(t *testing.T) {
 						TargetType: "github.com/my/project/current.Role",
 						Direction:  DirectionBoth,
 						CustomFunc: "ConvertRoleFunc",
+						Origin:     OriginDirective,
 						FieldRules: FieldRuleSet{
 							Ignore: make(map[string]struct{}),
 							Remap:  make(map[string]string),
@@ -140,6 +244,51 @@ func TestParser_Comprehensive(t *testing.T) {
 				t.Errorf("NamingRules mismatch:\ngot:  %+v\nwant: %+v", cfg.NamingRules, tc.expectedConfig.NamingRules)
 			}
 
+			// Compare HelperPackages
+			if !reflect.DeepEqual(cfg.HelperPackages, tc.expectedConfig.HelperPackages) {
+				t.Errorf("HelperPackages mismatch:\ngot:  %v\nwant: %v", cfg.HelperPackages, tc.expectedConfig.HelperPackages)
+			}
+
+			// Compare time options
+			if tc.expectedConfig.FieldTimeOptions == nil {
+				tc.expectedConfig.FieldTimeOptions = map[string]TimeOptions{}
+			}
+			if !reflect.DeepEqual(cfg.FieldTimeOptions, tc.expectedConfig.FieldTimeOptions) {
+				t.Errorf("FieldTimeOptions mismatch:\ngot:  %v\nwant: %v", cfg.FieldTimeOptions, tc.expectedConfig.FieldTimeOptions)
+			}
+			if cfg.GlobalBehaviorRules.Time != tc.expectedConfig.GlobalBehaviorRules.Time {
+				t.Errorf("Time options mismatch:\ngot:  %+v\nwant: %+v", cfg.GlobalBehaviorRules.Time, tc.expectedConfig.GlobalBehaviorRules.Time)
+			}
+
+			if tc.expectedConfig.JSONFields == nil {
+				tc.expectedConfig.JSONFields = map[string]bool{}
+			}
+			if !reflect.DeepEqual(cfg.JSONFields, tc.expectedConfig.JSONFields) {
+				t.Errorf("JSONFields mismatch:\ngot:  %v\nwant: %v", cfg.JSONFields, tc.expectedConfig.JSONFields)
+			}
+
+			if !reflect.DeepEqual(cfg.MapTypes, tc.expectedConfig.MapTypes) {
+				t.Errorf("MapTypes mismatch:\ngot:  %v\nwant: %v", cfg.MapTypes, tc.expectedConfig.MapTypes)
+			}
+			if want := tc.expectedConfig.GlobalBehaviorRules.MapKey; want != "" && cfg.GlobalBehaviorRules.MapKey != want {
+				t.Errorf("MapKey mismatch:\ngot:  %v\nwant: %v", cfg.GlobalBehaviorRules.MapKey, want)
+			}
+			if !reflect.DeepEqual(cfg.DeepCopyTypes, tc.expectedConfig.DeepCopyTypes) {
+				t.Errorf("DeepCopyTypes mismatch:\ngot:  %v\nwant: %v", cfg.DeepCopyTypes, tc.expectedConfig.DeepCopyTypes)
+			}
+			if want := tc.expectedConfig.GlobalBehaviorRules.CopyMode; want != "" && cfg.GlobalBehaviorRules.CopyMode != want {
+				t.Errorf("CopyMode mismatch:\ngot:  %v\nwant: %v", cfg.GlobalBehaviorRules.CopyMode, want)
+			}
+			if cfg.GlobalBehaviorRules.GenerateTests != tc.expectedConfig.GlobalBehaviorRules.GenerateTests {
+				t.Errorf("GenerateTests mismatch:\ngot:  %v\nwant: %v", cfg.GlobalBehaviorRules.GenerateTests, tc.expectedConfig.GlobalBehaviorRules.GenerateTests)
+			}
+			if cfg.GlobalBehaviorRules.GenerateMethods != tc.expectedConfig.GlobalBehaviorRules.GenerateMethods {
+				t.Errorf("GenerateMethods mismatch:\ngot:  %v\nwant: %v", cfg.GlobalBehaviorRules.GenerateMethods, tc.expectedConfig.GlobalBehaviorRules.GenerateMethods)
+			}
+			if cfg.GlobalBehaviorRules.GenerateCompare != tc.expectedConfig.GlobalBehaviorRules.GenerateCompare {
+				t.Errorf("GenerateCompare mismatch:\ngot:  %v\nwant: %v", cfg.GlobalBehaviorRules.GenerateCompare, tc.expectedConfig.GlobalBehaviorRules.GenerateCompare)
+			}
+
 			// Compare ConversionRules
 			if len(cfg.ConversionRules) != len(tc.expectedConfig.ConversionRules) {
 				t.Fatalf("Expected %d conversion rules, got %d", len(tc.expectedConfig.ConversionRules), len(cfg.ConversionRules))
@@ -159,3 +308,71 @@ func TestParser_Comprehensive(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_InvalidFuncNameTemplate(t *testing.T) {
+	p := NewParser()
+	_, err := p.ParseDirectives([]string{`//go:abgen:convert:func:name="{{.Source"`}, "directives", "path/to/directives")
+	if err == nil || !strings.Contains(err.Error(), "invalid convert:func:name template") {
+		t.Errorf("ParseDirectives() error = %v, want an invalid template error", err)
+	}
+}
+
+func TestParser_Overrides(t *testing.T) {
+	p := NewParser()
+	p.SetOverrides([]string{
+		`convert:direction=oneway`,
+		`//go:abgen:convert:target:suffix="PB"`,
+		`convert="source=ent.User,target=pb.User,ignore=Password"`,
+	})
+	cfg, err := p.ParseDirectives([]string{
+		`//go:abgen:package:path=path/to/ent,alias=ent`,
+		`//go:abgen:package:path=path/to/pb,alias=pb`,
+		`//go:abgen:convert:target:suffix="DTO"`,
+		`//go:abgen:convert="source=ent.User,target=pb.User,remap=ID:Id"`,
+		`//go:abgen:convert="source=ent.Group,target=pb.Group"`,
+		`//go:abgen:convert="source=ent.Role,target=pb.Role,direction=both"`,
+	}, mockCurrentPkgName, mockCurrentPkgPath)
+	if err != nil {
+		t.Fatalf("ParseDirectives() error = %v", err)
+	}
+
+	if cfg.NamingRules.TargetSuffix != "PB" || cfg.GlobalBehaviorRules.DefaultDirection != DirectionOneway {
+		t.Errorf("overrides did not override the directives: %+v %+v", cfg.NamingRules, cfg.GlobalBehaviorRules)
+	}
+	wantOverrides := []string{
+		`convert:direction=oneway`,
+		`convert:target:suffix="PB"`,
+		`convert="source=ent.User,target=pb.User,ignore=Password"`,
+	}
+	if !reflect.DeepEqual(cfg.Overrides, wantOverrides) {
+		t.Errorf("Overrides = %q, want %q", cfg.Overrides, wantOverrides)
+	}
+
+	// The override replaces the rule of the same types, and its direction applies to the rules
+	// without one.
+	want := map[string]struct {
+		direction ConversionDirection
+		origin    RuleOrigin
+	}{
+		"path/to/ent.User":  {DirectionOneway, OriginCLI},
+		"path/to/ent.Group": {DirectionOneway, OriginDirective},
+		"path/to/ent.Role":  {DirectionBoth, OriginDirective},
+	}
+	if len(cfg.ConversionRules) != len(want) {
+		t.Fatalf("ConversionRules = %+v, want %d rules", cfg.ConversionRules, len(want))
+	}
+	for _, rule := range cfg.ConversionRules {
+		if w := want[rule.SourceType]; rule.Direction != w.direction || rule.Origin != w.origin {
+			t.Errorf("rule %s = %s %s, want %s %s", rule.SourceType, rule.Direction, rule.Origin, w.direction, w.origin)
+		}
+	}
+	if user := cfg.ConversionRules[0]; len(user.FieldRules.Remap) != 0 || len(user.FieldRules.Ignore) != 1 {
+		t.Errorf("rule of User = %+v, want the field rules of the override only", user.FieldRules)
+	}
+
+	p = NewParser()
+	p.SetOverrides([]string{`convert:func:name="{{.Source"`})
+	if _, err := p.ParseDirectives(nil, mockCurrentPkgName, mockCurrentPkgPath); err == nil || !strings.Contains(err.Error(), "invalid override") {
+		t.Errorf("ParseDirectives() error = %v, want an invalid override error", err)
+	}
+}
@@ -8,8 +8,16 @@ type Config struct {
 	PackagePairs        []*PackagePair
 	ConversionRules     []*ConversionRule
 	CustomFunctionRules map[string]string
+	HelperPackages      []string
+	FieldTimeOptions    map[string]TimeOptions
+	JSONFields          map[string]bool
+	MapTypes            []string
+	DeepCopyTypes       []string
 	NamingRules         NamingRules
 	GlobalBehaviorRules BehaviorRules
+	// Overrides are the directives given on the command line, parsed after the directives
+	// of the package so that they take precedence over them.
+	Overrides []string
 }
 
 // GenerationContext holds information about the package where code is being generated.
@@ -19,6 +27,7 @@ type GenerationContext struct {
 	DirectivePath    string
 	MainOutputFile   string
 	CustomOutputFile string
+	TestOutputFile   string
 }
 
 // PackagePair represents a pairing between a source and a target package.
@@ -34,20 +43,46 @@ type ConversionRule struct {
 	Direction  ConversionDirection
 	FieldRules FieldRuleSet
 	CustomFunc string
+	// Origin tells what created the rule: a directive, a package pair, a converter
+	// interface or the planner.
+	Origin RuleOrigin
 }
 
 // NamingRules defines naming conventions for generated types and functions.
+// ToMethod and FromMethod name the conversion methods generated for the struct types of the
+// directive package; they default to To<Target> and From<Source>. FuncName is the
+// text/template naming conversion functions; it defaults to Convert<Source>To<Target>.
+// Acronyms, when set, replaces the default words written in upper case in generated names,
+// and Plurals maps singular words to the plurals used instead of the built-in English rules.
 type NamingRules struct {
 	SourcePrefix string
 	SourceSuffix string
 	TargetPrefix string
 	TargetSuffix string
+	ToMethod     string
+	FromMethod   string
+	FuncName     string
+	Acronyms     []string
+	Plurals      map[string]string
 }
 
 // BehaviorRules defines conversion behaviors.
 type BehaviorRules struct {
 	GenerateAlias    bool
 	DefaultDirection ConversionDirection
+	ErrorMode        ErrorMode
+	HelperMode       HelperMode
+	Time             TimeOptions
+	MapKey           MapKeyStrategy
+	CopyMode         CopyMode
+	// GenerateCompare adds Equal and Diff functions comparing the types of each rule.
+	GenerateCompare bool
+	// GenerateTests adds a test file with round-trip tests and fuzz targets for the
+	// rules converting in both directions.
+	GenerateTests bool
+	// GenerateMethods generates the conversions of struct types defined in the directive
+	// package as methods of those types instead of free functions.
+	GenerateMethods bool
 }
 
 // FieldRuleSet defines field-specific rules for a given type conversion.
@@ -56,6 +91,19 @@ type FieldRuleSet struct {
 	Remap  map[string]string
 }
 
+// Reverse returns the rules of the conversion in the opposite direction: remaps are
+// inverted, and fields are only ignored in the direction of the rule.
+func (fr FieldRuleSet) Reverse() FieldRuleSet {
+	reverse := FieldRuleSet{
+		Ignore: make(map[string]struct{}),
+		Remap:  make(map[string]string, len(fr.Remap)),
+	}
+	for from, to := range fr.Remap {
+		reverse.Remap[to] = from
+	}
+	return reverse
+}
+
 // ConversionDirection represents the direction of conversion.
 type ConversionDirection string
 
@@ -64,6 +112,104 @@ const (
 	DirectionOneway ConversionDirection = "oneway"
 )
 
+// RuleOrigin tells what created a conversion rule.
+type RuleOrigin string
+
+const (
+	// OriginDirective is a rule declared by a convert directive.
+	OriginDirective RuleOrigin = "directive"
+	// OriginCLI is a rule declared by a convert directive given on the command line.
+	OriginCLI RuleOrigin = "cli"
+	// OriginPackagePair is a rule between types of the same name of a package pair.
+	OriginPackagePair RuleOrigin = "package-pair"
+	// OriginConverter is a rule declared by a method of a converter interface.
+	OriginConverter RuleOrigin = "converter"
+	// OriginMap is a rule converting a type named by convert:map to and from a map.
+	OriginMap RuleOrigin = "map"
+	// OriginDeepCopy is a rule copying a type named by deepcopy.
+	OriginDeepCopy RuleOrigin = "deepcopy"
+	// OriginDependency is a rule added for the struct fields of another rule.
+	OriginDependency RuleOrigin = "dependency"
+)
+
+// ErrorMode controls how errors returned by fallible conversions are handled.
+type ErrorMode string
+
+const (
+	// ErrorModeIgnore discards conversion errors; generated functions keep single-value signatures.
+	ErrorModeIgnore ErrorMode = "ignore"
+	// ErrorModeReturn makes generated functions return an error alongside the converted value.
+	ErrorModeReturn ErrorMode = "return"
+)
+
+// HelperMode controls how built-in helper functions are made available to generated code.
+type HelperMode string
+
+const (
+	// HelperModeRuntime calls the helpers exported by the github.com/origadmin/abgen/runtime packages.
+	HelperModeRuntime HelperMode = "runtime"
+	// HelperModeInline copies the helper bodies into every generated file.
+	HelperModeInline HelperMode = "inline"
+)
+
+// CopyMode controls how values of identical source and target types are copied.
+type CopyMode string
+
+const (
+	// CopyShallow assigns identical values directly, sharing slices, maps and pointers.
+	CopyShallow CopyMode = "shallow"
+	// CopyDeep clones slices, maps, pointers and nested structs of identical types.
+	CopyDeep CopyMode = "deep"
+)
+
+// MapKeyStrategy controls how struct fields are named when converted to and from maps.
+type MapKeyStrategy string
+
+const (
+	// MapKeyField uses the Go field name as the map key.
+	MapKeyField MapKeyStrategy = "field"
+	// MapKeyJSON uses the name from the json struct tag, falling back to the field name.
+	MapKeyJSON MapKeyStrategy = "json"
+)
+
+// TimeOptions controls how time.Time values are converted to and from other representations.
+// Empty fields fall back to the defaults: RFC 3339 layouts, Unix seconds and the original zone.
+type TimeOptions struct {
+	// Layout is the name of a time package layout constant, such as RFC3339Nano or DateOnly,
+	// or a literal layout such as "2006-01-02".
+	Layout string
+	// Unix is the unit used for time.Time <-> int64 conversions: s, ms or ns.
+	Unix string
+	// Zone is UTC, Local or an IANA time zone name that times are normalized to.
+	Zone string
+}
+
+// Merge returns o with the non-empty fields of other applied on top.
+func (o TimeOptions) Merge(other TimeOptions) TimeOptions {
+	if other.Layout != "" {
+		o.Layout = other.Layout
+	}
+	if other.Unix != "" {
+		o.Unix = other.Unix
+	}
+	if other.Zone != "" {
+		o.Zone = other.Zone
+	}
+	return o
+}
+
+// TimeOptionsFor returns the global time options overlaid with the options declared for
+// each of the given fields, in order. Fields are identified as "<type FQN>#<field name>".
+func (c *Config) TimeOptionsFor(fields ...string) TimeOptions {
+	opts := c.GlobalBehaviorRules.Time
+	for _, field := range fields {
+		if fieldOpts, ok := c.FieldTimeOptions[field]; ok {
+			opts = opts.Merge(fieldOpts)
+		}
+	}
+	return opts
+}
+
 // NewConfig creates a new, empty configuration object.
 func NewConfig() *Config {
 	return &Config{
@@ -72,9 +218,15 @@ func NewConfig() *Config {
 		PackagePairs:        []*PackagePair{},
 		ConversionRules:     []*ConversionRule{},
 		CustomFunctionRules: make(map[string]string),
+		FieldTimeOptions:    make(map[string]TimeOptions),
+		JSONFields:          make(map[string]bool),
 		NamingRules:         NamingRules{},
 		GlobalBehaviorRules: BehaviorRules{
 			DefaultDirection: DirectionBoth,
+			ErrorMode:        ErrorModeIgnore,
+			HelperMode:       HelperModeRuntime,
+			MapKey:           MapKeyField,
+			CopyMode:         CopyShallow,
 		},
 	}
 }
@@ -92,8 +244,24 @@ func (c *Config) Clone() *Config {
 		PackagePairs:        make([]*PackagePair, len(c.PackagePairs)),
 		ConversionRules:     make([]*ConversionRule, 0, len(c.ConversionRules)),
 		CustomFunctionRules: make(map[string]string, len(c.CustomFunctionRules)),
+		HelperPackages:      append([]string(nil), c.HelperPackages...),
+		FieldTimeOptions:    make(map[string]TimeOptions, len(c.FieldTimeOptions)),
+		JSONFields:          make(map[string]bool, len(c.JSONFields)),
+		MapTypes:            append([]string(nil), c.MapTypes...),
+		DeepCopyTypes:       append([]string(nil), c.DeepCopyTypes...),
 		NamingRules:         c.NamingRules,
 		GlobalBehaviorRules: c.GlobalBehaviorRules,
+		Overrides:           append([]string(nil), c.Overrides...),
+	}
+
+	if c.NamingRules.Acronyms != nil {
+		clone.NamingRules.Acronyms = append([]string{}, c.NamingRules.Acronyms...)
+	}
+	if c.NamingRules.Plurals != nil {
+		clone.NamingRules.Plurals = make(map[string]string, len(c.NamingRules.Plurals))
+		for singular, plural := range c.NamingRules.Plurals {
+			clone.NamingRules.Plurals[singular] = plural
+		}
 	}
 
 	for i, pair := range c.PackagePairs {
@@ -110,6 +278,7 @@ func (c *Config) Clone() *Config {
 				TargetType: rule.TargetType,
 				Direction:  rule.Direction,
 				CustomFunc: rule.CustomFunc,
+				Origin:     rule.Origin,
 				FieldRules: FieldRuleSet{
 					Ignore: make(map[string]struct{}, len(rule.FieldRules.Ignore)),
 					Remap:  make(map[string]string, len(rule.FieldRules.Remap)),
@@ -133,5 +302,13 @@ func (c *Config) Clone() *Config {
 		clone.CustomFunctionRules[k] = v
 	}
 
+	for k, v := range c.FieldTimeOptions {
+		clone.FieldTimeOptions[k] = v
+	}
+
+	for k, v := range c.JSONFields {
+		clone.JSONFields[k] = v
+	}
+
 	return clone
 }
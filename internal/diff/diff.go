@@ -0,0 +1,163 @@
+// Package diff computes the line differences between two texts and formats them as a
+// unified diff.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change.
+const contextLines = 3
+
+// opKind tells whether a line is kept, deleted from the old text or inserted from the new one.
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// op is a line of the edit script turning the old text into the new one.
+type op struct {
+	kind opKind
+	text string
+}
+
+// Unified returns the unified diff turning old into new, with name in its file headers, or
+// an empty string when the texts are equal.
+func Unified(name string, old, new []byte) string {
+	if string(old) == string(new) {
+		return ""
+	}
+	ops := editScript(splitLines(string(old)), splitLines(string(new)))
+
+	// oldLine and newLine hold the number of old and new lines before each operation.
+	oldLine := make([]int, len(ops)+1)
+	newLine := make([]int, len(ops)+1)
+	for i, o := range ops {
+		oldLine[i+1], newLine[i+1] = oldLine[i], newLine[i]
+		if o.kind != opInsert {
+			oldLine[i+1]++
+		}
+		if o.kind != opDelete {
+			newLine[i+1]++
+		}
+	}
+
+	var buf strings.Builder
+	buf.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", name, name))
+	for i := 0; i < len(ops); {
+		if ops[i].kind == opEqual {
+			i++
+			continue
+		}
+		// A hunk runs from the context before a change to the context after the last change
+		// closer to it than twice the context.
+		start := max(i-contextLines, 0)
+		end := i
+		for j := i; j < len(ops) && j <= end+2*contextLines; j++ {
+			if ops[j].kind != opEqual {
+				end = j
+			}
+		}
+		end = min(end+contextLines+1, len(ops))
+
+		buf.WriteString(fmt.Sprintf("@@ -%s +%s @@\n",
+			hunkRange(oldLine[start], oldLine[end]-oldLine[start]),
+			hunkRange(newLine[start], newLine[end]-newLine[start])))
+		for _, o := range ops[start:end] {
+			buf.WriteString([]string{" ", "-", "+"}[o.kind])
+			buf.WriteString(o.text)
+			if !strings.HasSuffix(o.text, "\n") {
+				buf.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+	return buf.String()
+}
+
+// hunkRange formats the range of a hunk header from the number of lines before it and its
+// number of lines.
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", before+1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+// splitLines splits text into lines, each keeping its line feed.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// editScript returns the shortest edit script turning a into b, computed with the Myers
+// algorithm.
+func editScript(a, b []string) []op {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk the trace back from the end of both texts, collecting the operations in reverse.
+	var ops []op
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			ops = append(ops, op{kind: opEqual, text: a[x-1]})
+			x, y = x-1, y-1
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			ops = append(ops, op{kind: opInsert, text: b[y-1]})
+			y--
+		} else {
+			ops = append(ops, op{kind: opDelete, text: a[x-1]})
+			x--
+		}
+	}
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
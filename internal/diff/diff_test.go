@@ -0,0 +1,56 @@
+package diff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	testCases := []struct {
+		name     string
+		old      string
+		new      string
+		expected string
+	}{
+		{
+			name: "Equal",
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+		},
+		{
+			name:     "Changed Line",
+			old:      "package p\n\n// versions: v0.0.1\n\nfunc A() {}\n",
+			new:      "package p\n\n// versions: v0.0.2\n\nfunc A() {}\n",
+			expected: "--- f.go\n+++ f.go\n@@ -1,5 +1,5 @@\n package p\n \n-// versions: v0.0.1\n+// versions: v0.0.2\n \n func A() {}\n",
+		},
+		{
+			name:     "Separate Hunks",
+			old:      "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			new:      "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n",
+			expected: "--- f.go\n+++ f.go\n@@ -1,3 +1,4 @@\n+0\n 1\n 2\n 3\n@@ -9,4 +10,3 @@\n 9\n 10\n 11\n-12\n",
+		},
+		{
+			name:     "Joined Hunks",
+			old:      "1\n2\n3\n4\n5\n6\n7\n",
+			new:      "1\nx\n3\n4\n5\n6\ny\n",
+			expected: "--- f.go\n+++ f.go\n@@ -1,7 +1,7 @@\n 1\n-2\n+x\n 3\n 4\n 5\n 6\n-7\n+y\n",
+		},
+		{
+			name:     "New File",
+			old:      "",
+			new:      "a\nb\n",
+			expected: "--- f.go\n+++ f.go\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:     "No Newline at End",
+			old:      "a\nb",
+			new:      "a\nb\n",
+			expected: "--- f.go\n+++ f.go\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("f.go", []byte(tt.old), []byte(tt.new)); got != tt.expected {
+				t.Errorf("Unified() =\n%s\nwant:\n%s", got, tt.expected)
+			}
+		})
+	}
+}
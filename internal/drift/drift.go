@@ -0,0 +1,216 @@
+// Package drift reports how far the struct types of two packages converted by abgen have
+// drifted apart: the types and fields found on one side only, and the fields whose types
+// differ, along with the conversion abgen chooses for them.
+package drift
+
+import (
+	"encoding/json"
+	"go/token"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/origadmin/abgen/internal/config"
+	"github.com/origadmin/abgen/internal/model"
+)
+
+// Kind is the kind of a difference between the source and target packages.
+type Kind string
+
+const (
+	// KindSourceType is a struct type of the source package without a type of the same name
+	// in the target package.
+	KindSourceType Kind = "source-type"
+	// KindTargetType is a struct type of the target package without a type of the same name
+	// in the source package.
+	KindTargetType Kind = "target-type"
+	// KindSourceField is a field of a source type matching no field of the target type.
+	KindSourceField Kind = "source-field"
+	// KindTargetField is a field of a target type matching no field of the source type.
+	KindTargetField Kind = "target-field"
+	// KindFieldType is a field whose type differs between the source and target types, once
+	// the types of the source package are replaced by those of the target package.
+	KindFieldType Kind = "field-type"
+)
+
+// Item is a difference between the source and target packages. Field is the selector of the
+// field relative to its struct, such as "Edges.Owner" for the fields of the Edges struct of
+// ent entities. SourceType and TargetType are the types of the fields of a field-type item,
+// and Expression and Strategy tell how the generated conversion sets the target field.
+type Item struct {
+	Kind       Kind                `json:"kind"`
+	Type       string              `json:"type"`
+	Field      string              `json:"field,omitempty"`
+	SourceType string              `json:"sourceType,omitempty"`
+	TargetType string              `json:"targetType,omitempty"`
+	Expression string              `json:"expression,omitempty"`
+	Strategy   model.FieldStrategy `json:"strategy,omitempty"`
+}
+
+// key identifies an item across reports. The conversion chosen for a field is left out, so
+// that an item of the baseline still matches once abgen converts the field differently.
+func (i *Item) key() string {
+	return strings.Join([]string{string(i.Kind), i.Type, i.Field, i.SourceType, i.TargetType}, "\x00")
+}
+
+// Report is the drift from the source package to the target package.
+type Report struct {
+	Source string  `json:"source"`
+	Target string  `json:"target"`
+	Items  []*Item `json:"items"`
+}
+
+// Build reports the drift from the struct types of the package at sourcePath to those of the
+// package at targetPath, as resolved in typeInfos. Types are paired by name, as the package
+// pair of the two packages pairs them, and their exported fields as the generated conversions
+// pair them. The expressions and strategies of the fields of different types are taken from
+// conversions, the struct conversions generated for the pair.
+func Build(sourcePath, targetPath string, typeInfos map[string]*model.TypeInfo, conversions []*model.ConversionReport) *Report {
+	sourceTypes := packageTypes(typeInfos, sourcePath)
+	targetTypes := packageTypes(typeInfos, targetPath)
+	names := make([]string, 0, len(sourceTypes)+len(targetTypes))
+	for name := range sourceTypes {
+		names = append(names, name)
+	}
+	for name := range targetTypes {
+		if _, ok := sourceTypes[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	mappings := make(map[string]*model.FieldMapping)
+	for _, conversion := range conversions {
+		for _, field := range conversion.Fields {
+			mappings[conversion.Source+"->"+conversion.Target+"."+field.Target] = field
+		}
+	}
+
+	report := &Report{Source: sourcePath, Target: targetPath, Items: []*Item{}}
+	for _, name := range names {
+		source, target := sourceTypes[name], targetTypes[name]
+		switch {
+		case target == nil:
+			report.Items = append(report.Items, &Item{Kind: KindSourceType, Type: name})
+		case source == nil:
+			report.Items = append(report.Items, &Item{Kind: KindTargetType, Type: name})
+		default:
+			report.Items = append(report.Items, fieldItems(sourcePath, targetPath, source, target, mappings)...)
+		}
+	}
+	return report
+}
+
+// fieldItems reports the drift between the fields of the source and target types of the same
+// name: the source fields, then the target fields, on one side only, and the fields of
+// different types.
+func fieldItems(
+	sourcePath, targetPath string, source, target *model.TypeInfo, mappings map[string]*model.FieldMapping,
+) []*Item {
+	var items []*Item
+	rules := config.FieldRuleSet{}
+	pairs := model.PairFields(source, target, rules)
+
+	paired := make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		paired[pair.SourcePath] = true
+	}
+	for _, field := range structFields(source) {
+		// The Edges struct of ent entities is a container: its fields are matched instead.
+		if field.Name == "Edges" && !paired[field.Name] && structFields(field.Type) != nil {
+			for _, edge := range structFields(field.Type) {
+				if path := "Edges." + edge.Name; token.IsExported(edge.Name) && !paired[path] {
+					items = append(items, &Item{Kind: KindSourceField, Type: source.Name, Field: path})
+				}
+			}
+			continue
+		}
+		if token.IsExported(field.Name) && !paired[field.Name] {
+			items = append(items, &Item{Kind: KindSourceField, Type: source.Name, Field: field.Name})
+		}
+	}
+
+	_, unmapped := model.UnpairedFields(source, target, rules)
+	for _, field := range unmapped {
+		if token.IsExported(field.Name) {
+			items = append(items, &Item{Kind: KindTargetField, Type: target.Name, Field: field.Name})
+		}
+	}
+
+	for _, pair := range pairs {
+		if !token.IsExported(pair.Target.Name) || !token.IsExported(pair.Source.Name) {
+			continue
+		}
+		sourceKey := strings.ReplaceAll(pair.Source.Type.UniqueKey(), sourcePath+".", targetPath+".")
+		if sourceKey == pair.Target.Type.UniqueKey() {
+			continue
+		}
+		item := &Item{
+			Kind:       KindFieldType,
+			Type:       target.Name,
+			Field:      pair.Target.Name,
+			SourceType: pair.Source.Type.TypeString(),
+			TargetType: pair.Target.Type.TypeString(),
+		}
+		if mapping := mappings[source.UniqueKey()+"->"+target.UniqueKey()+"."+pair.Target.Name]; mapping != nil {
+			item.Expression = mapping.Expression
+			item.Strategy = mapping.Strategy
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// packageTypes returns the exported struct types of the package at path by name.
+func packageTypes(typeInfos map[string]*model.TypeInfo, path string) map[string]*model.TypeInfo {
+	infos := make(map[string]*model.TypeInfo)
+	for _, info := range typeInfos {
+		if info.IsNamedType() && info.ImportPath == path && token.IsExported(info.Name) && structFields(info) != nil {
+			infos[info.Name] = info
+		}
+	}
+	return infos
+}
+
+// structFields returns the fields of the struct behind named types and pointers, or nil when
+// there is no struct. The fields of a struct without any are empty but not nil.
+func structFields(info *model.TypeInfo) []*model.FieldInfo {
+	for info != nil && info.Kind != model.Struct {
+		if info.Kind != model.Pointer && info.Kind != model.Named {
+			return nil
+		}
+		info = info.Underlying
+	}
+	if info == nil {
+		return nil
+	}
+	if info.Fields == nil {
+		return []*model.FieldInfo{}
+	}
+	return info.Fields
+}
+
+// Without returns the report of the items not found in baseline, the report of an earlier
+// drift accepted as is.
+func (r *Report) Without(baseline *Report) *Report {
+	known := make(map[string]bool, len(baseline.Items))
+	for _, item := range baseline.Items {
+		known[item.key()] = true
+	}
+	report := &Report{Source: r.Source, Target: r.Target, Items: []*Item{}}
+	for _, item := range r.Items {
+		if !known[item.key()] {
+			report.Items = append(report.Items, item)
+		}
+	}
+	return report
+}
+
+// Read reads a report written as JSON, such as a baseline.
+func Read(r io.Reader) (*Report, error) {
+	report := &Report{}
+	if err := json.NewDecoder(r).Decode(report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,151 @@
+package drift
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/origadmin/abgen/internal/model"
+)
+
+func testTypeInfos() map[string]*model.TypeInfo {
+	str := &model.TypeInfo{Kind: model.Primitive, Name: "string"}
+	i64 := &model.TypeInfo{Kind: model.Primitive, Name: "int64"}
+	field := func(name string, typ *model.TypeInfo) *model.FieldInfo {
+		return &model.FieldInfo{Name: name, Type: typ}
+	}
+
+	entRole := &model.TypeInfo{Kind: model.Struct, Name: "Role", ImportPath: "example.com/ent", Fields: []*model.FieldInfo{
+		field("Name", str),
+	}}
+	pbRole := &model.TypeInfo{Kind: model.Struct, Name: "Role", ImportPath: "example.com/pb", Fields: []*model.FieldInfo{
+		field("Name", str),
+	}}
+	entUser := &model.TypeInfo{Kind: model.Struct, Name: "User", ImportPath: "example.com/ent", Fields: []*model.FieldInfo{
+		field("ID", i64), field("Name", str), field("Age", i64), field("Password", str), field("secret", str),
+		field("Edges", &model.TypeInfo{Kind: model.Struct, Name: "UserEdges", ImportPath: "example.com/ent", Fields: []*model.FieldInfo{
+			field("Roles", &model.TypeInfo{Kind: model.Slice, Underlying: &model.TypeInfo{Kind: model.Pointer, Underlying: entRole}}),
+			field("Groups", str),
+		}}),
+	}}
+	pbUser := &model.TypeInfo{Kind: model.Struct, Name: "User", ImportPath: "example.com/pb", Fields: []*model.FieldInfo{
+		field("Id", i64), field("Name", str), field("Age", str), field("Email", str),
+		field("Roles", &model.TypeInfo{Kind: model.Slice, Underlying: &model.TypeInfo{Kind: model.Pointer, Underlying: pbRole}}),
+	}}
+	entGroup := &model.TypeInfo{Kind: model.Struct, Name: "Group", ImportPath: "example.com/ent"}
+	pbPage := &model.TypeInfo{Kind: model.Struct, Name: "Page", ImportPath: "example.com/pb"}
+
+	infos := make(map[string]*model.TypeInfo)
+	for _, info := range []*model.TypeInfo{entRole, pbRole, entUser, pbUser, entGroup, pbPage} {
+		infos[info.UniqueKey()] = info
+	}
+	return infos
+}
+
+func testConversions() []*model.ConversionReport {
+	return []*model.ConversionReport{{
+		Function: "ConvertUserToUserPB",
+		Source:   "example.com/ent.User",
+		Target:   "example.com/pb.User",
+		Fields: []*model.FieldMapping{
+			{Target: "Age", Source: "Age", Expression: "strconv.FormatInt(from.Age, 10)", Strategy: model.StrategyHelper},
+		},
+	}}
+}
+
+func TestBuild(t *testing.T) {
+	report := Build("example.com/ent", "example.com/pb", testTypeInfos(), testConversions())
+
+	want := []*Item{
+		{Kind: KindSourceType, Type: "Group"},
+		{Kind: KindTargetType, Type: "Page"},
+		{Kind: KindSourceField, Type: "User", Field: "Password"},
+		{Kind: KindSourceField, Type: "User", Field: "Edges.Groups"},
+		{Kind: KindTargetField, Type: "User", Field: "Email"},
+		{
+			Kind: KindFieldType, Type: "User", Field: "Age", SourceType: "int64", TargetType: "string",
+			Expression: "strconv.FormatInt(from.Age, 10)", Strategy: model.StrategyHelper,
+		},
+	}
+	if len(report.Items) != len(want) {
+		t.Fatalf("Build() has %d items, want %d: %+v", len(report.Items), len(want), report.Items)
+	}
+	for i, item := range report.Items {
+		if !reflect.DeepEqual(item, want[i]) {
+			t.Errorf("Build() item %d = %+v, want %+v", i, item, want[i])
+		}
+	}
+}
+
+func TestReport_Without(t *testing.T) {
+	report := Build("example.com/ent", "example.com/pb", testTypeInfos(), testConversions())
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	baseline, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if got := report.Without(baseline); len(got.Items) != 0 {
+		t.Errorf("Without() the same report = %+v, want no item", got.Items)
+	}
+
+	// A field converted differently is the same drift, a field of another type is not.
+	baseline.Items = baseline.Items[:len(baseline.Items)-1]
+	baseline.Items = append(baseline.Items, &Item{Kind: KindFieldType, Type: "User", Field: "Age", SourceType: "int64", TargetType: "int32"})
+	report.Items[len(report.Items)-1].Strategy = model.StrategyStub
+	got := report.Without(baseline)
+	if len(got.Items) != 1 || got.Items[0].Field != "Age" {
+		t.Errorf("Without() = %+v, want the Age field only", got.Items)
+	}
+}
+
+func TestReport_Write(t *testing.T) {
+	report := Build("example.com/ent", "example.com/pb", testTypeInfos(), testConversions())
+
+	tests := []struct {
+		name  string
+		write func(*Report, *bytes.Buffer) error
+		want  []string
+	}{
+		{
+			name:  "text",
+			write: func(r *Report, buf *bytes.Buffer) error { return r.WriteText(buf) },
+			want: []string{
+				"Drift from example.com/ent to example.com/pb\n",
+				"  source-field  User.Edges.Groups\n",
+				"  field-type    User.Age  int64 -> string  strconv.FormatInt(from.Age, 10) (helper)\n",
+			},
+		},
+		{
+			name:  "markdown",
+			write: func(r *Report, buf *bytes.Buffer) error { return r.WriteMarkdown(buf) },
+			want: []string{
+				"### Drift from `example.com/ent` to `example.com/pb`\n",
+				"| target-type | `Page` |  |  |  |\n",
+				"| field-type | `User.Age` | `int64` | `string` | `strconv.FormatInt(from.Age, 10)` (helper) |\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.write(report, &buf); err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output does not contain %q:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := report.Without(report).WriteText(&buf); err != nil || buf.String() != "No drift from example.com/ent to example.com/pb\n" {
+		t.Errorf("WriteText() without drift = %q, %v", buf.String(), err)
+	}
+}
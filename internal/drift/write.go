@@ -0,0 +1,91 @@
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// WriteText writes the report as aligned text, a line per item.
+func (r *Report) WriteText(w io.Writer) error {
+	if len(r.Items) == 0 {
+		_, err := fmt.Fprintf(w, "No drift from %s to %s\n", r.Source, r.Target)
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Drift from %s to %s\n", r.Source, r.Target); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, item := range r.Items {
+		line := "  " + string(item.Kind) + "\t" + item.selector()
+		if item.Kind == KindFieldType {
+			line += "\t" + item.SourceType + " -> " + item.TargetType + "\t" + item.conversion()
+		}
+		if _, err := fmt.Fprintln(tw, line); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// WriteJSON writes the report as indented JSON, which Read reads back as a baseline.
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteMarkdown writes the report as a Markdown table, for pull request comments.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	if len(r.Items) == 0 {
+		_, err := fmt.Fprintf(w, "No drift from `%s` to `%s`.\n", r.Source, r.Target)
+		return err
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### Drift from `%s` to `%s`\n\n", r.Source, r.Target)
+	sb.WriteString("| Kind | Type | Source type | Target type | Conversion |\n")
+	sb.WriteString("| :--- | :--- | :--- | :--- | :--- |\n")
+	for _, item := range r.Items {
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s |\n", item.Kind, markdownCode(item.selector()),
+			markdownCode(item.SourceType), markdownCode(item.TargetType), item.markdownConversion())
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// selector returns the type of the item, followed by its field if any.
+func (i *Item) selector() string {
+	if i.Field == "" {
+		return i.Type
+	}
+	return i.Type + "." + i.Field
+}
+
+// conversion describes the conversion of the field of a field-type item.
+func (i *Item) conversion() string {
+	switch {
+	case i.Strategy == "":
+		return ""
+	case i.Expression == "":
+		return string(i.Strategy)
+	}
+	return i.Expression + " (" + string(i.Strategy) + ")"
+}
+
+// markdownConversion describes the conversion of the field of a field-type item in Markdown.
+func (i *Item) markdownConversion() string {
+	if i.Expression == "" {
+		return string(i.Strategy)
+	}
+	return markdownCode(i.Expression) + " (" + string(i.Strategy) + ")"
+}
+
+// markdownCode formats text as Markdown code, escaping the pipes splitting table cells.
+func markdownCode(text string) string {
+	if text == "" {
+		return ""
+	}
+	return "`" + strings.ReplaceAll(text, "|", `\|`) + "`"
+}
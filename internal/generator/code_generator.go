@@ -27,6 +27,12 @@ type generationSession struct {
 	typeConverter    model.TypeConverter
 	conversionEngine model.ConversionEngine
 	codeEmitter      model.CodeEmitter
+
+	// stubOrigins maps each custom conversion stub to the converter interface method
+	// that needs it, or to an empty string when it is not needed by a converter method.
+	stubOrigins map[string]string
+	// conversions records the struct conversions generated, for explaining the plan.
+	conversions []*model.ConversionReport
 }
 
 // Generate is the single public entry point for the generator package.
@@ -51,8 +57,9 @@ func newGenerationSession(analysisResult *model.AnalysisResult) (*generationSess
 		importManager.AddAs(path, alias)
 	}
 
-	aliasManager := components.NewAliasManager(analysisResult, importManager)
-	nameGenerator := components.NewNameGenerator(aliasManager)
+	namingStrategy := components.NewNamingStrategy(analysisResult.ExecutionPlan.FinalConfig.NamingRules)
+	aliasManager := components.NewAliasManager(analysisResult, importManager, namingStrategy)
+	nameGenerator := components.NewNameGenerator(aliasManager, namingStrategy, analysisResult.ExecutionPlan)
 	typeFormatter := components.NewTypeFormatter(analysisResult, aliasManager, importManager)
 	codeEmitter := components.NewCodeEmitter(analysisResult)
 
@@ -75,6 +82,7 @@ func newGenerationSession(analysisResult *model.AnalysisResult) (*generationSess
 		typeFormatter:    typeFormatter,
 		conversionEngine: conversionEngine,
 		codeEmitter:      codeEmitter,
+		stubOrigins:      make(map[string]string),
 	}, nil
 }
 
@@ -94,6 +102,14 @@ func (s *generationSession) run() (*model.GenerationResponse, error) {
 		return nil, fmt.Errorf("failed to generate custom stubs: %w", err)
 	}
 
+	var testCode []byte
+	if s.cfg.GlobalBehaviorRules.GenerateTests {
+		testCode, err = s.generateRoundTripTests()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate round-trip tests: %w", err)
+		}
+	}
+
 	importMap := s.importManager.GetAllImports()
 	requiredPackages := make([]string, 0, len(importMap))
 	for pkgPath := range importMap {
@@ -105,7 +121,9 @@ func (s *generationSession) run() (*model.GenerationResponse, error) {
 	return &model.GenerationResponse{
 		GeneratedCode:    generatedCode,
 		CustomStubs:      customStubs,
+		TestCode:         testCode,
 		RequiredPackages: requiredPackages,
+		Conversions:      s.conversions,
 	}, nil
 }
 
@@ -117,6 +135,11 @@ func (s *generationSession) generateMainCode() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Every conversion function and stub is named by now; two of them sharing a name
+	// would be emitted as one.
+	if err := s.nameGenerator.Err(); err != nil {
+		return nil, err
+	}
 
 	aliasesToRender := s.prepareAliasesForRender()
 
@@ -134,16 +157,23 @@ func (s *generationSession) generateMainCode() ([]byte, error) {
 	}
 
 	var helpersToEmit []model.Helper
-	allHelpers := components.GetBuiltInHelpers()
+	allHelpers := append(components.GetBuiltInHelpers(), components.GetTimeHelpers()...)
+	allHelpers = append(allHelpers, components.GetDiffHelpers()...)
 	helperMap := make(map[string]model.Helper)
 	for _, h := range allHelpers {
 		helperMap[h.Name] = h
 	}
 
 	for helperName := range requiredHelpers {
-		if h, ok := helperMap[helperName]; ok {
-			helpersToEmit = append(helpersToEmit, h)
+		h, ok := helperMap[helperName]
+		if !ok {
+			continue
+		}
+		// Helpers provided by a runtime package are called from there, not copied.
+		if s.cfg.GlobalBehaviorRules.HelperMode != config.HelperModeInline && h.Package != "" {
+			continue
 		}
+		helpersToEmit = append(helpersToEmit, h)
 	}
 
 	if err := s.codeEmitter.EmitHelpers(finalBuf, helpersToEmit); err != nil {
@@ -201,6 +231,27 @@ func (s *generationSession) generateConversionCode() ([]string, map[string]struc
 	generatedFunctions := make(map[string]bool)
 	worklist := make([]*model.ConversionTask, 0)
 
+	// Converter implementations come first, so that the conversions their methods need
+	// are generated on their behalf.
+	for _, converter := range s.analysisResult.Converters {
+		generated, newTasks, err := s.conversionEngine.GenerateConverterImplementation(converter)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to implement %s: %w", converter.Name, err)
+		}
+		// A method whose types have no conversion calls a stub named as usual.
+		for _, method := range converter.Methods {
+			stubName := s.nameGenerator.ConversionFunctionName(method.Source, method.Target)
+			if _, isStub := s.conversionEngine.GetStubsToGenerate()[stubName]; isStub {
+				s.recordStubOrigin(stubName, method.QualifiedName())
+			}
+		}
+		conversionFuncs = append(conversionFuncs, generated.FunctionBody)
+		for _, helper := range generated.RequiredHelpers {
+			requiredHelpers[helper.Name] = struct{}{}
+		}
+		worklist = append(worklist, newTasks...)
+	}
+
 	for _, rule := range s.plan.ActiveRules {
 		sourceInfo := s.analysisResult.TypeInfos[rule.SourceType]
 		targetInfo := s.analysisResult.TypeInfos[rule.TargetType]
@@ -208,6 +259,10 @@ func (s *generationSession) generateConversionCode() ([]string, map[string]struc
 			continue
 		}
 
+		if s.cfg.GlobalBehaviorRules.GenerateCompare && isComparablePair(sourceInfo, targetInfo) {
+			worklist = append(worklist, &model.ConversionTask{Source: sourceInfo, Target: targetInfo, Rule: rule, Compare: true})
+		}
+
 		if rule.Direction == config.DirectionBoth {
 			forwardRule := *rule
 			forwardRule.Direction = config.DirectionOneway
@@ -217,10 +272,8 @@ func (s *generationSession) generateConversionCode() ([]string, map[string]struc
 				SourceType: targetInfo.FQN(),
 				TargetType: sourceInfo.FQN(),
 				Direction:  config.DirectionOneway,
-				FieldRules: config.FieldRuleSet{Ignore: make(map[string]struct{}), Remap: make(map[string]string)},
-			}
-			for from, to := range rule.FieldRules.Remap {
-				reverseRule.FieldRules.Remap[to] = from
+				FieldRules: rule.FieldRules.Reverse(),
+				Origin:     rule.Origin,
 			}
 			worklist = append(worklist, &model.ConversionTask{Source: targetInfo, Target: sourceInfo, Rule: reverseRule})
 		} else {
@@ -232,26 +285,43 @@ func (s *generationSession) generateConversionCode() ([]string, map[string]struc
 		task := worklist[0]
 		worklist = worklist[1:]
 
-		funcName := s.nameGenerator.ConversionFunctionName(task.Source, task.Target)
+		funcName := s.taskFunctionName(task)
 		if generatedFunctions[funcName] {
 			continue
 		}
+		if s.analysisResult.ExistingFunctions[funcName] {
+			slog.Debug("Skipping conversion function already defined in the directive package", "func", funcName)
+			continue
+		}
 
-		generated, newTasks, err := s.conversionEngine.GenerateConversionFunction(task.Source, task.Target, task.Rule)
+		var generated *model.GeneratedCode
+		var newTasks []*model.ConversionTask
+		var err error
+		if task.Compare {
+			generated, newTasks, err = s.conversionEngine.GenerateComparisonFunctions(task.Source, task.Target, task.Rule)
+		} else {
+			generated, newTasks, err = s.conversionEngine.GenerateConversionFunction(task.Source, task.Target, task.Rule)
+		}
 		if err != nil {
 			slog.Warn("Error generating conversion function", "source", task.Source.FQN(), "target", task.Target.FQN(), "error", err)
 			continue
 		}
+		s.recordStubOrigins(task.Origin)
 
 		if generated != nil {
 			conversionFuncs = append(conversionFuncs, generated.FunctionBody)
 			generatedFunctions[funcName] = true
+			if generated.Fields != nil {
+				s.recordConversion(funcName, task, generated.Fields)
+			}
 			for _, helper := range generated.RequiredHelpers {
 				requiredHelpers[helper.Name] = struct{}{}
 			}
 			for _, newTask := range newTasks {
-				newFuncName := s.nameGenerator.ConversionFunctionName(newTask.Source, newTask.Target)
-				if !generatedFunctions[newFuncName] {
+				if newTask.Origin == "" {
+					newTask.Origin = task.Origin
+				}
+				if !generatedFunctions[s.taskFunctionName(newTask)] {
 					worklist = append(worklist, newTask)
 				}
 			}
@@ -262,6 +332,55 @@ func (s *generationSession) generateConversionCode() ([]string, map[string]struc
 	return conversionFuncs, requiredHelpers, nil
 }
 
+// recordStubOrigins attributes the stubs created since the last call to origin, the converter
+// method the generated code was created for.
+func (s *generationSession) recordStubOrigins(origin string) {
+	for name := range s.conversionEngine.GetStubsToGenerate() {
+		s.recordStubOrigin(name, origin)
+	}
+}
+
+// recordStubOrigin attributes a stub to origin unless it is already attributed, and reports
+// the stubs a converter method needs.
+func (s *generationSession) recordStubOrigin(name, origin string) {
+	if _, seen := s.stubOrigins[name]; seen {
+		return
+	}
+	s.stubOrigins[name] = origin
+	if origin != "" {
+		slog.Warn("converter method needs a custom conversion function", "method", origin, "func", name)
+	}
+}
+
+// recordConversion records the struct conversion generated as funcName for task.
+func (s *generationSession) recordConversion(funcName string, task *model.ConversionTask, fields []*model.FieldMapping) {
+	report := &model.ConversionReport{
+		Function: funcName,
+		Source:   task.Source.UniqueKey(),
+		Target:   task.Target.UniqueKey(),
+		Fields:   fields,
+	}
+	if task.Rule != nil {
+		report.Origin = task.Rule.Origin
+	}
+	s.conversions = append(s.conversions, report)
+}
+
+// taskFunctionName returns the name of the function generated for task; comparison tasks
+// are identified by their Diff function.
+func (s *generationSession) taskFunctionName(task *model.ConversionTask) string {
+	if task.Compare {
+		return s.nameGenerator.DiffFunctionName(task.Source, task.Target)
+	}
+	return s.nameGenerator.ConversionFunctionName(task.Source, task.Target)
+}
+
+// isComparablePair reports whether Equal and Diff functions are generated for a rule
+// between source and target: both must be structs, and of different types.
+func isComparablePair(source, target *model.TypeInfo) bool {
+	return source.IsUltimatelyStruct() && target.IsUltimatelyStruct() && source.UniqueKey() != target.UniqueKey()
+}
+
 // generateCustomStubs produces a file with function stubs for custom conversions.
 func (s *generationSession) generateCustomStubs() ([]byte, error) {
 	stubs := s.conversionEngine.GetStubsToGenerate()
@@ -289,6 +408,9 @@ func (s *generationSession) generateCustomStubs() ([]byte, error) {
 
 		var sb strings.Builder
 		sb.WriteString(fmt.Sprintf("// %s is a custom conversion function stub.\n", name))
+		if origin := s.stubOrigins[name]; origin != "" {
+			sb.WriteString(fmt.Sprintf("// It is needed by %s.\n", origin))
+		}
 		sb.WriteString(fmt.Sprintf("// Please implement this function to complete the conversion.\n"))
 		sb.WriteString(fmt.Sprintf("func %s(from %s) %s {\n", name, sourceTypeStr, targetTypeStr))
 		sb.WriteString(fmt.Sprintf("\t// TODO: Implement this custom conversion\n"))
@@ -315,3 +437,36 @@ func (s *generationSession) generateCustomStubs() ([]byte, error) {
 		TabIndent: true,
 	})
 }
+
+// generateRoundTripTests produces a test file with round-trip tests and fuzz targets for the
+// rules converting in both directions.
+func (s *generationSession) generateRoundTripTests() ([]byte, error) {
+	// Like the stubs, the tests get their own imports.
+	testImportManager := components.NewImportManager()
+	testTypeFormatter := components.NewTypeFormatter(s.analysisResult, s.aliasManager, testImportManager)
+	testGenerator := components.NewRoundTripTestGenerator(s.analysisResult, s.nameGenerator, testTypeFormatter, testImportManager)
+
+	tests, err := testGenerator.GenerateRoundTripTests(s.plan.ActiveRules, s.analysisResult.TypeInfos)
+	if err != nil || len(tests) == 0 {
+		return nil, err
+	}
+	testImportManager.Add("testing")
+
+	var finalBuf bytes.Buffer
+	if err := s.codeEmitter.EmitHeader(&finalBuf); err != nil {
+		return nil, fmt.Errorf("failed to emit header for tests: %w", err)
+	}
+	if err := s.codeEmitter.EmitImports(&finalBuf, testImportManager.GetAllImports()); err != nil {
+		return nil, fmt.Errorf("failed to emit imports for tests: %w", err)
+	}
+	if err := s.codeEmitter.EmitConversions(&finalBuf, tests); err != nil {
+		return nil, fmt.Errorf("failed to emit tests: %w", err)
+	}
+
+	return imports.Process("", finalBuf.Bytes(), &imports.Options{
+		Fragment:  true,
+		Comments:  true,
+		TabWidth:  8,
+		TabIndent: true,
+	})
+}
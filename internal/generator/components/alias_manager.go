@@ -2,9 +2,7 @@ package components
 
 import (
 	"log/slog"
-	"regexp"
 	"strings"
-	"unicode"
 
 	"github.com/origadmin/abgen/internal/config"
 	"github.com/origadmin/abgen/internal/model"
@@ -24,6 +22,7 @@ var nonManagedPackages = map[string]struct{}{
 type AliasManager struct {
 	cfg                 *config.Config
 	importManager       model.ImportManager
+	naming              model.NamingStrategy
 	aliasMap            map[string]string
 	typeInfos           map[string]*model.TypeInfo
 	aliasedTypes        map[string]*model.TypeInfo
@@ -32,11 +31,10 @@ type AliasManager struct {
 	visited             map[string]bool
 }
 
-var camelCaseRegexp = regexp.MustCompile(`[^a-zA-Z0-9]+`)
-
 func NewAliasManager(
 	analysisResult *model.AnalysisResult,
 	importManager model.ImportManager,
+	naming model.NamingStrategy,
 ) model.AliasManager {
 	fqnToAlias := make(map[string]string, len(analysisResult.ExistingAliases))
 	for alias, fqn := range analysisResult.ExistingAliases {
@@ -46,6 +44,7 @@ func NewAliasManager(
 	return &AliasManager{
 		cfg:                 analysisResult.ExecutionPlan.FinalConfig,
 		importManager:       importManager,
+		naming:              naming,
 		aliasMap:            make(map[string]string),
 		typeInfos:           analysisResult.TypeInfos,
 		aliasedTypes:        make(map[string]*model.TypeInfo),
@@ -116,6 +115,10 @@ func (am *AliasManager) addManagedPackage(pkgPath string) {
 	if _, isExcluded := nonManagedPackages[pkgPath]; isExcluded {
 		return
 	}
+	// Types of the directive package are used by their own names.
+	if pkgPath == am.cfg.GenerationContext.PackagePath {
+		return
+	}
 	am.managedPackagePaths[pkgPath] = struct{}{}
 }
 
@@ -185,11 +188,7 @@ func (am *AliasManager) getRecursiveBaseName(info *model.TypeInfo) string {
 	case model.Pointer:
 		return am.getRecursiveBaseName(info.Underlying)
 	case model.Slice:
-		elementBaseName := am.getRecursiveBaseName(info.Underlying)
-		if strings.HasSuffix(elementBaseName, "s") {
-			return elementBaseName + "es"
-		}
-		return elementBaseName + "s"
+		return am.naming.Plural(am.getRecursiveBaseName(info.Underlying))
 	case model.Array:
 		return am.getRecursiveBaseName(info.Underlying) + "Array"
 	case model.Map:
@@ -197,9 +196,9 @@ func (am *AliasManager) getRecursiveBaseName(info *model.TypeInfo) string {
 		valueBaseName := am.getRecursiveBaseName(info.Underlying)
 		return keyBaseName + "To" + valueBaseName + "Map"
 	case model.Named, model.Struct:
-		return am.toCamelCase(info.Name)
+		return am.naming.CamelCase(info.Name)
 	case model.Primitive:
-		return am.toCamelCase(info.Name)
+		return am.naming.CamelCase(info.Name)
 	default:
 		return "Unknown"
 	}
@@ -208,7 +207,7 @@ func (am *AliasManager) getRecursiveBaseName(info *model.TypeInfo) string {
 func (am *AliasManager) generateAlias(info *model.TypeInfo, isSource bool) string {
 	baseName := am.getRecursiveBaseName(info)
 	prefix, suffix := am.getPrefixAndSuffix(isSource)
-	return am.toCamelCase(prefix) + baseName + am.toCamelCase(suffix)
+	return am.naming.CamelCase(prefix) + baseName + am.naming.CamelCase(suffix)
 }
 
 func (am *AliasManager) getPrefixAndSuffix(isSource bool) (string, string) {
@@ -218,22 +217,6 @@ func (am *AliasManager) getPrefixAndSuffix(isSource bool) (string, string) {
 	return am.cfg.NamingRules.TargetPrefix, am.cfg.NamingRules.TargetSuffix
 }
 
-func (am *AliasManager) toCamelCase(s string) string {
-	if s == "" {
-		return ""
-	}
-	s = camelCaseRegexp.ReplaceAllString(s, " ")
-	parts := strings.Fields(s)
-	for i := range parts {
-		if len(parts[i]) > 0 {
-			runes := []rune(parts[i])
-			runes[0] = unicode.ToUpper(runes[0])
-			parts[i] = string(runes)
-		}
-	}
-	return strings.Join(parts, "")
-}
-
 func (am *AliasManager) GetAllAliases() map[string]string { return am.aliasMap }
 func (am *AliasManager) GetSourcePath() string {
 	if len(am.cfg.PackagePairs) > 0 {
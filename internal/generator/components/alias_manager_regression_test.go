@@ -31,7 +31,7 @@ func TestAliasManager_PointerVsValue_NoCollision(t *testing.T) {
 	cfg.NamingRules.SourceSuffix = "Source"
 
 	im := &mockImportManager{aliases: make(map[string]string)}
-	am := NewAliasManager(analysisResult, im)
+	am := NewAliasManager(analysisResult, im, NewNamingStrategy(config.NamingRules{}))
 
 	am.PopulateAliases()
 
@@ -76,7 +76,7 @@ func TestAliasManager_ThirdPartyTypes_NotAliased(t *testing.T) {
 	cfg.NamingRules.SourceSuffix = "Source"
 
 	im := &mockImportManager{aliases: make(map[string]string)}
-	am := NewAliasManager(analysisResult, im)
+	am := NewAliasManager(analysisResult, im, NewNamingStrategy(config.NamingRules{}))
 	am.PopulateAliases()
 
 	// --- Assertions ---
@@ -125,7 +125,7 @@ func TestAliasManager_PluralizationWithSuffix(t *testing.T) {
 
 	// --- Test Execution ---
 	im := &mockImportManager{aliases: make(map[string]string)}
-	am := NewAliasManager(analysisResult, im)
+	am := NewAliasManager(analysisResult, im, NewNamingStrategy(config.NamingRules{}))
 	am.PopulateAliases() // Use the public API
 
 	// --- Assertions ---
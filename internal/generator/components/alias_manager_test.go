@@ -74,7 +74,7 @@ func TestAliasManager_PopulateAliases(t *testing.T) {
 	cfg.NamingRules.TargetSuffix = "DTO"
 
 	im := &mockImportManager{aliases: make(map[string]string)}
-	am := NewAliasManager(analysisResult, im)
+	am := NewAliasManager(analysisResult, im, NewNamingStrategy(config.NamingRules{}))
 	am.PopulateAliases()
 
 	expectedAliases := map[string]string{
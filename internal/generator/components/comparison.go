@@ -0,0 +1,219 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/origadmin/abgen/internal/config"
+	"github.com/origadmin/abgen/internal/model"
+)
+
+// GenerateComparisonFunctions generates the Equal and Diff functions of a pair of struct
+// types. Fields are paired as in the conversion from source to target: each source field
+// is converted to the type of its target field with the expression the converter uses and
+// compared to it, and nested struct pairs, along with the elements of slices and maps of
+// them, are compared by their own Diff functions.
+// Diff cannot return conversion errors, so they are discarded in every error mode.
+func (ce *ConversionEngine) GenerateComparisonFunctions(
+	sourceInfo, targetInfo *model.TypeInfo, rule *config.ConversionRule,
+) (*model.GeneratedCode, []*model.ConversionTask, error) {
+	if !sourceInfo.IsUltimatelyStruct() || !targetInfo.IsUltimatelyStruct() {
+		return nil, nil, fmt.Errorf("cannot compare %s and %s: both must be structs", sourceInfo.UniqueKey(), targetInfo.UniqueKey())
+	}
+
+	ce.discardErrors = true
+	defer func() { ce.discardErrors = false }()
+
+	fieldDiff := ce.diffHelpers["FieldDiff"]
+	diffType := ce.helperFuncName(fieldDiff)
+	requiredHelpers := []model.Helper{fieldDiff}
+	var newTasks []*model.ConversionTask
+	var preAssignments, checks []string
+
+	for _, pair := range model.PairFields(sourceInfo, targetInfo, fieldRulesOf(rule)) {
+		aExpr := "a." + pair.SourcePath
+		bExpr := "b." + pair.Target.Name
+		opts := ce.fieldOptionsFor(sourceInfo, pair.Source.Name, targetInfo, pair.Target.Name)
+
+		if ce.comparesNested(pair.Source.Type, pair.Target.Type, opts) {
+			nested := &model.ConversionTask{Source: derefType(pair.Source.Type), Target: derefType(pair.Target.Type), Compare: true}
+			newTasks = append(newTasks, nested)
+			nestDiffs := ce.diffHelpers["NestDiffs"]
+			requiredHelpers = append(requiredHelpers, nestDiffs)
+			checks = append(checks, fmt.Sprintf("\tdiffs = append(diffs, %s(%q, %q, %s(%s, %s))...)\n",
+				ce.helperFuncName(nestDiffs), pair.SourcePath, pair.Target.Name,
+				ce.nameGenerator.DiffFunctionName(nested.Source, nested.Target),
+				pointerTo(pair.Source.Type, aExpr), pointerTo(pair.Target.Type, bExpr)))
+			continue
+		}
+		if sourceElem, targetElem, ok := ce.comparesElements(pair.Source.Type, pair.Target.Type, opts); ok {
+			nested := &model.ConversionTask{Source: derefType(sourceElem), Target: derefType(targetElem), Compare: true}
+			newTasks = append(newTasks, nested)
+			nestDiffs := ce.diffHelpers["NestDiffs"]
+			requiredHelpers = append(requiredHelpers, nestDiffs)
+			checks = append(checks, ce.elementChecks(pair, sourceElem, targetElem, aExpr, bExpr, diffType,
+				ce.helperFuncName(nestDiffs), ce.nameGenerator.DiffFunctionName(nested.Source, nested.Target)))
+			continue
+		}
+
+		converted := aExpr
+		if pair.Source.Type.UniqueKey() != pair.Target.Type.UniqueKey() {
+			expr, helpers, tasks, preAssignmentsForField := ce.getConversionExpression(pair.Source.Type, pair.Target.Type, aExpr, opts)
+			converted = expr
+			requiredHelpers = append(requiredHelpers, helpers...)
+			newTasks = append(newTasks, tasks...)
+			preAssignments = append(preAssignments, preAssignmentsForField...)
+		}
+		checks = append(checks, fmt.Sprintf("\tif %s {\n\t\tdiffs = append(diffs, %s{Path: %q, TargetPath: %q, A: %s, B: %s})\n\t}\n",
+			ce.differs(pair.Target.Type, converted, bExpr), diffType, pair.SourcePath, pair.Target.Name, aExpr, bExpr))
+	}
+
+	var buf strings.Builder
+	sourceTypeStr := ce.typeFormatter.Format(sourceInfo)
+	targetTypeStr := ce.typeFormatter.Format(targetInfo)
+	diffFuncName := ce.nameGenerator.DiffFunctionName(sourceInfo, targetInfo)
+	equalFuncName := ce.nameGenerator.EqualFunctionName(sourceInfo, targetInfo)
+
+	buf.WriteString(fmt.Sprintf("// %s lists the fields whose values differ between the given %s and %s.\n", diffFuncName, sourceTypeStr, targetTypeStr))
+	buf.WriteString(fmt.Sprintf("func %s(a *%s, b *%s) []%s {\n", diffFuncName, sourceTypeStr, targetTypeStr, diffType))
+	buf.WriteString("\tif a == nil || b == nil {\n")
+	buf.WriteString("\t\tif a == nil && b == nil {\n\t\t\treturn nil\n\t\t}\n")
+	buf.WriteString(fmt.Sprintf("\t\treturn []%s{{A: a, B: b}}\n", diffType))
+	buf.WriteString("\t}\n\n")
+	for _, preAssignment := range preAssignments {
+		buf.WriteString(preAssignment + "\n")
+	}
+	if len(preAssignments) > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString(fmt.Sprintf("\tvar diffs []%s\n", diffType))
+	for _, check := range checks {
+		buf.WriteString(check)
+	}
+	buf.WriteString("\treturn diffs\n")
+	buf.WriteString("}\n\n")
+
+	buf.WriteString(fmt.Sprintf("// %s reports whether the given %s and %s hold the same values.\n", equalFuncName, sourceTypeStr, targetTypeStr))
+	buf.WriteString(fmt.Sprintf("func %s(a *%s, b *%s) bool {\n", equalFuncName, sourceTypeStr, targetTypeStr))
+	buf.WriteString(fmt.Sprintf("\treturn len(%s(a, b)) == 0\n", diffFuncName))
+	buf.WriteString("}\n\n")
+
+	return &model.GeneratedCode{
+		FunctionBody:    buf.String(),
+		RequiredHelpers: requiredHelpers,
+	}, newTasks, nil
+}
+
+// comparesNested reports whether a pair of fields is compared by the Diff function of their
+// struct types, which applies when the converter would convert them with a generated
+// struct conversion.
+func (ce *ConversionEngine) comparesNested(source, target *model.TypeInfo, opts fieldOptions) bool {
+	if opts.json {
+		return false
+	}
+	sourceStruct, targetStruct := derefType(source), derefType(target)
+	if !sourceStruct.IsUltimatelyStruct() || !targetStruct.IsUltimatelyStruct() ||
+		sourceStruct.Name == "" || targetStruct.Name == "" ||
+		sourceStruct.UniqueKey() == targetStruct.UniqueKey() ||
+		isStandardLibrary(sourceStruct.ImportPath) || isStandardLibrary(targetStruct.ImportPath) {
+		return false
+	}
+	if _, found := ce.findConversionFunc(source, target); found {
+		return false
+	}
+	_, found := ce.findHelper(source, target)
+	return !found
+}
+
+// comparesElements reports whether a pair of slice, array or map fields is compared element
+// by element with the Diff function of their element types, and returns the element types.
+// This applies when the elements would be compared by their Diff function as fields, and
+// maps have keys of the same type.
+func (ce *ConversionEngine) comparesElements(source, target *model.TypeInfo, opts fieldOptions) (*model.TypeInfo, *model.TypeInfo, bool) {
+	if _, found := ce.findConversionFunc(source, target); found {
+		return nil, nil, false
+	}
+	effectiveSource, effectiveTarget := getEffectiveTypeInfo(source), getEffectiveTypeInfo(target)
+	if effectiveSource == nil || effectiveTarget == nil {
+		return nil, nil, false
+	}
+	switch {
+	case (effectiveSource.Kind == model.Slice || effectiveSource.Kind == model.Array) &&
+		(effectiveTarget.Kind == model.Slice || effectiveTarget.Kind == model.Array):
+	case effectiveSource.Kind == model.Map && effectiveTarget.Kind == model.Map &&
+		effectiveSource.KeyType != nil && effectiveTarget.KeyType != nil &&
+		effectiveSource.KeyType.UniqueKey() == effectiveTarget.KeyType.UniqueKey():
+	default:
+		return nil, nil, false
+	}
+	sourceElem, targetElem := effectiveSource.Underlying, effectiveTarget.Underlying
+	if sourceElem == nil || targetElem == nil || !ce.comparesNested(sourceElem, targetElem, opts) {
+		return nil, nil, false
+	}
+	return sourceElem, targetElem, true
+}
+
+// elementChecks returns the checks comparing the elements of a pair of slice, array or map
+// fields with diffFunc, the Diff function of their element types, nesting the paths of
+// their diffs with the index or key of the elements. Fields of different lengths are
+// reported as a whole, and keys of a missing from b by themselves. The diffs of map
+// elements follow the iteration order of the map.
+func (ce *ConversionEngine) elementChecks(
+	pair *model.FieldPair, sourceElem, targetElem *model.TypeInfo, aExpr, bExpr, diffType, nestDiffs, diffFunc string,
+) string {
+	var buf strings.Builder
+	wholeDiff := fmt.Sprintf("%s{Path: %q, TargetPath: %q, A: %s, B: %s}", diffType, pair.SourcePath, pair.Target.Name, aExpr, bExpr)
+	buf.WriteString(fmt.Sprintf("\tif len(%s) != len(%s) {\n", aExpr, bExpr))
+	buf.WriteString(fmt.Sprintf("\t\tdiffs = append(diffs, %s)\n", wholeDiff))
+	buf.WriteString("\t} else {\n")
+	if getEffectiveTypeInfo(pair.Source.Type).Kind == model.Map {
+		key := ce.importManager.Add("fmt") + ".Sprint(k)"
+		aPath, bPath := elementPath(pair.SourcePath, key), elementPath(pair.Target.Name, key)
+		buf.WriteString(fmt.Sprintf("\t\tfor k, va := range %s {\n", aExpr))
+		buf.WriteString(fmt.Sprintf("\t\t\tvb, ok := %s[k]\n", bExpr))
+		buf.WriteString("\t\t\tif !ok {\n")
+		buf.WriteString(fmt.Sprintf("\t\t\t\tdiffs = append(diffs, %s{Path: %s, TargetPath: %s, A: va})\n", diffType, aPath, bPath))
+		buf.WriteString("\t\t\t\tcontinue\n\t\t\t}\n")
+		buf.WriteString(fmt.Sprintf("\t\t\tdiffs = append(diffs, %s(%s, %s, %s(%s, %s))...)\n",
+			nestDiffs, aPath, bPath, diffFunc, pointerTo(sourceElem, "va"), pointerTo(targetElem, "vb")))
+		buf.WriteString("\t\t}\n")
+	} else {
+		index := ce.importManager.Add("strconv") + ".Itoa(i)"
+		buf.WriteString(fmt.Sprintf("\t\tfor i := range %s {\n", aExpr))
+		buf.WriteString(fmt.Sprintf("\t\t\tdiffs = append(diffs, %s(%s, %s, %s(%s, %s))...)\n",
+			nestDiffs, elementPath(pair.SourcePath, index), elementPath(pair.Target.Name, index), diffFunc,
+			pointerTo(sourceElem, aExpr+"[i]"), pointerTo(targetElem, bExpr+"[i]")))
+		buf.WriteString("\t\t}\n")
+	}
+	buf.WriteString("\t}\n")
+	return buf.String()
+}
+
+// elementPath returns the expression of the path of the element of a field at the index or
+// key formatted by the given expression.
+func elementPath(path, index string) string {
+	return fmt.Sprintf("%q+%s+%q", path+"[", index, "]")
+}
+
+// differs returns the condition under which x and y, both of type info, hold different
+// values. Values that == cannot compare meaningfully are compared with reflect.DeepEqual.
+func (ce *ConversionEngine) differs(info *model.TypeInfo, x, y string) string {
+	if getEffectiveTypeInfo(info).Kind == model.Primitive {
+		return fmt.Sprintf("%s != %s", x, y)
+	}
+	if info.UniqueKey() == timeTypeKey {
+		if strings.HasPrefix(x, "*") {
+			x = "(" + x + ")"
+		}
+		return fmt.Sprintf("!%s.Equal(%s)", x, y)
+	}
+	return fmt.Sprintf("!%s.DeepEqual(%s, %s)", ce.importManager.Add("reflect"), x, y)
+}
+
+// pointerTo returns the expression of a pointer to the value of expr, of type info.
+func pointerTo(info *model.TypeInfo, expr string) string {
+	if info.Kind == model.Pointer {
+		return expr
+	}
+	return "&" + expr
+}
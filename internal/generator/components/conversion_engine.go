@@ -3,7 +3,9 @@ package components
 import (
 	"fmt"
 	"log/slog"
+	"sort"
 	"strings"
+	"unicode"
 
 	"github.com/origadmin/abgen/internal/config"
 	"github.com/origadmin/abgen/internal/model"
@@ -18,7 +20,22 @@ type ConversionEngine struct {
 	importManager     model.ImportManager
 	stubsToGenerate   map[string]*model.ConversionTask
 	helperMap         map[string]model.Helper
+	timeHelpers       map[string]model.Helper
+	diffHelpers       map[string]model.Helper
 	existingFunctions map[string]bool
+	conversionFuncs   map[string]*model.ConversionFunc
+	helperFuncs       map[string]*model.ConversionFunc
+	customFuncs       map[string]*model.ConversionFunc
+	typeInfos         map[string]*model.TypeInfo
+	plan              *model.ExecutionPlan
+	errorMode         config.ErrorMode
+	helperMode        config.HelperMode
+	cfg               *config.Config
+	// discardErrors is set while generating functions that cannot return conversion errors.
+	discardErrors bool
+	// errorResult is the value returned alongside an error when it is not nil, as for
+	// converter methods returning structs or basic types.
+	errorResult string
 }
 
 func NewConversionEngine(
@@ -35,7 +52,17 @@ func NewConversionEngine(
 		importManager:     importManager,
 		stubsToGenerate:   make(map[string]*model.ConversionTask),
 		helperMap:         make(map[string]model.Helper),
+		timeHelpers:       make(map[string]model.Helper),
+		diffHelpers:       make(map[string]model.Helper),
 		existingFunctions: analysisResult.ExistingFunctions,
+		conversionFuncs:   analysisResult.ConversionFuncs,
+		helperFuncs:       analysisResult.HelperFuncs,
+		customFuncs:       analysisResult.CustomFuncs,
+		typeInfos:         analysisResult.TypeInfos,
+		plan:              analysisResult.ExecutionPlan,
+		errorMode:         analysisResult.ExecutionPlan.FinalConfig.GlobalBehaviorRules.ErrorMode,
+		helperMode:        analysisResult.ExecutionPlan.FinalConfig.GlobalBehaviorRules.HelperMode,
+		cfg:               analysisResult.ExecutionPlan.FinalConfig,
 	}
 	ce.initializeHelpers()
 	return ce
@@ -46,6 +73,12 @@ func (ce *ConversionEngine) initializeHelpers() {
 		key := h.SourceType + "->" + h.TargetType
 		ce.helperMap[key] = h
 	}
+	for _, h := range GetTimeHelpers() {
+		ce.timeHelpers[h.Name] = h
+	}
+	for _, h := range GetDiffHelpers() {
+		ce.diffHelpers[h.Name] = h
+	}
 }
 
 func (ce *ConversionEngine) GenerateConversionFunction(
@@ -53,6 +86,11 @@ func (ce *ConversionEngine) GenerateConversionFunction(
 ) (*model.GeneratedCode, []*model.ConversionTask, error) {
 	slog.Debug("ConversionEngine: Received task", "source", sourceInfo.UniqueKey(), "target", targetInfo.UniqueKey())
 
+	if sourceInfo.UniqueKey() == targetInfo.UniqueKey() {
+		slog.Debug("ConversionEngine: Dispatching to generateDeepCopyFunction")
+		return ce.generateDeepCopyFunction(sourceInfo)
+	}
+
 	concreteSource := getConcreteType(sourceInfo)
 	concreteTarget := getConcreteType(targetInfo)
 
@@ -64,6 +102,11 @@ func (ce *ConversionEngine) GenerateConversionFunction(
 		return ce.GenerateSliceConversion(sourceInfo, targetInfo)
 	}
 
+	if isStructMapPair(sourceInfo, targetInfo) {
+		slog.Debug("ConversionEngine: Dispatching to generateMapConversion")
+		return ce.generateMapConversion(sourceInfo, targetInfo, rule)
+	}
+
 	if !isStructConversion {
 		slog.Warn("ConversionEngine: Task is neither a struct nor a slice conversion, skipping.", "source", sourceInfo.UniqueKey())
 		return nil, nil, nil
@@ -74,11 +117,24 @@ func (ce *ConversionEngine) GenerateConversionFunction(
 	sourceTypeStr := ce.typeFormatter.Format(sourceInfo)
 	targetTypeStr := ce.typeFormatter.Format(targetInfo)
 
+	// A To method receives the value to convert as its receiver.
+	method := ce.plan.ConversionMethod(sourceInfo, targetInfo)
+	signature := fmt.Sprintf("%s(from *%s)", funcName, sourceTypeStr)
+	if method != nil && !method.From {
+		funcName = method.Name
+		signature = fmt.Sprintf("(from *%s) %s()", sourceTypeStr, method.Name)
+	}
+
 	buf.WriteString(fmt.Sprintf("// %s converts %s to %s.\n", funcName, sourceTypeStr, targetTypeStr))
-	buf.WriteString(fmt.Sprintf("func %s(from *%s) *%s {\n", funcName, sourceTypeStr, targetTypeStr))
-	buf.WriteString("\tif from == nil {\n\t\treturn nil\n\t}\n\n")
+	if ce.returnsErrors() {
+		buf.WriteString(fmt.Sprintf("func %s (*%s, error) {\n", signature, targetTypeStr))
+		buf.WriteString("\tif from == nil {\n\t\treturn nil, nil\n\t}\n\n")
+	} else {
+		buf.WriteString(fmt.Sprintf("func %s *%s {\n", signature, targetTypeStr))
+		buf.WriteString("\tif from == nil {\n\t\treturn nil\n\t}\n\n")
+	}
 
-	structCode, requiredHelpers, newTasks, err := ce.generateStructToStructConversion(sourceInfo, targetInfo, rule)
+	structCode, requiredHelpers, newTasks, fields, err := ce.generateStructToStructConversion(sourceInfo, targetInfo, rule)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -86,12 +142,36 @@ func (ce *ConversionEngine) GenerateConversionFunction(
 
 	buf.WriteString("}\n\n")
 
+	if method != nil && method.From {
+		buf.WriteString(ce.fromMethod(method, funcName, sourceTypeStr, targetTypeStr))
+	}
+
 	return &model.GeneratedCode{
 		FunctionBody:    buf.String(),
 		RequiredHelpers: requiredHelpers,
+		Fields:          fields,
 	}, newTasks, nil
 }
 
+// fromMethod returns a From method setting its receiver to the conversion of its argument.
+// The method calls the conversion function, which other conversions call as well.
+func (ce *ConversionEngine) fromMethod(method *model.ConversionMethod, funcName, sourceTypeStr, targetTypeStr string) string {
+	var buf strings.Builder
+	buf.WriteString(fmt.Sprintf("// %s replaces the receiver with the conversion of from. A nil from leaves it unchanged.\n", method.Name))
+	if ce.returnsErrors() {
+		buf.WriteString(fmt.Sprintf("func (to *%s) %s(from *%s) error {\n", targetTypeStr, method.Name, sourceTypeStr))
+		buf.WriteString(fmt.Sprintf("\tconv, err := %s(from)\n", funcName))
+		buf.WriteString("\tif err != nil {\n\t\treturn err\n\t}\n")
+		buf.WriteString("\tif conv != nil {\n\t\t*to = *conv\n\t}\n")
+		buf.WriteString("\treturn nil\n")
+	} else {
+		buf.WriteString(fmt.Sprintf("func (to *%s) %s(from *%s) {\n", targetTypeStr, method.Name, sourceTypeStr))
+		buf.WriteString(fmt.Sprintf("\tif conv := %s(from); conv != nil {\n\t\t*to = *conv\n\t}\n", funcName))
+	}
+	buf.WriteString("}\n\n")
+	return buf.String()
+}
+
 func (ce *ConversionEngine) GenerateSliceConversion(
 	sourceInfo, targetInfo *model.TypeInfo,
 ) (*model.GeneratedCode, []*model.ConversionTask, error) {
@@ -125,8 +205,13 @@ func (ce *ConversionEngine) GenerateSliceConversion(
 	sourceElemStr := ce.typeFormatter.Format(sourceElem)
 	targetElemStr := ce.typeFormatter.Format(targetElem)
 	buf.WriteString(fmt.Sprintf("// %s converts a slice of %s to a slice of %s.\n", funcName, sourceElemStr, targetElemStr))
-	buf.WriteString(fmt.Sprintf("func %s(froms %s) %s {\n", funcName, sourceSliceStr, targetSliceStr))
-	buf.WriteString("\tif froms == nil {\n\t\treturn nil\t}\n")
+	if ce.returnsErrors() {
+		buf.WriteString(fmt.Sprintf("func %s(froms %s) (%s, error) {\n", funcName, sourceSliceStr, targetSliceStr))
+		buf.WriteString("\tif froms == nil {\n\t\treturn nil, nil\n\t}\n")
+	} else {
+		buf.WriteString(fmt.Sprintf("func %s(froms %s) %s {\n", funcName, sourceSliceStr, targetSliceStr))
+		buf.WriteString("\tif froms == nil {\n\t\treturn nil\t}\n")
+	}
 
 	loopVar := "froms"
 	if isSourcePtr {
@@ -138,7 +223,15 @@ func (ce *ConversionEngine) GenerateSliceConversion(
 
 	var assignment string
 	if sourceElem.UniqueKey() == targetElem.UniqueKey() {
-		assignment = "tos[i] = f"
+		elemExpr := "f"
+		if ce.deepCopies() {
+			var elemTasks []*model.ConversionTask
+			elemExpr, elemTasks = ce.deepCopyExpression(sourceElem, "f")
+			newTasks = append(newTasks, elemTasks...)
+		}
+		assignment = "tos[i] = " + elemExpr
+	} else if fn, found := ce.findConversionFunc(sourceElem, targetElem); found {
+		assignment = "tos[i] = " + ce.sliceElementCall(&buf, ce.conversionFuncName(fn), "f", fn.ReturnsError)
 	} else {
 		elemFuncName := ce.nameGenerator.ConversionFunctionName(sourceElem, targetElem)
 		if _, exists := ce.existingFunctions[elemFuncName]; !exists {
@@ -151,8 +244,9 @@ func (ce *ConversionEngine) GenerateSliceConversion(
 			arg = "&f"
 		}
 
-		call := fmt.Sprintf("%s(%s)", elemFuncName, arg)
-
+		// Map to struct conversions report mismatched entries even in ignore mode.
+		fallible := ce.returnsErrors() || isStructMapPair(sourceElem, targetElem) && getConcreteType(targetElem).Kind == model.Struct
+		call := ce.sliceElementCall(&buf, elemFuncName, arg, fallible)
 		if getConcreteType(targetElem).Kind == model.Struct && targetElem.Kind != model.Pointer {
 			call = "*" + call
 		}
@@ -162,10 +256,14 @@ func (ce *ConversionEngine) GenerateSliceConversion(
 	buf.WriteString(fmt.Sprintf("\t\t%s\n", assignment))
 	buf.WriteString("\t}\n")
 
+	result := "tos"
 	if isTargetPtr {
-		buf.WriteString(fmt.Sprintf("\treturn &tos\n"))
+		result = "&tos"
+	}
+	if ce.returnsErrors() {
+		buf.WriteString(fmt.Sprintf("\treturn %s, nil\n", result))
 	} else {
-		buf.WriteString(fmt.Sprintf("\treturn tos\n"))
+		buf.WriteString(fmt.Sprintf("\treturn %s\n", result))
 	}
 
 	buf.WriteString("}\n\n")
@@ -173,71 +271,72 @@ func (ce *ConversionEngine) GenerateSliceConversion(
 	return &model.GeneratedCode{FunctionBody: buf.String()}, newTasks, nil
 }
 
-func findField(typeInfo *model.TypeInfo, fieldName string) *model.FieldInfo {
-	if typeInfo == nil {
-		return nil
+// sliceElementCall returns the expression converting a single slice element inside the
+// conversion loop, writing the error handling for fallible calls to buf first.
+func (ce *ConversionEngine) sliceElementCall(buf *strings.Builder, funcName, arg string, fallible bool) string {
+	call := fmt.Sprintf("%s(%s)", funcName, arg)
+	if !fallible {
+		return call
 	}
-	for _, f := range typeInfo.Fields {
-		if f.Name == fieldName || strings.EqualFold(f.Name, fieldName) {
-			return f
-		}
-	}
-	return nil
+	assignment := ce.fallibleAssignment("v", call, `fmt.Errorf("convert element %d: %w", i, err)`)
+	buf.WriteString("\t" + strings.ReplaceAll(assignment, "\n", "\n\t") + "\n")
+	return "v"
 }
 
 func (ce *ConversionEngine) generateStructToStructConversion(
 	sourceInfo, targetInfo *model.TypeInfo, rule *config.ConversionRule,
-) (string, []model.Helper, []*model.ConversionTask, error) {
+) (string, []model.Helper, []*model.ConversionTask, []*model.FieldMapping, error) {
 	var buf strings.Builder
+	fields := []*model.FieldMapping{}
 	var fieldAssignments []string
 	var preAssignments []string
 	var allRequiredHelpers []model.Helper
 	var newTasks []*model.ConversionTask
 
 	targetTypeStr := ce.typeFormatter.Format(targetInfo)
-	sourceEdgesField := findField(sourceInfo, "Edges")
 
-	for _, targetField := range targetInfo.Fields {
-		if _, shouldIgnore := rule.FieldRules.Ignore[targetField.Name]; shouldIgnore {
-			continue
-		}
-
-		sourceFieldName := targetField.Name
-		if remappedName, ok := rule.FieldRules.Remap[targetField.Name]; ok {
-			sourceFieldName = remappedName
-		}
-
-		var sourceField *model.FieldInfo
-		var sourceFieldExpr string
-
-		// Strategy 1: Find a direct field match in the source struct.
-		sourceField = findField(sourceInfo, sourceFieldName)
-		if sourceField != nil {
-			sourceFieldExpr = fmt.Sprintf("from.%s", sourceField.Name)
-		} else if sourceEdgesField != nil {
-			// Strategy 2: If no direct match, look inside the 'Edges' field.
-			edgeField := findField(sourceEdgesField.Type, sourceFieldName)
-			if edgeField != nil {
-				sourceField = edgeField
-				sourceFieldExpr = fmt.Sprintf("from.Edges.%s", edgeField.Name)
-			}
-		}
-
-		if sourceField != nil {
-			conversionExpr, requiredHelpers, newTask, preAssignmentsForField := ce.getConversionExpression(
-				sourceField.Type,
-				targetField.Type,
-				sourceFieldExpr,
-			)
-			allRequiredHelpers = append(allRequiredHelpers, requiredHelpers...)
-			if newTask != nil {
-				newTasks = append(newTasks, newTask)
-			}
-			preAssignments = append(preAssignments, preAssignmentsForField...)
-
-			fieldAssignments = append(fieldAssignments, fmt.Sprintf("\t\t%s: %s,", targetField.Name, conversionExpr))
-		}
-	}
+	for _, pair := range model.PairFields(sourceInfo, targetInfo, fieldRulesOf(rule)) {
+		conversionExpr, requiredHelpers, fieldTasks, preAssignmentsForField, strategy := ce.convertField(
+			pair.Source.Type,
+			pair.Target.Type,
+			"from."+pair.SourcePath,
+			ce.fieldOptionsFor(sourceInfo, pair.Source.Name, targetInfo, pair.Target.Name),
+		)
+		fields = append(fields, &model.FieldMapping{
+			Target:     pair.Target.Name,
+			TargetType: model.GetElementType(pair.Target.Type).UniqueKey(),
+			Source:     pair.SourcePath,
+			SourceType: model.GetElementType(pair.Source.Type).UniqueKey(),
+			Expression: conversionExpr,
+			Strategy:   strategy,
+		})
+		allRequiredHelpers = append(allRequiredHelpers, requiredHelpers...)
+		newTasks = append(newTasks, fieldTasks...)
+		preAssignments = append(preAssignments, preAssignmentsForField...)
+
+		fieldAssignments = append(fieldAssignments, fmt.Sprintf("\t\t%s: %s,", pair.Target.Name, conversionExpr))
+	}
+	ignored, unmapped := model.UnpairedFields(sourceInfo, targetInfo, fieldRulesOf(rule))
+	for _, field := range ignored {
+		fields = append(fields, &model.FieldMapping{
+			Target:     field.Name,
+			TargetType: model.GetElementType(field.Type).UniqueKey(),
+			Strategy:   model.StrategyIgnored,
+		})
+	}
+	for _, field := range unmapped {
+		fields = append(fields, &model.FieldMapping{
+			Target:     field.Name,
+			TargetType: model.GetElementType(field.Type).UniqueKey(),
+			Strategy:   model.StrategyUnmapped,
+		})
+	}
+	// Fields are recorded in the order of the target struct.
+	order := make(map[string]int)
+	for i, field := range getConcreteType(targetInfo).Fields {
+		order[field.Name] = i
+	}
+	sort.SliceStable(fields, func(i, j int) bool { return order[fields[i].Target] < order[fields[j].Target] })
 
 	for _, preAssignment := range preAssignments {
 		buf.WriteString(preAssignment + "\n")
@@ -251,17 +350,113 @@ func (ce *ConversionEngine) generateStructToStructConversion(
 		buf.WriteString(assignment + "\n")
 	}
 	buf.WriteString("\t}\n")
-	buf.WriteString("\treturn to\n")
+	if ce.returnsErrors() {
+		buf.WriteString("\treturn to, nil\n")
+	} else {
+		buf.WriteString("\treturn to\n")
+	}
+
+	return buf.String(), allRequiredHelpers, newTasks, fields, nil
+}
+
+// fieldRulesOf returns the field rules of rule, which is nil for conversions discovered
+// while generating other functions.
+func fieldRulesOf(rule *config.ConversionRule) config.FieldRuleSet {
+	if rule == nil {
+		return config.FieldRuleSet{}
+	}
+	return rule.FieldRules
+}
 
-	return buf.String(), allRequiredHelpers, newTasks, nil
+// fieldOptions holds the per-field directives that affect how a single field is converted.
+type fieldOptions struct {
+	time config.TimeOptions
+	// fieldTime reports whether time options are declared for the fields themselves.
+	fieldTime bool
+	json      bool
+}
+
+// fieldOptionsFor collects the options declared for a pair of source and target fields.
+// Options declared on the source field take precedence over those on the target field.
+func (ce *ConversionEngine) fieldOptionsFor(
+	sourceInfo *model.TypeInfo, sourceField string, targetInfo *model.TypeInfo, targetField string,
+) fieldOptions {
+	sourceKey := fieldOptionKey(sourceInfo, sourceField)
+	targetKey := fieldOptionKey(targetInfo, targetField)
+	_, sourceTime := ce.cfg.FieldTimeOptions[sourceKey]
+	_, targetTime := ce.cfg.FieldTimeOptions[targetKey]
+	return fieldOptions{
+		time:      ce.cfg.TimeOptionsFor(targetKey, sourceKey),
+		fieldTime: sourceTime || targetTime,
+		json:      ce.cfg.JSONFields[sourceKey] || ce.cfg.JSONFields[targetKey],
+	}
+}
+
+// fieldOptionKey identifies a field of a struct type in per-field directives.
+func fieldOptionKey(structInfo *model.TypeInfo, fieldName string) string {
+	return model.GetElementType(structInfo).FQN() + "#" + fieldName
 }
 
 func (ce *ConversionEngine) getConversionExpression(
 	sourceType, targetType *model.TypeInfo,
 	sourceFieldExpr string,
-) (string, []model.Helper, *model.ConversionTask, []string) {
+	opts fieldOptions,
+) (string, []model.Helper, []*model.ConversionTask, []string) {
+	expr, helpers, tasks, preAssignments, _ := ce.convertField(sourceType, targetType, sourceFieldExpr, opts)
+	return expr, helpers, tasks, preAssignments
+}
+
+// convertField returns the expression converting sourceFieldExpr from sourceType to
+// targetType, as getConversionExpression does, along with the strategy it chose.
+func (ce *ConversionEngine) convertField(
+	sourceType, targetType *model.TypeInfo,
+	sourceFieldExpr string,
+	opts fieldOptions,
+) (string, []model.Helper, []*model.ConversionTask, []string, model.FieldStrategy) {
 	if sourceType.UniqueKey() == targetType.UniqueKey() {
-		return sourceFieldExpr, nil, nil, nil
+		// Identical times are still normalized when a zone is configured.
+		if expr, helpers, preAssignments, ok := ce.timeConversion(sourceType, targetType, sourceFieldExpr, opts.time); ok {
+			return expr, helpers, nil, preAssignments, model.StrategyHelper
+		}
+		if ce.deepCopies() {
+			expr, tasks := ce.deepCopyExpression(sourceType, sourceFieldExpr)
+			switch {
+			case len(tasks) > 0:
+				return expr, nil, tasks, nil, model.StrategyNested
+			case expr != sourceFieldExpr:
+				// Slices and maps of values are copied with slices.Clone and maps.Clone, and
+				// anonymous structs with a composite literal.
+				return expr, nil, nil, nil, model.StrategyHelper
+			}
+			return expr, nil, nil, nil, model.StrategyDirect
+		}
+		return sourceFieldExpr, nil, nil, nil, model.StrategyDirect
+	}
+
+	if opts.json {
+		if expr, preAssignments, ok := ce.jsonConversion(sourceType, targetType, sourceFieldExpr); ok {
+			return expr, nil, nil, preAssignments, model.StrategyHelper
+		}
+	}
+
+	// Time options declared for the field take precedence over conversion functions.
+	if opts.fieldTime {
+		if expr, helpers, preAssignments, ok := ce.timeConversion(sourceType, targetType, sourceFieldExpr, opts.time); ok {
+			return expr, helpers, nil, preAssignments, model.StrategyHelper
+		}
+	}
+
+	if fn, found := ce.findConversionFunc(sourceType, targetType); found {
+		expr, preAssignments := ce.callExpression(ce.conversionFuncName(fn), sourceFieldExpr, sourceFieldExpr, fn.ReturnsError)
+		return expr, nil, nil, preAssignments, model.StrategyCustom
+	}
+
+	if expr, helpers, preAssignments, ok := ce.timeConversion(sourceType, targetType, sourceFieldExpr, opts.time); ok {
+		return expr, helpers, nil, preAssignments, model.StrategyHelper
+	}
+
+	if expr, helpers, tasks, preAssignments, ok := ce.sqlNullConversion(sourceType, targetType, sourceFieldExpr, opts); ok {
+		return expr, helpers, tasks, preAssignments, model.StrategyHelper
 	}
 
 	isSourcePtr := sourceType.Kind == model.Pointer
@@ -274,24 +469,28 @@ func (ce *ConversionEngine) getConversionExpression(
 
 	if isSimplePointerSwap {
 		if !isSourcePtr && isTargetPtr {
-			return "&" + sourceFieldExpr, nil, nil, nil
+			return "&" + sourceFieldExpr, nil, nil, nil, model.StrategyDirect
 		}
 		if isSourcePtr && !isTargetPtr {
-			tempVarName := fmt.Sprintf("temp%s", strings.ReplaceAll(sourceFieldExpr, ".", ""))
+			tempVarName := tempVarName("temp", sourceFieldExpr)
 			targetTypeStr := ce.typeFormatter.Format(targetType)
 			preAssignment := fmt.Sprintf("\tvar %s %s\n\tif %s != nil {\n\t\t%s = *%s\n\t}", tempVarName, targetTypeStr, sourceFieldExpr, tempVarName, sourceFieldExpr)
-			return tempVarName, nil, nil, []string{preAssignment}
+			return tempVarName, nil, nil, []string{preAssignment}, model.StrategyDirect
 		}
 	}
 
 	if canUseSimpleTypeConversion(sourceType, targetType) {
 		targetTypeStr := ce.typeFormatter.Format(targetType)
-		return fmt.Sprintf("%s(%s)", targetTypeStr, sourceFieldExpr), nil, nil, nil
+		return fmt.Sprintf("%s(%s)", targetTypeStr, sourceFieldExpr), nil, nil, nil, model.StrategyCast
 	}
 
 	if helper, found := ce.findHelper(sourceType, targetType); found {
-		ce.addRequiredImportsForHelper(helper)
-		return fmt.Sprintf("%s(%s)", helper.Name, sourceFieldExpr), []model.Helper{helper}, nil, nil
+		expr, preAssignments := ce.callExpression(ce.helperFuncName(helper), sourceFieldExpr, sourceFieldExpr, helper.ReturnsError)
+		return expr, []model.Helper{helper}, nil, preAssignments, model.StrategyHelper
+	}
+
+	if expr, task, preAssignments, ok := ce.structMapConversion(sourceType, targetType, sourceFieldExpr); ok {
+		return expr, nil, []*model.ConversionTask{task}, preAssignments, model.StrategyNested
 	}
 
 	concreteSourceType := getConcreteType(sourceType)
@@ -309,13 +508,14 @@ func (ce *ConversionEngine) getConversionExpression(
 			if sourceType.Kind != model.Pointer {
 				arg = "&" + sourceFieldExpr
 			}
-			expr := fmt.Sprintf("%s(%s)", convFuncName, arg)
+			expr, preAssignments := ce.callExpression(convFuncName, arg, sourceFieldExpr, ce.returnsErrors())
 			if targetType.Kind != model.Pointer {
 				expr = "*" + expr
 			}
-			return expr, nil, newTask, nil
+			return expr, nil, []*model.ConversionTask{newTask}, preAssignments, model.StrategyNested
 		}
-		return fmt.Sprintf("%s(%s)", convFuncName, sourceFieldExpr), nil, newTask, nil
+		expr, preAssignments := ce.callExpression(convFuncName, sourceFieldExpr, sourceFieldExpr, ce.returnsErrors())
+		return expr, nil, []*model.ConversionTask{newTask}, preAssignments, model.StrategyNested
 	}
 
 	// Fallback for other types, though less common for complex conversions.
@@ -323,9 +523,10 @@ func (ce *ConversionEngine) getConversionExpression(
 	if _, exists := ce.existingFunctions[convFuncName]; !exists {
 		stubTask := &model.ConversionTask{Source: sourceType, Target: targetType}
 		ce.stubsToGenerate[convFuncName] = stubTask
+		return fmt.Sprintf("%s(%s)", convFuncName, sourceFieldExpr), nil, nil, nil, model.StrategyStub
 	}
 
-	return fmt.Sprintf("%s(%s)", convFuncName, sourceFieldExpr), nil, nil, nil
+	return fmt.Sprintf("%s(%s)", convFuncName, sourceFieldExpr), nil, nil, nil, model.StrategyCustom
 }
 
 func getConcreteType(info *model.TypeInfo) *model.TypeInfo {
@@ -351,12 +552,123 @@ func getEffectiveTypeInfo(info *model.TypeInfo) *model.TypeInfo {
 	return info
 }
 
+// isStandardLibrary reports whether importPath names a standard library package, whose
+// import paths have no dot in their first element.
+func isStandardLibrary(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+// findHelper looks up the built-in helper converting source to target. The helpers are keyed
+// with the empty interface spelled any, so that interface{} finds them too.
 func (ce *ConversionEngine) findHelper(source, target *model.TypeInfo) (model.Helper, bool) {
-	key := source.UniqueKey() + "->" + target.UniqueKey()
+	key := helperTypeKey(source) + "->" + helperTypeKey(target)
 	helper, found := ce.helperMap[key]
 	return helper, found
 }
 
+// helperTypeKey returns the unique key of info with the empty interface spelled any.
+func helperTypeKey(info *model.TypeInfo) string {
+	return strings.ReplaceAll(info.UniqueKey(), "interface{}", "any")
+}
+
+// findConversionFunc looks up a user-defined function that converts source to target.
+// Functions named by convert:rule take precedence over the other functions of the directive
+// package, which take precedence over those from helper packages.
+func (ce *ConversionEngine) findConversionFunc(source, target *model.TypeInfo) (*model.ConversionFunc, bool) {
+	key := source.UniqueKey() + "->" + target.UniqueKey()
+	if fn, found := ce.customFuncs[key]; found {
+		return fn, true
+	}
+	if fn, found := ce.conversionFuncs[key]; found {
+		return fn, true
+	}
+	fn, found := ce.helperFuncs[key]
+	return fn, found
+}
+
+// conversionFuncName returns the name used to call fn, importing its package if needed.
+func (ce *ConversionEngine) conversionFuncName(fn *model.ConversionFunc) string {
+	if fn.ImportPath == "" {
+		return fn.Name
+	}
+	return ce.importManager.Add(fn.ImportPath) + "." + fn.Name
+}
+
+// returnsErrors reports whether generated conversion functions return an error.
+func (ce *ConversionEngine) returnsErrors() bool {
+	return ce.errorMode == config.ErrorModeReturn
+}
+
+// propagatesErrors reports whether the errors of fallible calls are returned from the
+// function being generated rather than discarded.
+func (ce *ConversionEngine) propagatesErrors() bool {
+	return ce.returnsErrors() && !ce.discardErrors
+}
+
+// deepCopies reports whether values of identical types are deep copied rather than shared.
+func (ce *ConversionEngine) deepCopies() bool {
+	return ce.cfg.GlobalBehaviorRules.CopyMode == config.CopyDeep
+}
+
+// callExpression builds the expression that calls funcName with arg. Calls to fallible
+// functions are hoisted into a pre-assignment so that their error can be discarded or
+// returned, depending on the configured error mode.
+func (ce *ConversionEngine) callExpression(funcName, arg, sourceFieldExpr string, fallible bool) (string, []string) {
+	call := fmt.Sprintf("%s(%s)", funcName, arg)
+	if !fallible {
+		return call, nil
+	}
+	tempVarName := tempVarName("conv", sourceFieldExpr)
+	// Errors converting the whole argument, as converter methods do, need no context.
+	errExpr := "err"
+	if sourceFieldExpr != "from" {
+		errExpr = fmt.Sprintf("fmt.Errorf(\"convert %s: %%w\", err)", strings.TrimPrefix(sourceFieldExpr, "from."))
+	}
+	return tempVarName, []string{ce.fallibleAssignment(tempVarName, call, errExpr)}
+}
+
+// tempVarName derives the name of a temporary variable from the expression it holds,
+// keeping only the characters that are valid in an identifier.
+func tempVarName(prefix, expr string) string {
+	return prefix + strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, expr)
+}
+
+// fallibleAssignment assigns the value of a call returning (T, error) to tempVarName.
+// In return mode, a non-nil error is returned from the enclosing function as errExpr.
+func (ce *ConversionEngine) fallibleAssignment(tempVarName, call, errExpr string) string {
+	if !ce.propagatesErrors() {
+		return fmt.Sprintf("\t%s, _ := %s", tempVarName, call)
+	}
+	result := "nil"
+	if ce.errorResult != "" {
+		result = ce.errorResult
+	}
+	if errExpr != "err" {
+		ce.importManager.Add("fmt")
+	}
+	return fmt.Sprintf("\t%s, err := %s\n\tif err != nil {\n\t\treturn %s, %s\n\t}", tempVarName, call, result, errExpr)
+}
+
+// helperFuncName returns the name used to call a helper and registers the imports it needs.
+// In runtime mode the helper is qualified with its runtime package; inline helpers are
+// emitted into the generated file and need their own dependencies instead.
+func (ce *ConversionEngine) helperFuncName(helper model.Helper) string {
+	if ce.helperMode == config.HelperModeInline || helper.Package == "" {
+		ce.addRequiredImportsForHelper(helper)
+		return helper.Name
+	}
+	if helper.Package == runtimePkg {
+		return ce.importManager.AddAs(runtimePkg, runtimePkgAlias) + "." + helper.Name
+	}
+	return ce.importManager.Add(helper.Package) + "." + helper.Name
+}
+
 func (ce *ConversionEngine) addRequiredImportsForHelper(helper model.Helper) {
 	for _, pkg := range helper.Dependencies {
 		ce.importManager.Add(pkg)
@@ -0,0 +1,101 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/origadmin/abgen/internal/model"
+)
+
+// GenerateConverterImplementation generates the struct implementing a converter interface.
+// Each method converts its argument as a struct field of the same types would be converted,
+// so the conversions it needs are generated as the usual functions. The tasks creating them
+// carry the method as their origin, to report the custom conversions a method is missing.
+func (ce *ConversionEngine) GenerateConverterImplementation(
+	converter *model.ConverterInterface,
+) (*model.GeneratedCode, []*model.ConversionTask, error) {
+	var buf strings.Builder
+	var requiredHelpers []model.Helper
+	var newTasks []*model.ConversionTask
+
+	buf.WriteString(fmt.Sprintf("// %s implements %s.\n", converter.StructName, converter.Name))
+	buf.WriteString(fmt.Sprintf("type %s struct{}\n\n", converter.StructName))
+	buf.WriteString(fmt.Sprintf("var _ %s = %s{}\n\n", converter.Name, converter.StructName))
+
+	for _, method := range converter.Methods {
+		methodCode, helpers, tasks := ce.generateConverterMethod(converter.StructName, method)
+		buf.WriteString(methodCode)
+		requiredHelpers = append(requiredHelpers, helpers...)
+		for _, task := range tasks {
+			// Struct conversion functions take and return pointers to the struct types.
+			task.Source, task.Target = derefType(task.Source), derefType(task.Target)
+			task.Origin = method.QualifiedName()
+			newTasks = append(newTasks, task)
+		}
+	}
+
+	return &model.GeneratedCode{
+		FunctionBody:    buf.String(),
+		RequiredHelpers: requiredHelpers,
+	}, newTasks, nil
+}
+
+// generateConverterMethod generates a single method of a converter implementation.
+// Conversion errors are returned when the method returns an error and discarded otherwise.
+func (ce *ConversionEngine) generateConverterMethod(
+	structName string, method *model.ConverterMethod,
+) (string, []model.Helper, []*model.ConversionTask) {
+	ce.discardErrors = !method.ReturnsError
+	ce.errorResult = ce.zeroValue(method.Target)
+	defer func() {
+		ce.discardErrors = false
+		ce.errorResult = ""
+	}()
+
+	sourceTypeStr := ce.typeFormatter.Format(method.Source)
+	targetTypeStr := ce.typeFormatter.Format(method.Target)
+	expr, helpers, tasks, preAssignments := ce.getConversionExpression(
+		method.Source, method.Target, "from", fieldOptions{time: ce.cfg.GlobalBehaviorRules.Time},
+	)
+
+	var buf strings.Builder
+	buf.WriteString(fmt.Sprintf("// %s converts %s to %s.\n", method.Name, sourceTypeStr, targetTypeStr))
+	if method.ReturnsError {
+		buf.WriteString(fmt.Sprintf("func (%s) %s(from %s) (%s, error) {\n", structName, method.Name, sourceTypeStr, targetTypeStr))
+	} else {
+		buf.WriteString(fmt.Sprintf("func (%s) %s(from %s) %s {\n", structName, method.Name, sourceTypeStr, targetTypeStr))
+	}
+	for _, preAssignment := range preAssignments {
+		buf.WriteString(preAssignment + "\n")
+	}
+	if method.ReturnsError {
+		buf.WriteString(fmt.Sprintf("\treturn %s, nil\n", expr))
+	} else {
+		buf.WriteString(fmt.Sprintf("\treturn %s\n", expr))
+	}
+	buf.WriteString("}\n\n")
+
+	return buf.String(), helpers, tasks
+}
+
+// zeroValue returns an expression for the zero value of info.
+func (ce *ConversionEngine) zeroValue(info *model.TypeInfo) string {
+	effective := getEffectiveTypeInfo(info)
+	if effective == nil {
+		return "nil"
+	}
+	switch effective.Kind {
+	case model.Struct, model.Array:
+		return ce.typeFormatter.Format(info) + "{}"
+	case model.Primitive:
+		switch {
+		case effective.Name == "string":
+			return `""`
+		case effective.Name == "bool":
+			return "false"
+		case isNumeric(effective.Name):
+			return "0"
+		}
+	}
+	return "nil"
+}
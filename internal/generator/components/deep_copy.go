@@ -0,0 +1,208 @@
+package components
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+
+	"github.com/origadmin/abgen/internal/model"
+)
+
+const (
+	slicesPkg = "slices"
+	mapsPkg   = "maps"
+)
+
+// needsDeepCopy reports whether assigning a value of type info shares memory with the
+// original: pointers, slices and maps, and arrays and structs containing them. Structs
+// of the standard library, such as time.Time, anonymous structs with embedded or
+// unexported fields, and interface values are treated as values.
+func needsDeepCopy(info *model.TypeInfo) bool {
+	return needsDeepCopyVisiting(info, make(map[string]bool))
+}
+
+func needsDeepCopyVisiting(info *model.TypeInfo, visiting map[string]bool) bool {
+	if info == nil {
+		return false
+	}
+	switch info.Kind {
+	case model.Pointer, model.Slice, model.Map:
+		return true
+	case model.Array, model.Named:
+		return needsDeepCopyVisiting(info.Underlying, visiting)
+	case model.Struct:
+		if info.Name == "" {
+			if !isCompositeLiteralStruct(info) {
+				return false
+			}
+			for _, field := range info.Fields {
+				if needsDeepCopyVisiting(field.Type, visiting) {
+					return true
+				}
+			}
+			return false
+		}
+		if isStandardLibrary(info.ImportPath) {
+			return false
+		}
+		// A struct can only contain itself through a pointer, which needs a copy anyway.
+		key := info.UniqueKey()
+		if visiting[key] {
+			return false
+		}
+		visiting[key] = true
+		for _, field := range info.Fields {
+			if needsDeepCopyVisiting(field.Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isCompositeLiteralStruct reports whether an anonymous struct can be copied with a
+// composite literal listing its fields, that is whether all of them are exported and none
+// of them is embedded.
+func isCompositeLiteralStruct(info *model.TypeInfo) bool {
+	if info.Original == nil {
+		return false
+	}
+	s, ok := info.Original.Type().(*types.Struct)
+	if !ok {
+		return false
+	}
+	for i := 0; i < s.NumFields(); i++ {
+		if f := s.Field(i); f.Embedded() || !f.Exported() {
+			return false
+		}
+	}
+	return true
+}
+
+// deepCopyExpression returns the expression copying the value of expr, and the tasks
+// generating the DeepCopy functions it calls. Slices and maps of plain values are cloned
+// with the slices and maps packages, and anonymous structs with a composite literal.
+func (ce *ConversionEngine) deepCopyExpression(info *model.TypeInfo, expr string) (string, []*model.ConversionTask) {
+	if !needsDeepCopy(info) {
+		return expr, nil
+	}
+
+	effective := getEffectiveTypeInfo(info)
+	switch effective.Kind {
+	case model.Slice:
+		if !needsDeepCopy(effective.Underlying) {
+			return fmt.Sprintf("%s.Clone(%s)", ce.importManager.Add(slicesPkg), expr), nil
+		}
+	case model.Map:
+		if !needsDeepCopy(effective.Underlying) {
+			return fmt.Sprintf("%s.Clone(%s)", ce.importManager.Add(mapsPkg), expr), nil
+		}
+	case model.Struct:
+		if effective.Name == "" {
+			return ce.anonymousStructCopy(effective, expr)
+		}
+		task := &model.ConversionTask{Source: info, Target: info}
+		return fmt.Sprintf("*%s(%s)", ce.nameGenerator.DeepCopyFunctionName(info), addressOf(expr)), []*model.ConversionTask{task}
+	case model.Pointer:
+		if effective.Underlying.IsUltimatelyStruct() {
+			task := &model.ConversionTask{Source: effective.Underlying, Target: effective.Underlying}
+			return fmt.Sprintf("%s(%s)", ce.nameGenerator.DeepCopyFunctionName(effective.Underlying), expr), []*model.ConversionTask{task}
+		}
+	}
+
+	task := &model.ConversionTask{Source: info, Target: info}
+	return fmt.Sprintf("%s(%s)", ce.nameGenerator.DeepCopyFunctionName(info), expr), []*model.ConversionTask{task}
+}
+
+// anonymousStructCopy returns the composite literal copying the anonymous struct expr
+// field by field. Anonymous structs have no DeepCopy function of their own.
+func (ce *ConversionEngine) anonymousStructCopy(info *model.TypeInfo, expr string) (string, []*model.ConversionTask) {
+	root := expr
+	if strings.HasPrefix(expr, "*") {
+		root = "(" + expr + ")"
+	}
+	var tasks []*model.ConversionTask
+	fields := make([]string, 0, len(info.Fields))
+	for _, field := range info.Fields {
+		copied, fieldTasks := ce.deepCopyExpression(field.Type, root+"."+field.Name)
+		tasks = append(tasks, fieldTasks...)
+		fields = append(fields, fmt.Sprintf("%s: %s", field.Name, copied))
+	}
+	return fmt.Sprintf("%s{%s}", ce.typeFormatter.Format(info), strings.Join(fields, ", ")), tasks
+}
+
+// generateDeepCopyFunction generates the DeepCopy function for a struct, slice, array, map
+// or pointer type. Struct copies start from a shallow copy, so unexported fields are shared
+// with the original.
+func (ce *ConversionEngine) generateDeepCopyFunction(info *model.TypeInfo) (*model.GeneratedCode, []*model.ConversionTask, error) {
+	var buf strings.Builder
+	var newTasks []*model.ConversionTask
+	copyValue := func(typeInfo *model.TypeInfo, expr string) string {
+		copied, tasks := ce.deepCopyExpression(typeInfo, expr)
+		newTasks = append(newTasks, tasks...)
+		return copied
+	}
+
+	if info.Kind == model.Pointer && info.Underlying.IsUltimatelyStruct() {
+		info = info.Underlying
+	}
+	funcName := ce.nameGenerator.DeepCopyFunctionName(info)
+	typeStr := ce.typeFormatter.Format(info)
+	effective := getEffectiveTypeInfo(info)
+
+	buf.WriteString(fmt.Sprintf("// %s returns a deep copy of %s.\n", funcName, typeStr))
+	switch effective.Kind {
+	case model.Struct:
+		buf.WriteString(fmt.Sprintf("func %s(from *%s) *%s {\n", funcName, typeStr, typeStr))
+		buf.WriteString("\tif from == nil {\n\t\treturn nil\n\t}\n\n")
+		buf.WriteString("\tto := *from\n")
+		for _, field := range effective.Fields {
+			fieldExpr := "from." + field.Name
+			if copied := copyValue(field.Type, fieldExpr); copied != fieldExpr {
+				buf.WriteString(fmt.Sprintf("\tto.%s = %s\n", field.Name, copied))
+			}
+		}
+		buf.WriteString("\treturn &to\n")
+	case model.Slice:
+		buf.WriteString(fmt.Sprintf("func %s(from %s) %s {\n", funcName, typeStr, typeStr))
+		buf.WriteString("\tif from == nil {\n\t\treturn nil\n\t}\n\n")
+		buf.WriteString(fmt.Sprintf("\tto := make(%s, len(from))\n", typeStr))
+		buf.WriteString("\tfor i, v := range from {\n")
+		buf.WriteString(fmt.Sprintf("\t\tto[i] = %s\n", copyValue(effective.Underlying, "v")))
+		buf.WriteString("\t}\n")
+		buf.WriteString("\treturn to\n")
+	case model.Array:
+		buf.WriteString(fmt.Sprintf("func %s(from %s) %s {\n", funcName, typeStr, typeStr))
+		buf.WriteString(fmt.Sprintf("\tvar to %s\n", typeStr))
+		buf.WriteString("\tfor i, v := range from {\n")
+		buf.WriteString(fmt.Sprintf("\t\tto[i] = %s\n", copyValue(effective.Underlying, "v")))
+		buf.WriteString("\t}\n")
+		buf.WriteString("\treturn to\n")
+	case model.Map:
+		buf.WriteString(fmt.Sprintf("func %s(from %s) %s {\n", funcName, typeStr, typeStr))
+		buf.WriteString("\tif from == nil {\n\t\treturn nil\n\t}\n\n")
+		buf.WriteString(fmt.Sprintf("\tto := make(%s, len(from))\n", typeStr))
+		buf.WriteString("\tfor k, v := range from {\n")
+		buf.WriteString(fmt.Sprintf("\t\tto[k] = %s\n", copyValue(effective.Underlying, "v")))
+		buf.WriteString("\t}\n")
+		buf.WriteString("\treturn to\n")
+	case model.Pointer:
+		buf.WriteString(fmt.Sprintf("func %s(from %s) %s {\n", funcName, typeStr, typeStr))
+		buf.WriteString("\tif from == nil {\n\t\treturn nil\n\t}\n\n")
+		buf.WriteString(fmt.Sprintf("\tto := %s\n", copyValue(effective.Underlying, "*from")))
+		buf.WriteString("\treturn &to\n")
+	default:
+		return nil, nil, fmt.Errorf("cannot generate a deep copy of %s", info.UniqueKey())
+	}
+	buf.WriteString("}\n\n")
+
+	return &model.GeneratedCode{FunctionBody: buf.String()}, newTasks, nil
+}
+
+// addressOf returns the expression taking the address of expr, simplifying &*p to p.
+func addressOf(expr string) string {
+	if strings.HasPrefix(expr, "*") {
+		return expr[1:]
+	}
+	return "&" + expr
+}
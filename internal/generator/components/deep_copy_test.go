@@ -0,0 +1,63 @@
+package components
+
+import (
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/origadmin/abgen/internal/model"
+)
+
+func TestNeedsDeepCopy(t *testing.T) {
+	stringType := newPrimitive("string")
+	line := newStruct("Line", "example.com/shop", []*model.FieldInfo{
+		{Name: "SKU", Type: stringType},
+	})
+	item := newStruct("Item", "example.com/shop", []*model.FieldInfo{
+		{Name: "Tags", Type: newSlice(stringType)},
+	})
+	node := newStruct("Node", "example.com/shop", nil)
+	node.Fields = []*model.FieldInfo{{Name: "Next", Type: newPointer(node)}}
+	timeType := newStruct("Time", "time", []*model.FieldInfo{{Name: "loc", Type: newPointer(newStruct("Location", "time", nil))}})
+	anonymous := func(fields ...*types.Var) *model.TypeInfo {
+		info := &model.TypeInfo{Kind: model.Struct}
+		for _, f := range fields {
+			if f.Exported() {
+				info.Fields = append(info.Fields, &model.FieldInfo{Name: f.Name(), Type: newSlice(stringType)})
+			}
+		}
+		info.Original = types.NewVar(token.NoPos, nil, "", types.NewStruct(fields, nil))
+		return info
+	}
+	tagsField := types.NewField(token.NoPos, nil, "Tags", types.NewSlice(types.Typ[types.String]), false)
+	hiddenField := types.NewField(token.NoPos, nil, "hidden", types.NewSlice(types.Typ[types.String]), false)
+
+	tests := []struct {
+		name string
+		info *model.TypeInfo
+		want bool
+	}{
+		{name: "primitive", info: stringType},
+		{name: "pointer", info: newPointer(stringType), want: true},
+		{name: "slice", info: newSlice(stringType), want: true},
+		{name: "map", info: &model.TypeInfo{Kind: model.Map, KeyType: stringType, Underlying: stringType}, want: true},
+		{name: "array of values", info: &model.TypeInfo{Kind: model.Array, ArrayLen: 2, Underlying: stringType}},
+		{name: "array of slices", info: &model.TypeInfo{Kind: model.Array, ArrayLen: 2, Underlying: newSlice(stringType)}, want: true},
+		{name: "struct of values", info: line},
+		{name: "struct with a slice", info: item, want: true},
+		{name: "recursive struct", info: node, want: true},
+		{name: "standard library struct", info: timeType},
+		{name: "anonymous struct with a slice", info: anonymous(tagsField), want: true},
+		{name: "anonymous struct with an unexported field", info: anonymous(tagsField, hiddenField)},
+		{name: "named slice", info: newNamed("Tags", "example.com/shop", newSlice(stringType)), want: true},
+		{name: "interface", info: &model.TypeInfo{Kind: model.Interface, Name: "interface{}"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := needsDeepCopy(tt.info); got != tt.want {
+				t.Errorf("needsDeepCopy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
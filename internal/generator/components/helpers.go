@@ -4,10 +4,19 @@ import "github.com/origadmin/abgen/internal/model"
 
 const (
 	timePkg        = "time"
+	syncPkg        = "sync"
 	uuidPkg        = "github.com/google/uuid"
 	timestamppbPkg = "google.golang.org/protobuf/types/known/timestamppb"
 	wrapperspbPkg  = "google.golang.org/protobuf/types/known/wrapperspb"
 	durationpbPkg  = "google.golang.org/protobuf/types/known/durationpb"
+	structpbPkg    = "google.golang.org/protobuf/types/known/structpb"
+
+	// runtimePkg holds the helpers that only depend on the standard library.
+	// Its name clashes with the standard runtime package, so it is imported as runtimePkgAlias.
+	runtimePkg      = "github.com/origadmin/abgen/runtime"
+	runtimePkgAlias = "abgenrt"
+	uuidconvPkg     = runtimePkg + "/uuidconv"
+	protoconvPkg    = runtimePkg + "/protoconv"
 )
 
 // GetBuiltInHelpers returns a list of all built-in helper functions.
@@ -19,6 +28,7 @@ func GetBuiltInHelpers() []model.Helper {
 			SourceType:   "string",
 			TargetType:   "time.Time",
 			Dependencies: []string{timePkg},
+			Package:      runtimePkg,
 			Body: `
 func ConvertStringToTime(s string) time.Time {
 	t, _ := time.Parse(time.RFC3339, s)
@@ -30,6 +40,7 @@ func ConvertStringToTime(s string) time.Time {
 			SourceType:   "time.Time",
 			TargetType:   "string",
 			Dependencies: []string{timePkg},
+			Package:      runtimePkg,
 			Body: `
 func ConvertTimeToString(t time.Time) string {
 	return t.Format(time.RFC3339)
@@ -41,6 +52,7 @@ func ConvertTimeToString(t time.Time) string {
 			SourceType:   "string",
 			TargetType:   "github.com/google/uuid.UUID",
 			Dependencies: []string{uuidPkg},
+			Package:      uuidconvPkg,
 			Body: `
 func ConvertStringToUUID(s string) uuid.UUID {
 	u, _ := uuid.Parse(s)
@@ -52,6 +64,7 @@ func ConvertStringToUUID(s string) uuid.UUID {
 			SourceType:   "github.com/google/uuid.UUID",
 			TargetType:   "string",
 			Dependencies: []string{uuidPkg},
+			Package:      uuidconvPkg,
 			Body: `
 func ConvertUUIDToString(u uuid.UUID) string {
 	return u.String()
@@ -63,6 +76,7 @@ func ConvertUUIDToString(u uuid.UUID) string {
 			SourceType:   "time.Time",
 			TargetType:   "*google.golang.org/protobuf/types/known/timestamppb.Timestamp",
 			Dependencies: []string{timePkg, timestamppbPkg},
+			Package:      protoconvPkg,
 			Body: `
 func ConvertTimeToTimestamp(t time.Time) *timestamppb.Timestamp {
 	if t.IsZero() {
@@ -76,6 +90,7 @@ func ConvertTimeToTimestamp(t time.Time) *timestamppb.Timestamp {
 			SourceType:   "*google.golang.org/protobuf/types/known/timestamppb.Timestamp",
 			TargetType:   "time.Time",
 			Dependencies: []string{timePkg, timestamppbPkg},
+			Package:      protoconvPkg,
 			Body: `
 func ConvertTimestampToTime(ts *timestamppb.Timestamp) time.Time {
 	if ts == nil {
@@ -90,6 +105,7 @@ func ConvertTimestampToTime(ts *timestamppb.Timestamp) time.Time {
 			SourceType:   "string",
 			TargetType:   "*google.golang.org/protobuf/types/known/wrapperspb.StringValue",
 			Dependencies: []string{wrapperspbPkg},
+			Package:      protoconvPkg,
 			Body: `
 func ConvertStringToStringValue(s string) *wrapperspb.StringValue {
 	return wrapperspb.String(s)
@@ -100,6 +116,7 @@ func ConvertStringToStringValue(s string) *wrapperspb.StringValue {
 			SourceType:   "*google.golang.org/protobuf/types/known/wrapperspb.StringValue",
 			TargetType:   "string",
 			Dependencies: []string{wrapperspbPkg},
+			Package:      protoconvPkg,
 			Body: `
 func ConvertStringValueToString(v *wrapperspb.StringValue) string {
 	if v == nil {
@@ -114,6 +131,7 @@ func ConvertStringValueToString(v *wrapperspb.StringValue) string {
 			SourceType:   "int32",
 			TargetType:   "*google.golang.org/protobuf/types/known/wrapperspb.Int32Value",
 			Dependencies: []string{wrapperspbPkg},
+			Package:      protoconvPkg,
 			Body: `
 func ConvertInt32ToInt32Value(i int32) *wrapperspb.Int32Value {
 	return wrapperspb.Int32(i)
@@ -124,6 +142,7 @@ func ConvertInt32ToInt32Value(i int32) *wrapperspb.Int32Value {
 			SourceType:   "*google.golang.org/protobuf/types/known/wrapperspb.Int32Value",
 			TargetType:   "int32",
 			Dependencies: []string{wrapperspbPkg},
+			Package:      protoconvPkg,
 			Body: `
 func ConvertInt32ValueToInt32(v *wrapperspb.Int32Value) int32 {
 	if v == nil {
@@ -138,6 +157,7 @@ func ConvertInt32ValueToInt32(v *wrapperspb.Int32Value) int32 {
 			SourceType:   "int64",
 			TargetType:   "*google.golang.org/protobuf/types/known/wrapperspb.Int64Value",
 			Dependencies: []string{wrapperspbPkg},
+			Package:      protoconvPkg,
 			Body: `
 func ConvertInt64ToInt64Value(i int64) *wrapperspb.Int64Value {
 	return wrapperspb.Int64(i)
@@ -148,6 +168,7 @@ func ConvertInt64ToInt64Value(i int64) *wrapperspb.Int64Value {
 			SourceType:   "*google.golang.org/protobuf/types/known/wrapperspb.Int64Value",
 			TargetType:   "int64",
 			Dependencies: []string{wrapperspbPkg},
+			Package:      protoconvPkg,
 			Body: `
 func ConvertInt64ValueToInt64(v *wrapperspb.Int64Value) int64 {
 	if v == nil {
@@ -162,6 +183,7 @@ func ConvertInt64ValueToInt64(v *wrapperspb.Int64Value) int64 {
 			SourceType:   "uint32",
 			TargetType:   "*google.golang.org/protobuf/types/known/wrapperspb.UInt32Value",
 			Dependencies: []string{wrapperspbPkg},
+			Package:      protoconvPkg,
 			Body: `
 func ConvertUInt32ToUInt32Value(i uint32) *wrapperspb.UInt32Value {
 	return wrapperspb.UInt32(i)
@@ -172,6 +194,7 @@ func ConvertUInt32ToUInt32Value(i uint32) *wrapperspb.UInt32Value {
 			SourceType:   "*google.golang.org/protobuf/types/known/wrapperspb.UInt32Value",
 			TargetType:   "uint32",
 			Dependencies: []string{wrapperspbPkg},
+			Package:      protoconvPkg,
 			Body: `
 func ConvertUInt32ValueToUInt32(v *wrapperspb.UInt32Value) uint32 {
 	if v == nil {
@@ -186,6 +209,7 @@ func ConvertUInt32ValueToUInt32(v *wrapperspb.UInt32Value) uint32 {
 			SourceType:   "uint64",
 			TargetType:   "*google.golang.org/protobuf/types/known/wrapperspb.UInt64Value",
 			Dependencies: []string{wrapperspbPkg},
+			Package:      protoconvPkg,
 			Body: `
 func ConvertUInt64ToUInt64Value(i uint64) *wrapperspb.UInt64Value {
 	return wrapperspb.UInt64(i)
@@ -196,6 +220,7 @@ func ConvertUInt64ToUInt64Value(i uint64) *wrapperspb.UInt64Value {
 			SourceType:   "*google.golang.org/protobuf/types/known/wrapperspb.UInt64Value",
 			TargetType:   "uint64",
 			Dependencies: []string{wrapperspbPkg},
+			Package:      protoconvPkg,
 			Body: `
 func ConvertUInt64ValueToUInt64(v *wrapperspb.UInt64Value) uint64 {
 	if v == nil {
@@ -210,6 +235,7 @@ func ConvertUInt64ValueToUInt64(v *wrapperspb.UInt64Value) uint64 {
 			SourceType:   "float32",
 			TargetType:   "*google.golang.org/protobuf/types/known/wrapperspb.FloatValue",
 			Dependencies: []string{wrapperspbPkg},
+			Package:      protoconvPkg,
 			Body: `
 func ConvertFloatToFloatValue(f float32) *wrapperspb.FloatValue {
 	return wrapperspb.Float(f)
@@ -220,6 +246,7 @@ func ConvertFloatToFloatValue(f float32) *wrapperspb.FloatValue {
 			SourceType:   "*google.golang.org/protobuf/types/known/wrapperspb.FloatValue",
 			TargetType:   "float32",
 			Dependencies: []string{wrapperspbPkg},
+			Package:      protoconvPkg,
 			Body: `
 func ConvertFloatValueToFloat(v *wrapperspb.FloatValue) float32 {
 	if v == nil {
@@ -234,6 +261,7 @@ func ConvertFloatValueToFloat(v *wrapperspb.FloatValue) float32 {
 			SourceType:   "float64",
 			TargetType:   "*google.golang.org/protobuf/types/known/wrapperspb.DoubleValue",
 			Dependencies: []string{wrapperspbPkg},
+			Package:      protoconvPkg,
 			Body: `
 func ConvertDoubleToDoubleValue(d float64) *wrapperspb.DoubleValue {
 	return wrapperspb.Double(d)
@@ -244,6 +272,7 @@ func ConvertDoubleToDoubleValue(d float64) *wrapperspb.DoubleValue {
 			SourceType:   "*google.golang.org/protobuf/types/known/wrapperspb.DoubleValue",
 			TargetType:   "float64",
 			Dependencies: []string{wrapperspbPkg},
+			Package:      protoconvPkg,
 			Body: `
 func ConvertDoubleValueToDouble(v *wrapperspb.DoubleValue) float64 {
 	if v == nil {
@@ -258,6 +287,7 @@ func ConvertDoubleValueToDouble(v *wrapperspb.DoubleValue) float64 {
 			SourceType:   "bool",
 			TargetType:   "*google.golang.org/protobuf/types/known/wrapperspb.BoolValue",
 			Dependencies: []string{wrapperspbPkg},
+			Package:      protoconvPkg,
 			Body: `
 func ConvertBoolToBoolValue(b bool) *wrapperspb.BoolValue {
 	return wrapperspb.Bool(b)
@@ -268,6 +298,7 @@ func ConvertBoolToBoolValue(b bool) *wrapperspb.BoolValue {
 			SourceType:   "*google.golang.org/protobuf/types/known/wrapperspb.BoolValue",
 			TargetType:   "bool",
 			Dependencies: []string{wrapperspbPkg},
+			Package:      protoconvPkg,
 			Body: `
 func ConvertBoolValueToBool(v *wrapperspb.BoolValue) bool {
 	if v == nil {
@@ -282,6 +313,7 @@ func ConvertBoolValueToBool(v *wrapperspb.BoolValue) bool {
 			SourceType:   "[]byte",
 			TargetType:   "*google.golang.org/protobuf/types/known/wrapperspb.BytesValue",
 			Dependencies: []string{wrapperspbPkg},
+			Package:      protoconvPkg,
 			Body: `
 func ConvertBytesToBytesValue(b []byte) *wrapperspb.BytesValue {
 	return wrapperspb.Bytes(b)
@@ -292,6 +324,7 @@ func ConvertBytesToBytesValue(b []byte) *wrapperspb.BytesValue {
 			SourceType:   "*google.golang.org/protobuf/types/known/wrapperspb.BytesValue",
 			TargetType:   "[]byte",
 			Dependencies: []string{wrapperspbPkg},
+			Package:      protoconvPkg,
 			Body: `
 func ConvertBytesValueToBytes(v *wrapperspb.BytesValue) []byte {
 	if v == nil {
@@ -306,6 +339,7 @@ func ConvertBytesValueToBytes(v *wrapperspb.BytesValue) []byte {
 			SourceType:   "time.Duration",
 			TargetType:   "*google.golang.org/protobuf/types/known/durationpb.Duration",
 			Dependencies: []string{timePkg, durationpbPkg},
+			Package:      protoconvPkg,
 			Body: `
 func ConvertDurationToDurationpb(d time.Duration) *durationpb.Duration {
 	return durationpb.New(d)
@@ -316,12 +350,326 @@ func ConvertDurationToDurationpb(d time.Duration) *durationpb.Duration {
 			SourceType:   "*google.golang.org/protobuf/types/known/durationpb.Duration",
 			TargetType:   "time.Duration",
 			Dependencies: []string{timePkg, durationpbPkg},
+			Package:      protoconvPkg,
 			Body: `
 func ConvertDurationpbToDuration(d *durationpb.Duration) time.Duration {
 	if d == nil {
 		return 0
 	}
 	return d.AsDuration()
+}`,
+		},
+		// map[string]any <-> *structpb.Struct
+		{
+			Name:         "ConvertMapToStruct",
+			SourceType:   "map[string]any",
+			TargetType:   "*google.golang.org/protobuf/types/known/structpb.Struct",
+			Dependencies: []string{structpbPkg},
+			Package:      protoconvPkg,
+			ReturnsError: true,
+			Body: `
+func ConvertMapToStruct(m map[string]any) (*structpb.Struct, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return structpb.NewStruct(m)
+}`,
+		},
+		{
+			Name:         "ConvertStructToMap",
+			SourceType:   "*google.golang.org/protobuf/types/known/structpb.Struct",
+			TargetType:   "map[string]any",
+			Dependencies: []string{structpbPkg},
+			Package:      protoconvPkg,
+			Body: `
+func ConvertStructToMap(s *structpb.Struct) map[string]any {
+	if s == nil {
+		return nil
+	}
+	return s.AsMap()
+}`,
+		},
+		// any <-> *structpb.Value
+		{
+			Name:         "ConvertAnyToValue",
+			SourceType:   "any",
+			TargetType:   "*google.golang.org/protobuf/types/known/structpb.Value",
+			Dependencies: []string{structpbPkg},
+			Package:      protoconvPkg,
+			ReturnsError: true,
+			Body: `
+func ConvertAnyToValue(v any) (*structpb.Value, error) {
+	return structpb.NewValue(v)
+}`,
+		},
+		{
+			Name:         "ConvertValueToAny",
+			SourceType:   "*google.golang.org/protobuf/types/known/structpb.Value",
+			TargetType:   "any",
+			Dependencies: []string{structpbPkg},
+			Package:      protoconvPkg,
+			Body: `
+func ConvertValueToAny(v *structpb.Value) any {
+	if v == nil {
+		return nil
+	}
+	return v.AsInterface()
+}`,
+		},
+		// []any <-> *structpb.ListValue
+		{
+			Name:         "ConvertSliceToListValue",
+			SourceType:   "[]any",
+			TargetType:   "*google.golang.org/protobuf/types/known/structpb.ListValue",
+			Dependencies: []string{structpbPkg},
+			Package:      protoconvPkg,
+			ReturnsError: true,
+			Body: `
+func ConvertSliceToListValue(l []any) (*structpb.ListValue, error) {
+	if l == nil {
+		return nil, nil
+	}
+	return structpb.NewList(l)
+}`,
+		},
+		{
+			Name:         "ConvertListValueToSlice",
+			SourceType:   "*google.golang.org/protobuf/types/known/structpb.ListValue",
+			TargetType:   "[]any",
+			Dependencies: []string{structpbPkg},
+			Package:      protoconvPkg,
+			Body: `
+func ConvertListValueToSlice(l *structpb.ListValue) []any {
+	if l == nil {
+		return nil
+	}
+	return l.AsSlice()
+}`,
+		},
+	}
+}
+
+// GetTimeHelpers returns the configurable time helpers. Unlike the built-in helpers they
+// take the layout, Unix unit and time zone as extra arguments, so they are not keyed by
+// type pair and are only called for time conversions.
+func GetTimeHelpers() []model.Helper {
+	return []model.Helper{
+		{
+			Name:         "loadLocation",
+			Dependencies: []string{syncPkg, timePkg},
+			Package:      runtimePkg,
+			Body: `
+var zoneLocations sync.Map
+
+func loadLocation(zone string) (*time.Location, error) {
+	if loc, ok := zoneLocations.Load(zone); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return nil, err
+	}
+	zoneLocations.Store(zone, loc)
+	return loc, nil
+}`,
+		},
+		{
+			Name:         "InZone",
+			Dependencies: []string{timePkg},
+			Requires:     []string{"loadLocation"},
+			Package:      runtimePkg,
+			Body: `
+func InZone(t time.Time, zone string) time.Time {
+	if zone == "" {
+		return t
+	}
+	loc, err := loadLocation(zone)
+	if err != nil {
+		return t
+	}
+	return t.In(loc)
+}`,
+		},
+		{
+			Name:         "InZonePtr",
+			Dependencies: []string{timePkg},
+			Requires:     []string{"InZone"},
+			Package:      runtimePkg,
+			Body: `
+func InZonePtr(t *time.Time, zone string) *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := InZone(*t, zone)
+	return &v
+}`,
+		},
+		{
+			Name:         "FormatTime",
+			Dependencies: []string{timePkg},
+			Requires:     []string{"InZone"},
+			Package:      runtimePkg,
+			Body: `
+func FormatTime(t time.Time, layout, zone string) string {
+	return InZone(t, zone).Format(layout)
+}`,
+		},
+		{
+			Name:         "FormatTimePtr",
+			Dependencies: []string{timePkg},
+			Requires:     []string{"FormatTime"},
+			Package:      runtimePkg,
+			Body: `
+func FormatTimePtr(t *time.Time, layout, zone string) string {
+	if t == nil {
+		return ""
+	}
+	return FormatTime(*t, layout, zone)
+}`,
+		},
+		{
+			Name:         "ParseTime",
+			Dependencies: []string{timePkg},
+			Requires:     []string{"loadLocation"},
+			Package:      runtimePkg,
+			Body: `
+func ParseTime(s, layout, zone string) (time.Time, error) {
+	if zone == "" {
+		return time.Parse(layout, s)
+	}
+	loc, err := loadLocation(zone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.ParseInLocation(layout, s, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(loc), nil
+}`,
+		},
+		{
+			Name:         "ParseTimePtr",
+			Dependencies: []string{timePkg},
+			Requires:     []string{"ParseTime"},
+			Package:      runtimePkg,
+			Body: `
+func ParseTimePtr(s, layout, zone string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := ParseTime(s, layout, zone)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}`,
+		},
+		{
+			Name:         "TimeToUnix",
+			Dependencies: []string{timePkg},
+			Package:      runtimePkg,
+			Body: `
+func TimeToUnix(t time.Time, unit string) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	switch unit {
+	case "ms":
+		return t.UnixMilli()
+	case "ns":
+		return t.UnixNano()
+	default:
+		return t.Unix()
+	}
+}`,
+		},
+		{
+			Name:         "TimePtrToUnix",
+			Dependencies: []string{timePkg},
+			Requires:     []string{"TimeToUnix"},
+			Package:      runtimePkg,
+			Body: `
+func TimePtrToUnix(t *time.Time, unit string) int64 {
+	if t == nil {
+		return 0
+	}
+	return TimeToUnix(*t, unit)
+}`,
+		},
+		{
+			Name:         "UnixToTime",
+			Dependencies: []string{timePkg},
+			Requires:     []string{"InZone"},
+			Package:      runtimePkg,
+			Body: `
+func UnixToTime(v int64, unit, zone string) time.Time {
+	if v == 0 {
+		return time.Time{}
+	}
+	var t time.Time
+	switch unit {
+	case "ms":
+		t = time.UnixMilli(v)
+	case "ns":
+		t = time.Unix(0, v)
+	default:
+		t = time.Unix(v, 0)
+	}
+	return InZone(t, zone)
+}`,
+		},
+		{
+			Name:         "UnixToTimePtr",
+			Dependencies: []string{timePkg},
+			Requires:     []string{"UnixToTime"},
+			Package:      runtimePkg,
+			Body: `
+func UnixToTimePtr(v int64, unit, zone string) *time.Time {
+	if v == 0 {
+		return nil
+	}
+	t := UnixToTime(v, unit, zone)
+	return &t
+}`,
+		},
+	}
+}
+
+// GetDiffHelpers returns the declarations used by generated Diff functions: the FieldDiff
+// type they return and NestDiffs, which prefixes the paths of diffs found in nested structs.
+func GetDiffHelpers() []model.Helper {
+	return []model.Helper{
+		{
+			Name:    "FieldDiff",
+			Package: runtimePkg,
+			Body: `
+type FieldDiff struct {
+	Path       string
+	TargetPath string
+	A          any
+	B          any
+}`,
+		},
+		{
+			Name:     "NestDiffs",
+			Requires: []string{"FieldDiff"},
+			Package:  runtimePkg,
+			Body: `
+func NestDiffs(path, targetPath string, diffs []FieldDiff) []FieldDiff {
+	for i := range diffs {
+		if diffs[i].Path == "" {
+			diffs[i].Path = path
+		} else {
+			diffs[i].Path = path + "." + diffs[i].Path
+		}
+		if diffs[i].TargetPath == "" {
+			diffs[i].TargetPath = targetPath
+		} else {
+			diffs[i].TargetPath = targetPath + "." + diffs[i].TargetPath
+		}
+	}
+	return diffs
 }`,
 		},
 	}
@@ -0,0 +1,114 @@
+package components
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runtimeDirs maps each runtime import path to its directory relative to this package.
+var runtimeDirs = map[string]string{
+	runtimePkg:   "../../../runtime",
+	uuidconvPkg:  "../../../runtime/uuidconv",
+	protoconvPkg: "../../../runtime/protoconv",
+}
+
+// TestBuiltInHelpers_MatchRuntime guards against the inline helper bodies drifting
+// from the functions exported by the runtime packages.
+func TestBuiltInHelpers_MatchRuntime(t *testing.T) {
+	runtimeFuncs := make(map[string]map[string]string)
+	for pkgPath, dir := range runtimeDirs {
+		runtimeFuncs[pkgPath] = parseRuntimeFuncs(t, dir)
+	}
+
+	for _, helper := range append(append(GetBuiltInHelpers(), GetTimeHelpers()...), GetDiffHelpers()...) {
+		t.Run(helper.Name, func(t *testing.T) {
+			funcs, ok := runtimeFuncs[helper.Package]
+			if !ok {
+				t.Fatalf("helper %s has unknown runtime package %q", helper.Name, helper.Package)
+			}
+			if _, ok := funcs[helper.Name]; !ok {
+				t.Fatalf("runtime package %s does not export %s", helper.Package, helper.Name)
+			}
+			got, err := format.Source([]byte("package p\n" + helper.Body))
+			if err != nil {
+				t.Fatalf("helper body does not parse: %v", err)
+			}
+			// A body may declare the variables of its function too, each matching its runtime
+			// declaration.
+			f, err := parser.ParseFile(token.NewFileSet(), "", got, 0)
+			if err != nil {
+				t.Fatalf("helper body does not parse: %v", err)
+			}
+			wants := make([]string, 0, len(f.Decls))
+			for _, decl := range f.Decls {
+				wants = append(wants, funcs[declName(decl)])
+			}
+			want := strings.Join(wants, "\n\n")
+			gotBody := strings.TrimSpace(strings.TrimPrefix(string(got), "package p\n"))
+			if gotBody != want {
+				t.Errorf("helper %s differs from runtime:\n--- inline ---\n%s\n--- runtime ---\n%s", helper.Name, gotBody, want)
+			}
+		})
+	}
+}
+
+// parseRuntimeFuncs returns the formatted declarations (without doc comments) of the
+// top-level functions, types and variables in dir, keyed by name.
+func parseRuntimeFuncs(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	funcs := make(map[string]string)
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatalf("parse %s: %v", file, err)
+		}
+		for _, decl := range f.Decls {
+			name := declName(decl)
+			if name == "" {
+				continue
+			}
+			var buf bytes.Buffer
+			if err := format.Node(&buf, fset, decl); err != nil {
+				t.Fatalf("format %s: %v", name, err)
+			}
+			funcs[name] = buf.String()
+		}
+	}
+	return funcs
+}
+
+// declName returns the name of a top-level function, or of a type or variable declared alone,
+// and an empty string for the other declarations.
+func declName(decl ast.Decl) string {
+	switch decl := decl.(type) {
+	case *ast.FuncDecl:
+		return decl.Name.Name
+	case *ast.GenDecl:
+		if len(decl.Specs) != 1 {
+			return ""
+		}
+		switch spec := decl.Specs[0].(type) {
+		case *ast.TypeSpec:
+			return spec.Name.Name
+		case *ast.ValueSpec:
+			if decl.Tok == token.VAR && len(spec.Names) == 1 {
+				return spec.Names[0].Name
+			}
+		}
+	}
+	return ""
+}
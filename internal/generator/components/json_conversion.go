@@ -0,0 +1,65 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/origadmin/abgen/internal/model"
+)
+
+const (
+	jsonPkg           = "encoding/json"
+	jsonRawMessageKey = jsonPkg + ".RawMessage"
+)
+
+// isJSONEncoded reports whether values of info hold encoded JSON: string, []byte or json.RawMessage.
+func isJSONEncoded(info *model.TypeInfo) bool {
+	switch info.UniqueKey() {
+	case "string", "[]byte", "[]uint8", jsonRawMessageKey:
+		return true
+	}
+	return false
+}
+
+// jsonConversion converts a field marked with convert:json between its decoded form, such
+// as a struct or map, and its encoded form. It reports false unless exactly one side is
+// encoded. Marshal and unmarshal errors are handled according to the error mode; empty
+// input decodes to the zero value.
+func (ce *ConversionEngine) jsonConversion(
+	sourceType, targetType *model.TypeInfo, sourceFieldExpr string,
+) (string, []string, bool) {
+	sourceEncoded, targetEncoded := isJSONEncoded(sourceType), isJSONEncoded(targetType)
+	if sourceEncoded == targetEncoded {
+		return "", nil, false
+	}
+	jsonAlias := ce.importManager.Add(jsonPkg)
+
+	if targetEncoded {
+		expr, preAssignments := ce.callExpression(jsonAlias+".Marshal", sourceFieldExpr, sourceFieldExpr, true)
+		if key := targetType.UniqueKey(); key != "[]byte" && key != "[]uint8" {
+			expr = fmt.Sprintf("%s(%s)", ce.typeFormatter.Format(targetType), expr)
+		}
+		return expr, preAssignments, true
+	}
+
+	data := sourceFieldExpr
+	if sourceType.UniqueKey() == "string" {
+		data = fmt.Sprintf("[]byte(%s)", sourceFieldExpr)
+	}
+	tempVarName := tempVarName("conv", sourceFieldExpr)
+	unmarshal := fmt.Sprintf("%s.Unmarshal(%s, &%s)", jsonAlias, data, tempVarName)
+
+	var buf strings.Builder
+	buf.WriteString(fmt.Sprintf("\tvar %s %s\n", tempVarName, ce.typeFormatter.Format(targetType)))
+	buf.WriteString(fmt.Sprintf("\tif len(%s) > 0 {\n", sourceFieldExpr))
+	if ce.propagatesErrors() {
+		ce.importManager.Add("fmt")
+		buf.WriteString(fmt.Sprintf("\t\tif err := %s; err != nil {\n", unmarshal))
+		buf.WriteString(fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"convert %s: %%w\", err)\n", strings.TrimPrefix(sourceFieldExpr, "from.")))
+		buf.WriteString("\t\t}\n")
+	} else {
+		buf.WriteString(fmt.Sprintf("\t\t_ = %s\n", unmarshal))
+	}
+	buf.WriteString("\t}")
+	return tempVarName, []string{buf.String()}, true
+}
@@ -0,0 +1,399 @@
+package components
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/origadmin/abgen/internal/config"
+	"github.com/origadmin/abgen/internal/model"
+)
+
+const strconvPkg = "strconv"
+
+// mapField pairs a struct field with the key it is stored under in a map.
+type mapField struct {
+	field *model.FieldInfo
+	key   string
+}
+
+// stringMapValue returns the value type of map[string]any and map[string]string, the map
+// types that structs can be converted to and from. It reports false for any other type.
+func stringMapValue(info *model.TypeInfo) (*model.TypeInfo, bool) {
+	m := getEffectiveTypeInfo(info)
+	if m == nil || m.Kind != model.Map || m.KeyType == nil || m.Underlying == nil ||
+		m.KeyType.Kind != model.Primitive || m.KeyType.Name != "string" {
+		return nil, false
+	}
+	switch value := m.Underlying; {
+	case isAnyType(value):
+		return value, true
+	case value.Kind == model.Primitive && value.Name == "string":
+		return value, true
+	}
+	return nil, false
+}
+
+// isAnyType reports whether info is the empty interface, spelled either interface{} or any.
+func isAnyType(info *model.TypeInfo) bool {
+	if info.Kind == model.Named && info.Name == "any" && info.ImportPath == "" {
+		info = info.Underlying
+	}
+	return info != nil && info.Kind == model.Interface && (info.Name == "interface{}" || info.Name == "any")
+}
+
+// isStructMapPair reports whether one side is a struct, or a pointer to one, and the other
+// a map[string]any or map[string]string.
+func isStructMapPair(source, target *model.TypeInfo) bool {
+	_, sourceIsMap := stringMapValue(source)
+	_, targetIsMap := stringMapValue(target)
+	return (targetIsMap && getConcreteType(source).Kind == model.Struct) ||
+		(sourceIsMap && getConcreteType(target).Kind == model.Struct)
+}
+
+// structMapConversion returns the expression converting a struct field to or from a map
+// field. Map to struct conversions always return an error, which is discarded or returned
+// according to the error mode. It reports false if the types are not a struct-map pair.
+func (ce *ConversionEngine) structMapConversion(
+	sourceType, targetType *model.TypeInfo, sourceFieldExpr string,
+) (string, *model.ConversionTask, []string, bool) {
+	if !isStructMapPair(sourceType, targetType) {
+		return "", nil, nil, false
+	}
+
+	if _, targetIsMap := stringMapValue(targetType); targetIsMap {
+		task := &model.ConversionTask{Source: derefType(sourceType), Target: targetType}
+		arg := sourceFieldExpr
+		if sourceType.Kind != model.Pointer {
+			arg = "&" + sourceFieldExpr
+		}
+		funcName := ce.nameGenerator.ConversionFunctionName(task.Source, task.Target)
+		expr, preAssignments := ce.callExpression(funcName, arg, sourceFieldExpr, ce.returnsErrors())
+		return expr, task, preAssignments, true
+	}
+
+	task := &model.ConversionTask{Source: sourceType, Target: derefType(targetType)}
+	funcName := ce.nameGenerator.ConversionFunctionName(task.Source, task.Target)
+	expr, preAssignments := ce.callExpression(funcName, sourceFieldExpr, sourceFieldExpr, true)
+	if targetType.Kind == model.Pointer {
+		return expr, task, preAssignments, true
+	}
+
+	// A nil map converts to a nil struct, which leaves the value field at its zero value.
+	// The call is fallible, so expr names the temporary variable holding its result.
+	mapVarName := tempVarName("map", sourceFieldExpr)
+	preAssignments = append(preAssignments, fmt.Sprintf("\tvar %s %s\n\tif %s != nil {\n\t\t%s = *%s\n\t}",
+		mapVarName, ce.typeFormatter.Format(targetType), expr, mapVarName, expr))
+	return mapVarName, task, preAssignments, true
+}
+
+// generateMapConversion generates a function converting a struct to a map[string]any or
+// map[string]string, or back. Nested structs of the analyzed packages are converted to
+// nested maps; other values are stored as they are, or formatted as strings for string maps.
+func (ce *ConversionEngine) generateMapConversion(
+	sourceInfo, targetInfo *model.TypeInfo, rule *config.ConversionRule,
+) (*model.GeneratedCode, []*model.ConversionTask, error) {
+	if _, targetIsMap := stringMapValue(targetInfo); targetIsMap {
+		return ce.generateStructToMapConversion(derefType(sourceInfo), targetInfo, rule)
+	}
+	return ce.generateMapToStructConversion(sourceInfo, derefType(targetInfo), rule)
+}
+
+func (ce *ConversionEngine) generateStructToMapConversion(
+	sourceInfo, targetInfo *model.TypeInfo, rule *config.ConversionRule,
+) (*model.GeneratedCode, []*model.ConversionTask, error) {
+	var buf strings.Builder
+	var entries []string
+	var preAssignments []string
+	var newTasks []*model.ConversionTask
+
+	funcName := ce.nameGenerator.ConversionFunctionName(sourceInfo, targetInfo)
+	sourceTypeStr := ce.typeFormatter.Format(sourceInfo)
+	targetTypeStr := ce.typeFormatter.Format(targetInfo)
+	valueType, _ := stringMapValue(targetInfo)
+
+	for _, mf := range ce.mapFields(sourceInfo, fieldRulesOf(rule)) {
+		fieldExpr := "from." + mf.field.Name
+		var valueExpr string
+		switch {
+		case !isAnyType(valueType):
+			expr, ok := ce.formatStringValue(mf.field.Type, fieldExpr)
+			if !ok {
+				slog.Debug("ConversionEngine: Skipping field that cannot be stored in a string map",
+					"type", sourceInfo.UniqueKey(), "field", mf.field.Name)
+				continue
+			}
+			valueExpr = expr
+		case ce.isNestedMapStruct(mf.field.Type):
+			nestedExpr, task, pre, _ := ce.structMapConversion(mf.field.Type, targetInfo, fieldExpr)
+			newTasks = append(newTasks, task)
+			preAssignments = append(preAssignments, pre...)
+			valueExpr = nestedExpr
+		default:
+			valueExpr = fieldExpr
+		}
+		entries = append(entries, fmt.Sprintf("\t\t%s: %s,", strconv.Quote(mf.key), valueExpr))
+	}
+
+	buf.WriteString(fmt.Sprintf("// %s converts %s to %s.\n", funcName, sourceTypeStr, targetTypeStr))
+	if ce.returnsErrors() {
+		buf.WriteString(fmt.Sprintf("func %s(from *%s) (%s, error) {\n", funcName, sourceTypeStr, targetTypeStr))
+		buf.WriteString("\tif from == nil {\n\t\treturn nil, nil\n\t}\n\n")
+	} else {
+		buf.WriteString(fmt.Sprintf("func %s(from *%s) %s {\n", funcName, sourceTypeStr, targetTypeStr))
+		buf.WriteString("\tif from == nil {\n\t\treturn nil\n\t}\n\n")
+	}
+	for _, preAssignment := range preAssignments {
+		buf.WriteString(preAssignment + "\n")
+	}
+	if len(preAssignments) > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString(fmt.Sprintf("\tto := %s{\n", targetTypeStr))
+	for _, entry := range entries {
+		buf.WriteString(entry + "\n")
+	}
+	buf.WriteString("\t}\n")
+	if ce.returnsErrors() {
+		buf.WriteString("\treturn to, nil\n")
+	} else {
+		buf.WriteString("\treturn to\n")
+	}
+	buf.WriteString("}\n\n")
+
+	return &model.GeneratedCode{FunctionBody: buf.String()}, newTasks, nil
+}
+
+func (ce *ConversionEngine) generateMapToStructConversion(
+	sourceInfo, targetInfo *model.TypeInfo, rule *config.ConversionRule,
+) (*model.GeneratedCode, []*model.ConversionTask, error) {
+	var buf strings.Builder
+	var newTasks []*model.ConversionTask
+
+	funcName := ce.nameGenerator.ConversionFunctionName(sourceInfo, targetInfo)
+	sourceTypeStr := ce.typeFormatter.Format(sourceInfo)
+	targetTypeStr := ce.typeFormatter.Format(targetInfo)
+	valueType, _ := stringMapValue(sourceInfo)
+
+	buf.WriteString(fmt.Sprintf("// %s converts %s to %s.\n", funcName, sourceTypeStr, targetTypeStr))
+	buf.WriteString(fmt.Sprintf("func %s(from %s) (*%s, error) {\n", funcName, sourceTypeStr, targetTypeStr))
+	buf.WriteString("\tif from == nil {\n\t\treturn nil, nil\n\t}\n\n")
+	buf.WriteString(fmt.Sprintf("\tto := &%s{}\n", targetTypeStr))
+
+	// The fields are stored under the keys of the conversion from the struct to the map.
+	rules := fieldRulesOf(rule)
+	for _, mf := range ce.mapFields(targetInfo, config.FieldRuleSet{Ignore: rules.Ignore, Remap: rules.Reverse().Remap}) {
+		var block string
+		switch {
+		case !isAnyType(valueType):
+			b, ok := ce.parseStringValue(mf)
+			if !ok {
+				slog.Debug("ConversionEngine: Skipping field that cannot be read from a string map",
+					"type", targetInfo.UniqueKey(), "field", mf.field.Name)
+				continue
+			}
+			block = b
+		case ce.isNestedMapStruct(mf.field.Type):
+			task := &model.ConversionTask{Source: sourceInfo, Target: derefType(mf.field.Type)}
+			newTasks = append(newTasks, task)
+			block = ce.nestedMapBlock(mf, sourceTypeStr, ce.nameGenerator.ConversionFunctionName(task.Source, task.Target))
+		default:
+			block = ce.assertValueBlock(mf)
+		}
+		buf.WriteString(block)
+	}
+
+	buf.WriteString("\treturn to, nil\n")
+	buf.WriteString("}\n\n")
+
+	return &model.GeneratedCode{FunctionBody: buf.String()}, newTasks, nil
+}
+
+// mapFields returns the fields of a struct that are stored in maps, with their keys. The
+// rules are those of the conversion from the struct to the map, matched as by
+// model.PairFields: a field remapped by them is stored under the key it is remapped to, and
+// the other fields whose key it takes, or that are ignored by their name or key, are left
+// out, as are fields tagged json:"-" under the json key strategy.
+func (ce *ConversionEngine) mapFields(structInfo *model.TypeInfo, rules config.FieldRuleSet) []mapField {
+	remappedTo := make(map[string]bool, len(rules.Remap))
+	for _, to := range rules.Remap {
+		remappedTo[to] = true
+	}
+	ignored := func(name string) bool {
+		_, ok := rules.Ignore[name]
+		return ok
+	}
+	var fields []mapField
+	for _, field := range getConcreteType(structInfo).Fields {
+		if ignored(field.Name) {
+			continue
+		}
+		if key, remapped := rules.Remap[field.Name]; remapped {
+			if !ignored(key) {
+				fields = append(fields, mapField{field: field, key: key})
+			}
+			continue
+		}
+		key := field.Name
+		if ce.cfg.GlobalBehaviorRules.MapKey == config.MapKeyJSON {
+			tag := reflect.StructTag(field.Tag).Get("json")
+			if tag == "-" {
+				continue
+			}
+			if name, _, _ := strings.Cut(tag, ","); name != "" {
+				key = name
+			}
+		}
+		if ignored(key) || remappedTo[key] {
+			continue
+		}
+		fields = append(fields, mapField{field: field, key: key})
+	}
+	return fields
+}
+
+// isNestedMapStruct reports whether a field of type info is converted to a nested map: a
+// struct, or pointer to one, declared in the analyzed packages outside the standard library.
+// Other structs, such as time.Time, are stored as they are.
+func (ce *ConversionEngine) isNestedMapStruct(info *model.TypeInfo) bool {
+	info = derefType(info)
+	return info.Kind == model.Struct && ce.typeInfos[info.FQN()] != nil && !isStandardLibrary(info.ImportPath)
+}
+
+// assertValueBlock reads a map[string]any entry into a field with a type assertion.
+func (ce *ConversionEngine) assertValueBlock(mf mapField) string {
+	fieldTypeStr := ce.typeFormatter.Format(mf.field.Type)
+	var buf strings.Builder
+	buf.WriteString(fmt.Sprintf("\tif v, ok := from[%s]; ok && v != nil {\n", strconv.Quote(mf.key)))
+	buf.WriteString(fmt.Sprintf("\t\tvalue, ok := v.(%s)\n", fieldTypeStr))
+	buf.WriteString("\t\tif !ok {\n")
+	buf.WriteString(ce.mapKeyError(mf.key, fmt.Sprintf("got %%T, want %s", fieldTypeStr), "v"))
+	buf.WriteString("\t\t}\n")
+	buf.WriteString(fmt.Sprintf("\t\tto.%s = value\n", mf.field.Name))
+	buf.WriteString("\t}\n")
+	return buf.String()
+}
+
+// nestedMapBlock reads a nested map[string]any entry into a struct field.
+func (ce *ConversionEngine) nestedMapBlock(mf mapField, mapTypeStr, funcName string) string {
+	var buf strings.Builder
+	buf.WriteString(fmt.Sprintf("\tif v, ok := from[%s]; ok && v != nil {\n", strconv.Quote(mf.key)))
+	buf.WriteString(fmt.Sprintf("\t\tm, ok := v.(%s)\n", mapTypeStr))
+	buf.WriteString("\t\tif !ok {\n")
+	buf.WriteString(ce.mapKeyError(mf.key, fmt.Sprintf("got %%T, want %s", mapTypeStr), "v"))
+	buf.WriteString("\t\t}\n")
+	buf.WriteString(fmt.Sprintf("\t\tvalue, err := %s(m)\n", funcName))
+	buf.WriteString("\t\tif err != nil {\n")
+	buf.WriteString(ce.mapKeyError(mf.key, "%w", "err"))
+	buf.WriteString("\t\t}\n")
+	if mf.field.Type.Kind == model.Pointer {
+		buf.WriteString(fmt.Sprintf("\t\tto.%s = value\n", mf.field.Name))
+	} else {
+		buf.WriteString(fmt.Sprintf("\t\tif value != nil {\n\t\t\tto.%s = *value\n\t\t}\n", mf.field.Name))
+	}
+	buf.WriteString("\t}\n")
+	return buf.String()
+}
+
+// parseStringValue reads a map[string]string entry into a string, numeric or boolean field.
+func (ce *ConversionEngine) parseStringValue(mf mapField) (string, bool) {
+	info := getEffectiveTypeInfo(mf.field.Type)
+	if info == nil || info.Kind != model.Primitive {
+		return "", false
+	}
+
+	var parse, result string
+	switch info.Name {
+	case "string":
+		value := "v"
+		if mf.field.Type.Kind == model.Named {
+			value = fmt.Sprintf("%s(v)", ce.typeFormatter.Format(mf.field.Type))
+		}
+		return fmt.Sprintf("\tif v, ok := from[%s]; ok {\n\t\tto.%s = %s\n\t}\n", strconv.Quote(mf.key), mf.field.Name, value), true
+	case "int", "int8", "int16", "int32", "int64", "rune":
+		parse, result = fmt.Sprintf("ParseInt(v, 10, %d)", bitSize(info.Name)), "int64"
+	case "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte":
+		parse, result = fmt.Sprintf("ParseUint(v, 10, %d)", bitSize(info.Name)), "uint64"
+	case "float32", "float64":
+		parse, result = fmt.Sprintf("ParseFloat(v, %d)", bitSize(info.Name)), "float64"
+	case "bool":
+		parse, result = "ParseBool(v)", "bool"
+	default:
+		return "", false
+	}
+
+	value := "value"
+	if fieldTypeStr := ce.typeFormatter.Format(mf.field.Type); fieldTypeStr != result {
+		value = fmt.Sprintf("%s(value)", fieldTypeStr)
+	}
+	var buf strings.Builder
+	buf.WriteString(fmt.Sprintf("\tif v, ok := from[%s]; ok {\n", strconv.Quote(mf.key)))
+	buf.WriteString(fmt.Sprintf("\t\tvalue, err := %s.%s\n", ce.importManager.Add(strconvPkg), parse))
+	buf.WriteString("\t\tif err != nil {\n")
+	buf.WriteString(ce.mapKeyError(mf.key, "%w", "err"))
+	buf.WriteString("\t\t}\n")
+	buf.WriteString(fmt.Sprintf("\t\tto.%s = %s\n", mf.field.Name, value))
+	buf.WriteString("\t}\n")
+	return buf.String(), true
+}
+
+// formatStringValue returns the expression formatting a string, numeric or boolean field
+// for a map[string]string.
+func (ce *ConversionEngine) formatStringValue(fieldType *model.TypeInfo, fieldExpr string) (string, bool) {
+	info := getEffectiveTypeInfo(fieldType)
+	if info == nil || info.Kind != model.Primitive {
+		return "", false
+	}
+	named := fieldType.Kind == model.Named
+
+	switch info.Name {
+	case "string":
+		if named {
+			return fmt.Sprintf("string(%s)", fieldExpr), true
+		}
+		return fieldExpr, true
+	case "int", "int8", "int16", "int32", "int64", "rune":
+		return fmt.Sprintf("%s.FormatInt(int64(%s), 10)", ce.importManager.Add(strconvPkg), fieldExpr), true
+	case "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte":
+		return fmt.Sprintf("%s.FormatUint(uint64(%s), 10)", ce.importManager.Add(strconvPkg), fieldExpr), true
+	case "float32", "float64":
+		return fmt.Sprintf("%s.FormatFloat(float64(%s), 'g', -1, %d)", ce.importManager.Add(strconvPkg), fieldExpr, bitSize(info.Name)), true
+	case "bool":
+		if named {
+			fieldExpr = fmt.Sprintf("bool(%s)", fieldExpr)
+		}
+		return fmt.Sprintf("%s.FormatBool(%s)", ce.importManager.Add(strconvPkg), fieldExpr), true
+	}
+	return "", false
+}
+
+// mapKeyError returns the statement returning an error for the entry stored under key.
+func (ce *ConversionEngine) mapKeyError(key, format, arg string) string {
+	ce.importManager.Add("fmt")
+	return fmt.Sprintf("\t\t\treturn nil, fmt.Errorf(\"convert key %%q: %s\", %s, %s)\n", format, strconv.Quote(key), arg)
+}
+
+// bitSize returns the bit size argument of the strconv functions for a basic type name.
+func bitSize(name string) int {
+	switch name {
+	case "int8", "uint8", "byte":
+		return 8
+	case "int16", "uint16":
+		return 16
+	case "int32", "uint32", "rune", "float32":
+		return 32
+	case "int64", "uint64", "float64", "uintptr":
+		return 64
+	}
+	return 0
+}
+
+// derefType returns the type a pointer points to, or info itself if it is not a pointer.
+func derefType(info *model.TypeInfo) *model.TypeInfo {
+	if info != nil && info.Kind == model.Pointer && info.Underlying != nil {
+		return info.Underlying
+	}
+	return info
+}
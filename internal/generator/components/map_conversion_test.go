@@ -0,0 +1,76 @@
+package components
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/origadmin/abgen/internal/config"
+	"github.com/origadmin/abgen/internal/model"
+)
+
+func TestStringMapValue(t *testing.T) {
+	stringType := newPrimitive("string")
+	anyAlias := &model.TypeInfo{Kind: model.Named, Name: "any", Underlying: &model.TypeInfo{Kind: model.Interface, Name: "interface{}"}}
+
+	tests := []struct {
+		name   string
+		info   *model.TypeInfo
+		wantOK bool
+	}{
+		{name: "map[string]interface{}", info: model.AnyMapType(), wantOK: true},
+		{name: "map[string]any", info: &model.TypeInfo{Kind: model.Map, KeyType: stringType, Underlying: anyAlias}, wantOK: true},
+		{name: "map[string]string", info: &model.TypeInfo{Kind: model.Map, KeyType: stringType, Underlying: stringType}, wantOK: true},
+		{name: "map[int]string", info: &model.TypeInfo{Kind: model.Map, KeyType: newPrimitive("int"), Underlying: stringType}},
+		{name: "map[string]int", info: &model.TypeInfo{Kind: model.Map, KeyType: stringType, Underlying: newPrimitive("int")}},
+		{name: "pointer to map", info: newPointer(model.AnyMapType())},
+		{name: "struct", info: newStruct("User", "a/b", nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := stringMapValue(tt.info); ok != tt.wantOK {
+				t.Errorf("stringMapValue() ok = %v, want %v", ok, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestMapFields(t *testing.T) {
+	user := newStruct("User", "a/b", []*model.FieldInfo{
+		{Name: "ID", Type: newPrimitive("int64"), Tag: `json:"id"`},
+		{Name: "Name", Type: newPrimitive("string"), Tag: `json:"name,omitempty"`},
+		{Name: "Password", Type: newPrimitive("string"), Tag: `json:"-"`},
+		{Name: "Note", Type: newPrimitive("string"), Tag: `json:",omitempty"`},
+		{Name: "Internal", Type: newPrimitive("string")},
+		{Name: "Email", Type: newPrimitive("string"), Tag: `json:"email"`},
+		{Name: "Contact", Type: newPrimitive("string"), Tag: `json:"contact"`},
+	})
+	rules := config.FieldRuleSet{
+		Ignore: map[string]struct{}{"Internal": {}},
+		Remap:  map[string]string{"Email": "contact"},
+	}
+
+	tests := []struct {
+		strategy config.MapKeyStrategy
+		want     []string
+	}{
+		{strategy: config.MapKeyField, want: []string{"ID", "Name", "Password", "Note", "contact", "Contact"}},
+		{strategy: config.MapKeyJSON, want: []string{"id", "name", "Note", "contact"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			cfg := config.NewConfig()
+			cfg.GlobalBehaviorRules.MapKey = tt.strategy
+			ce := &ConversionEngine{cfg: cfg}
+
+			var got []string
+			for _, mf := range ce.mapFields(user, rules) {
+				got = append(got, mf.key)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("mapFields() keys = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
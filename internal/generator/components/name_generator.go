@@ -1,8 +1,13 @@
 package components
 
 import (
+	"errors"
 	"fmt"
-	"unicode"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+	"text/template"
 
 	"github.com/origadmin/abgen/internal/model"
 )
@@ -10,30 +15,196 @@ import (
 // NameGenerator implements the model.NameGenerator interface.
 type NameGenerator struct {
 	aliasManager model.AliasManager
+	naming       model.NamingStrategy
+	plan         *model.ExecutionPlan
+
+	// funcName is the convert:func:name template, if any. The source->target keys of the
+	// active rules and the packages of their source and target types tell the direction of
+	// a conversion.
+	funcName       *template.Template
+	ruleKeys       map[string]bool
+	sourcePackages map[string]bool
+	targetPackages map[string]bool
+
+	// names maps each conversion function name handed out to the conversion it names, and
+	// errs collects the names that could not be generated or name several conversions.
+	names map[string]string
+	errs  map[string]string
+}
+
+// funcNameData is the data the convert:func:name template is executed with.
+type funcNameData struct {
+	Source    string
+	Target    string
+	SourcePkg string
+	TargetPkg string
+	Kind      string
+	Direction string
 }
 
-// NewNameGenerator creates a new name generator that depends on an alias manager.
-func NewNameGenerator(aliasManager model.AliasManager) model.NameGenerator {
-	return &NameGenerator{
-		aliasManager: aliasManager,
+// NewNameGenerator creates a new name generator that depends on an alias manager and on the
+// naming strategy the aliases were created with. The plan tells which conversions are
+// generated as methods and how functions are named; it may be nil.
+func NewNameGenerator(aliasManager model.AliasManager, naming model.NamingStrategy, plan *model.ExecutionPlan) model.NameGenerator {
+	n := &NameGenerator{
+		aliasManager:   aliasManager,
+		naming:         naming,
+		plan:           plan,
+		ruleKeys:       make(map[string]bool),
+		sourcePackages: make(map[string]bool),
+		targetPackages: make(map[string]bool),
+		names:          make(map[string]string),
+		errs:           make(map[string]string),
+	}
+	if plan == nil {
+		return n
+	}
+	for _, rule := range plan.ActiveRules {
+		n.ruleKeys[rule.SourceType+"->"+rule.TargetType] = true
+		if i := strings.LastIndex(rule.SourceType, "."); i > 0 {
+			n.sourcePackages[rule.SourceType[:i]] = true
+		}
+		if i := strings.LastIndex(rule.TargetType, "."); i > 0 {
+			n.targetPackages[rule.TargetType[:i]] = true
+		}
 	}
+	if text := plan.FinalConfig.NamingRules.FuncName; text != "" {
+		tmpl, err := template.New("convert:func:name").Option("missingkey=error").Parse(text)
+		if err != nil {
+			n.errs["convert:func:name"] = fmt.Sprintf("invalid convert:func:name template: %v", err)
+		} else {
+			n.funcName = tmpl
+		}
+	}
+	return n
 }
 
 // ConversionFunctionName returns a standardized name for a function that converts between two types.
+// A conversion between identical types is a deep copy and is named by DeepCopyFunctionName.
+// A conversion generated as a To method is called through its method expression, such as
+// (*UserDTO).ToPB, which takes the receiver as its argument.
 func (n *NameGenerator) ConversionFunctionName(source, target *model.TypeInfo) string {
+	if source.UniqueKey() == target.UniqueKey() {
+		return n.DeepCopyFunctionName(source)
+	}
+	if method := n.plan.ConversionMethod(source, target); method != nil && !method.From {
+		return fmt.Sprintf("(*%s).%s", derefType(source).Name, method.Name)
+	}
 	sourceName := n.getCleanBaseName(source)
 	targetName := n.getCleanBaseName(target)
-	return fmt.Sprintf("Convert%sTo%s", sourceName, targetName)
+	name := fmt.Sprintf("Convert%sTo%s", sourceName, targetName)
+	if n.funcName != nil {
+		name = n.templateName(source, target, sourceName, targetName, name)
+	}
+	n.claim(name, derefType(source).UniqueKey()+"->"+derefType(target).UniqueKey())
+	return name
+}
+
+// templateName executes the convert:func:name template for a conversion. A template that
+// fails or does not produce an identifier is reported, and the default name is used instead.
+func (n *NameGenerator) templateName(source, target *model.TypeInfo, sourceName, targetName, defaultName string) string {
+	sourceElem, targetElem := elementType(source), elementType(target)
+	data := funcNameData{
+		Source:    sourceName,
+		Target:    targetName,
+		SourcePkg: packageName(sourceElem),
+		TargetPkg: packageName(targetElem),
+		Kind:      conversionKind(source, target),
+		Direction: n.direction(sourceElem, targetElem),
+	}
+
+	var buf strings.Builder
+	if err := n.funcName.Execute(&buf, data); err != nil {
+		n.errs[defaultName] = fmt.Sprintf("convert:func:name failed for %s: %v", defaultName, err)
+		return defaultName
+	}
+	name := strings.TrimSpace(buf.String())
+	if !token.IsIdentifier(name) {
+		n.errs[defaultName] = fmt.Sprintf("convert:func:name gives %q for %s, which is not an identifier", name, defaultName)
+		return defaultName
+	}
+	return name
+}
+
+// direction returns reverse for a conversion opposite to a rule, and forward otherwise.
+// A conversion without a rule, such as the stub converting a field, is reverse when it
+// converts from the target packages of the rules to their source packages.
+func (n *NameGenerator) direction(source, target *model.TypeInfo) string {
+	forwardKey := source.UniqueKey() + "->" + target.UniqueKey()
+	reverseKey := target.UniqueKey() + "->" + source.UniqueKey()
+	switch {
+	case n.ruleKeys[forwardKey]:
+		return "forward"
+	case n.ruleKeys[reverseKey]:
+		return "reverse"
+	case n.sourcePackages[source.ImportPath] || n.targetPackages[target.ImportPath]:
+		return "forward"
+	case n.targetPackages[source.ImportPath] || n.sourcePackages[target.ImportPath]:
+		return "reverse"
+	}
+	return "forward"
+}
+
+// claim records that name is the function converting the conversion identified by key, and
+// reports a name already given to another conversion.
+func (n *NameGenerator) claim(name, key string) {
+	if claimed, ok := n.names[name]; !ok {
+		n.names[name] = key
+	} else if claimed != key {
+		n.errs[name] = fmt.Sprintf("function name %s is generated for both %s and %s", name, claimed, key)
+	}
+}
+
+// Err reports the conversion function names that could not be generated and the names
+// generated for more than one conversion. The generator checks it before emitting any code.
+func (n *NameGenerator) Err() error {
+	keys := make([]string, 0, len(n.errs))
+	for key := range n.errs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	errs := make([]error, 0, len(keys))
+	for _, key := range keys {
+		errs = append(errs, errors.New(n.errs[key]))
+	}
+	return errors.Join(errs...)
 }
 
 // FieldConversionFunctionName returns a standardized name for a function that converts a specific field.
 func (n *NameGenerator) FieldConversionFunctionName(sourceParent, targetParent *model.TypeInfo, sourceField, targetField *model.FieldInfo) string {
 	sourceParentName := n.getCleanBaseName(sourceParent)
 	targetParentName := n.getCleanBaseName(targetParent)
-	fieldName := n.capitalize(sourceField.Name)
+	fieldName := n.naming.CamelCase(sourceField.Name)
 	return fmt.Sprintf("Convert%s%sTo%s%s", sourceParentName, fieldName, targetParentName, fieldName)
 }
 
+// DeepCopyFunctionName returns the name of the function that deep copies values of a type.
+// Structs and pointers to them share a function; other pointer types get a Ptr suffix.
+func (n *NameGenerator) DeepCopyFunctionName(info *model.TypeInfo) string {
+	if info.Kind == model.Pointer && info.Underlying != nil && !info.Underlying.IsUltimatelyStruct() {
+		return fmt.Sprintf("DeepCopy%sPtr", n.getCleanBaseName(info.Underlying))
+	}
+	return fmt.Sprintf("DeepCopy%s", n.getCleanBaseName(info))
+}
+
+// EqualFunctionName returns the name of the function reporting whether a source and a
+// target value hold the same data.
+func (n *NameGenerator) EqualFunctionName(source, target *model.TypeInfo) string {
+	return fmt.Sprintf("Equal%sAnd%s", n.getCleanBaseName(source), n.getCleanBaseName(target))
+}
+
+// DiffFunctionName returns the name of the function listing the fields that differ between
+// a source and a target value.
+func (n *NameGenerator) DiffFunctionName(source, target *model.TypeInfo) string {
+	return fmt.Sprintf("Diff%sAnd%s", n.getCleanBaseName(source), n.getCleanBaseName(target))
+}
+
+// RoundTripTestName returns the name, without its Test or Fuzz prefix, of the tests
+// converting a source value to the target type and back.
+func (n *NameGenerator) RoundTripTestName(source, target *model.TypeInfo) string {
+	return fmt.Sprintf("RoundTrip%sAnd%s", n.getCleanBaseName(source), n.getCleanBaseName(target))
+}
+
 // getCleanBaseName finds the authoritative name for a type.
 // It prioritizes looking up a pre-computed alias from the AliasManager.
 // If no alias is found, it constructs a name from the type's structure.
@@ -44,7 +215,7 @@ func (n *NameGenerator) getCleanBaseName(info *model.TypeInfo) string {
 
 	// The AliasManager is the source of truth for all managed types.
 	if alias, ok := n.aliasManager.LookupAlias(info.UniqueKey()); ok {
-		return n.capitalize(alias)
+		return n.naming.CamelCase(alias)
 	}
 
 	// Fallback for unmanaged types (e.g., primitives, time.Time, etc.)
@@ -53,11 +224,18 @@ func (n *NameGenerator) getCleanBaseName(info *model.TypeInfo) string {
 	case model.Pointer:
 		return n.getCleanBaseName(info.Underlying)
 	case model.Slice:
-		elemName := n.getCleanBaseName(info.Underlying)
-		baseName = elemName + "s"
+		baseName = n.naming.Plural(n.getCleanBaseName(info.Underlying))
 	case model.Array:
 		baseName = n.getCleanBaseName(info.Underlying) + "Array"
 	case model.Map:
+		// String-keyed maps are named after their values: map[string]any is a Map and
+		// map[string]string a StringMap.
+		if info.KeyType != nil && info.KeyType.Kind == model.Primitive && info.KeyType.Name == "string" {
+			if info.Underlying != nil && isAnyType(info.Underlying) {
+				return "Map"
+			}
+			return n.getCleanBaseName(info.Underlying) + "Map"
+		}
 		keyName := n.getCleanBaseName(info.KeyType)
 		valName := n.getCleanBaseName(info.Underlying)
 		baseName = fmt.Sprintf("%sTo%sMap", keyName, valName)
@@ -69,14 +247,47 @@ func (n *NameGenerator) getCleanBaseName(info *model.TypeInfo) string {
 		baseName = "Object"
 	}
 
-	return n.capitalize(baseName)
+	return n.naming.CamelCase(baseName)
+}
+
+// elementType returns the type a conversion of info is about: the struct of a pointer, or
+// the elements of a slice, array or map.
+func elementType(info *model.TypeInfo) *model.TypeInfo {
+	for info.Underlying != nil {
+		switch info.Kind {
+		case model.Pointer, model.Slice, model.Array, model.Map:
+			info = info.Underlying
+		default:
+			return info
+		}
+	}
+	return info
 }
 
-func (n *NameGenerator) capitalize(s string) string {
-	if s == "" {
+// packageName returns the name of the package declaring info, or an empty string for
+// predeclared and unnamed types.
+func packageName(info *model.TypeInfo) string {
+	if obj, ok := info.Original.(*types.TypeName); ok && obj.Pkg() != nil {
+		return obj.Pkg().Name()
+	}
+	if info.ImportPath == "" {
 		return ""
 	}
-	runes := []rune(s)
-	runes[0] = unicode.ToUpper(runes[0])
-	return string(runes)
+	return info.ImportPath[strings.LastIndex(info.ImportPath, "/")+1:]
+}
+
+// conversionKind returns the kind of a conversion for the convert:func:name template:
+// struct, slice or map conversions, or stub for the functions left to the user.
+func conversionKind(source, target *model.TypeInfo) string {
+	concreteSource, concreteTarget := getConcreteType(source), getConcreteType(target)
+	switch {
+	case concreteSource.Kind == model.Struct && concreteTarget.Kind == model.Struct:
+		return "struct"
+	case concreteSource.Kind == model.Slice && concreteTarget.Kind == model.Slice:
+		return "slice"
+	case concreteSource.Kind == model.Map || concreteTarget.Kind == model.Map:
+		return "map"
+	default:
+		return "stub"
+	}
 }
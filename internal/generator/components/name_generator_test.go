@@ -1,8 +1,11 @@
 package components
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/origadmin/abgen/internal/config"
+
 	"github.com/origadmin/abgen/internal/model"
 )
 
@@ -48,7 +51,7 @@ func TestNameGenerator_ConversionFunctionName(t *testing.T) {
 		},
 	}
 
-	ng := NewNameGenerator(mockAM)
+	ng := NewNameGenerator(mockAM, NewNamingStrategy(config.NamingRules{}), nil)
 
 	testCases := []struct {
 		name     string
@@ -59,6 +62,13 @@ func TestNameGenerator_ConversionFunctionName(t *testing.T) {
 		{"Primitives (unmanaged)", intType, stringType, "ConvertIntToString"},
 		{"Structs with Alias", userStruct, userDTOStruct, "ConvertUserSourceToUserTarget"},
 		{"Slices of Pointers with Alias", pointerUserSlice, pointerUserDTOSlice, "ConvertUsersSourceToUsersTarget"},
+		{"Struct to map[string]any", userStruct, model.AnyMapType(), "ConvertUserSourceToMap"},
+		{"map[string]string to Struct", &model.TypeInfo{Kind: model.Map, KeyType: stringType, Underlying: stringType}, userStruct, "ConvertStringMapToUserSource"},
+		{"Identical Structs", userStruct, userStruct, "DeepCopyUserSource"},
+		{"Identical Pointers to Structs", newPointer(userStruct), newPointer(userStruct), "DeepCopyUserSource"},
+		{"Identical Pointers to Primitives", newPointer(intType), newPointer(intType), "DeepCopyIntPtr"},
+		{"Identical Slices", newSlice(stringType), newSlice(stringType), "DeepCopyStrings"},
+		{"Int-keyed Map", &model.TypeInfo{Kind: model.Map, KeyType: intType, Underlying: stringType}, stringType, "ConvertIntToStringMapToString"},
 	}
 
 	for _, tt := range testCases {
@@ -70,3 +80,86 @@ func TestNameGenerator_ConversionFunctionName(t *testing.T) {
 		})
 	}
 }
+
+func TestNameGenerator_PairFunctionNames(t *testing.T) {
+	userStruct := newStruct("User", "a/b", nil)
+	userPBStruct := newStruct("UserPB", "c/d", nil)
+	ng := NewNameGenerator(&mockAliasManager{aliasMap: map[string]string{"a/b.User": "User"}}, NewNamingStrategy(config.NamingRules{}), nil)
+
+	if got := ng.EqualFunctionName(userStruct, userPBStruct); got != "EqualUserAndUserPB" {
+		t.Errorf("EqualFunctionName() = %v, want EqualUserAndUserPB", got)
+	}
+	if got := ng.DiffFunctionName(newPointer(userStruct), userPBStruct); got != "DiffUserAndUserPB" {
+		t.Errorf("DiffFunctionName() = %v, want DiffUserAndUserPB", got)
+	}
+	if got := ng.RoundTripTestName(userStruct, userPBStruct); got != "RoundTripUserAndUserPB" {
+		t.Errorf("RoundTripTestName() = %v, want RoundTripUserAndUserPB", got)
+	}
+}
+
+func TestNameGenerator_FuncNameTemplate(t *testing.T) {
+	userStruct := newStruct("User", "a/ent", nil)
+	userPBStruct := newStruct("UserPB", "c/pb", nil)
+	statusType := &model.TypeInfo{Name: "Status", ImportPath: "a/ent", Kind: model.Named, Underlying: newPrimitive("int")}
+	stringType := newPrimitive("string")
+
+	newGenerator := func(text string) model.NameGenerator {
+		cfg := config.NewConfig()
+		cfg.NamingRules.FuncName = text
+		plan := &model.ExecutionPlan{
+			FinalConfig: cfg,
+			ActiveRules: []*config.ConversionRule{{SourceType: "a/ent.User", TargetType: "c/pb.UserPB"}},
+		}
+		return NewNameGenerator(&mockAliasManager{}, NewNamingStrategy(cfg.NamingRules), plan)
+	}
+
+	ng := newGenerator(`{{.SourcePkg}}{{.Source}}To{{.TargetPkg}}{{.Target}}{{.Kind}}{{.Direction}}`)
+	testCases := []struct {
+		name     string
+		source   *model.TypeInfo
+		target   *model.TypeInfo
+		expected string
+	}{
+		{"Struct", userStruct, userPBStruct, "entUserTopbUserPBstructforward"},
+		{"Reverse Struct", newPointer(userPBStruct), userStruct, "pbUserPBToentUserstructreverse"},
+		{"Slice", newSlice(newPointer(userStruct)), newSlice(newPointer(userPBStruct)), "entUsersTopbUserPBssliceforward"},
+		{"Map", userStruct, model.AnyMapType(), "entUserToMapmapforward"},
+		{"Stub", statusType, stringType, "entStatusToStringstubforward"},
+		{"Reverse Stub", stringType, statusType, "StringToentStatusstubreverse"},
+		{"Identical Structs", userStruct, userStruct, "DeepCopyUser"},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ng.ConversionFunctionName(tt.source, tt.target); got != tt.expected {
+				t.Errorf("ConversionFunctionName() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+	if err := ng.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+
+	t.Run("Collision", func(t *testing.T) {
+		ng := newGenerator(`Convert{{.Kind}}`)
+		ng.ConversionFunctionName(userStruct, userPBStruct)
+		ng.ConversionFunctionName(newPointer(userStruct), userPBStruct)
+		if err := ng.Err(); err != nil {
+			t.Fatalf("Err() = %v for pointer and value variants of one conversion", err)
+		}
+		ng.ConversionFunctionName(userPBStruct, userStruct)
+		err := ng.Err()
+		if err == nil || !strings.Contains(err.Error(), "Convertstruct is generated for both") {
+			t.Errorf("Err() = %v, want a collision of Convertstruct", err)
+		}
+	})
+
+	t.Run("Not an Identifier", func(t *testing.T) {
+		ng := newGenerator(`{{.Source}}-{{.Target}}`)
+		if got := ng.ConversionFunctionName(userStruct, userPBStruct); got != "ConvertUserToUserPB" {
+			t.Errorf("ConversionFunctionName() = %v, want the default name", got)
+		}
+		if err := ng.Err(); err == nil || !strings.Contains(err.Error(), "not an identifier") {
+			t.Errorf("Err() = %v, want an identifier error", err)
+		}
+	})
+}
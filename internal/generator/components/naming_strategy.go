@@ -0,0 +1,155 @@
+package components
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/origadmin/abgen/internal/config"
+	"github.com/origadmin/abgen/internal/model"
+)
+
+var _ model.NamingStrategy = (*NamingStrategy)(nil)
+
+// defaultAcronyms are the words written in upper case in generated names unless configured
+// otherwise with naming:acronyms.
+var defaultAcronyms = []string{"API", "HTTP", "ID", "JSON", "SQL", "URI", "URL", "UUID"}
+
+// irregularPlurals are the English nouns not pluralized by the suffix rules of Plural.
+var irregularPlurals = map[string]string{
+	"Child":  "Children",
+	"Foot":   "Feet",
+	"Goose":  "Geese",
+	"Half":   "Halves",
+	"Knife":  "Knives",
+	"Leaf":   "Leaves",
+	"Life":   "Lives",
+	"Man":    "Men",
+	"Mouse":  "Mice",
+	"Ox":     "Oxen",
+	"Person": "People",
+	"Tooth":  "Teeth",
+	"Wife":   "Wives",
+	"Woman":  "Women",
+}
+
+// uncountableNouns are the nouns whose plural is the noun itself. Slices of them are named
+// with a List suffix, so that the name differs from the name of their element.
+var uncountableNouns = map[string]struct{}{
+	"Data":        {},
+	"Equipment":   {},
+	"Info":        {},
+	"Information": {},
+	"Metadata":    {},
+	"News":        {},
+	"Series":      {},
+	"Sheep":       {},
+	"Species":     {},
+}
+
+// NamingStrategy implements the model.NamingStrategy interface. It is shared by the alias
+// manager and the name generator, so aliases and function names use the same words.
+type NamingStrategy struct {
+	acronyms map[string]string
+	plurals  map[string]string
+}
+
+// NewNamingStrategy creates a naming strategy from the naming rules. The configured acronyms
+// replace the default ones, and the configured plurals take precedence over the built-in rules.
+func NewNamingStrategy(rules config.NamingRules) model.NamingStrategy {
+	acronyms := rules.Acronyms
+	if acronyms == nil {
+		acronyms = defaultAcronyms
+	}
+	s := &NamingStrategy{
+		acronyms: make(map[string]string, len(acronyms)),
+		plurals:  make(map[string]string, len(rules.Plurals)),
+	}
+	for _, acronym := range acronyms {
+		s.acronyms[strings.ToLower(acronym)] = acronym
+	}
+	for singular, plural := range rules.Plurals {
+		s.plurals[singular] = plural
+	}
+	return s
+}
+
+// CamelCase joins the words of name, separated by any non-alphanumeric characters, into a
+// single exported identifier: user_id becomes UserID. Acronyms are written as configured,
+// and the other words are capitalized.
+func (s *NamingStrategy) CamelCase(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		if acronym, ok := s.acronyms[strings.ToLower(word)]; ok {
+			words[i] = acronym
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, "")
+}
+
+// Plural returns the plural of a CamelCase name by pluralizing its last word: Category
+// becomes Categories, UserPerson UserPeople and UserID UserIDs.
+func (s *NamingStrategy) Plural(name string) string {
+	if name == "" {
+		return ""
+	}
+	if plural, ok := s.plurals[name]; ok {
+		return plural
+	}
+	head, word := splitLastWord(name)
+	if plural, ok := s.plurals[word]; ok {
+		return head + plural
+	}
+	if plural, ok := irregularPlurals[word]; ok {
+		return head + plural
+	}
+	if _, ok := uncountableNouns[word]; ok {
+		return name + "List"
+	}
+	if isUpper(word) {
+		return name + "s"
+	}
+
+	lower := strings.ToLower(word)
+	switch {
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return name[:len(name)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return name + "es"
+	}
+	return name + "s"
+}
+
+// splitLastWord splits a CamelCase name before its last word. A trailing run of upper case
+// letters and digits, such as the ID of UserID, is a word of its own.
+func splitLastWord(name string) (string, string) {
+	runes := []rune(name)
+	i := len(runes)
+	if unicode.IsUpper(runes[i-1]) {
+		for i > 0 && (unicode.IsUpper(runes[i-1]) || unicode.IsDigit(runes[i-1])) {
+			i--
+		}
+		return string(runes[:i]), string(runes[i:])
+	}
+	for i = len(runes) - 1; i > 0 && !unicode.IsUpper(runes[i]); i-- {
+	}
+	return string(runes[:i]), string(runes[i:])
+}
+
+// isUpper reports whether word has letters and all of them are in upper case.
+func isUpper(word string) bool {
+	hasLetter := false
+	for _, r := range word {
+		if unicode.IsLower(r) {
+			return false
+		}
+		hasLetter = hasLetter || unicode.IsLetter(r)
+	}
+	return hasLetter
+}
@@ -0,0 +1,69 @@
+package components
+
+import (
+	"testing"
+
+	"github.com/origadmin/abgen/internal/config"
+)
+
+func TestNamingStrategy_Plural(t *testing.T) {
+	naming := NewNamingStrategy(config.NamingRules{
+		Plurals: map[string]string{"Criterion": "Criteria", "UserStatus": "UserStatusList"},
+	})
+
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{"User", "Users"},
+		{"Category", "Categories"},
+		{"Key", "Keys"},
+		{"Status", "Statuses"},
+		{"Box", "Boxes"},
+		{"Match", "Matches"},
+		{"Person", "People"},
+		{"UserPerson", "UserPeople"},
+		{"Child", "Children"},
+		{"UserID", "UserIDs"},
+		{"URL", "URLs"},
+		{"Metadata", "MetadataList"},
+		{"UserInfo", "UserInfoList"},
+		{"Int64", "Int64s"},
+		{"Criterion", "Criteria"},
+		{"SearchCriterion", "SearchCriteria"},
+		{"UserStatus", "UserStatusList"},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := naming.Plural(tt.name); got != tt.expected {
+				t.Errorf("Plural(%q) = %v, want %v", tt.name, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNamingStrategy_CamelCase(t *testing.T) {
+	testCases := []struct {
+		name     string
+		acronyms []string
+		input    string
+		expected string
+	}{
+		{"Identifier", nil, "User", "User"},
+		{"Snake Case", nil, "user_id", "UserID"},
+		{"Default Acronyms", nil, "http_url", "HTTPURL"},
+		{"Single Acronym", nil, "id", "ID"},
+		{"Camel Case Unchanged", nil, "userId", "UserId"},
+		{"Configured Acronyms", []string{"PB"}, "pb", "PB"},
+		{"Configured Acronyms Replace Defaults", []string{"PB"}, "user_id", "UserId"},
+		{"No Acronyms", []string{}, "id", "Id"},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			naming := NewNamingStrategy(config.NamingRules{Acronyms: tt.acronyms})
+			if got := naming.CamelCase(tt.input); got != tt.expected {
+				t.Errorf("CamelCase(%q) = %v, want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package components
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+
+	"github.com/origadmin/abgen/internal/config"
+	"github.com/origadmin/abgen/internal/model"
+)
+
+const testfillPkg = runtimePkg + "/testfill"
+
+// roundTripSeeds declares the inputs shared by the round-trip tests and the seed corpus of
+// the fuzz targets.
+const roundTripSeeds = `// roundTripSeeds are the inputs of the round-trip tests and the seed corpus of the fuzz targets.
+var roundTripSeeds = [][]byte{
+	nil,
+	[]byte("abgen"),
+	[]byte("\x01\x02\x03\x04\x05\x06\x07\x08\x09\x0a\x0b\x0c\x0d\x0e\x0f\x10\x11\x12\x13\x14\x15\x16\x17\x18"),
+	[]byte("\xff\xfe\xfd\xfc\xfb\xfa\xf9\xf8\xf7\xf6\xf5\xf4\xf3\xf2\xf1\xf0\xef\xee\xed\xec\xeb\xea\xe9\xe8"),
+}
+
+`
+
+// RoundTripTestGenerator implements the model.TestGenerator interface.
+type RoundTripTestGenerator struct {
+	nameGenerator   model.NameGenerator
+	typeFormatter   model.TypeFormatter
+	importManager   model.ImportManager
+	conversionFuncs map[string]*model.ConversionFunc
+	helperFuncs     map[string]*model.ConversionFunc
+	customFuncs     map[string]*model.ConversionFunc
+	fieldRules      map[string]config.FieldRuleSet
+	cfg             *config.Config
+}
+
+// NewRoundTripTestGenerator creates a test generator that writes the types it references
+// with typeFormatter and records the packages the tests import in importManager.
+func NewRoundTripTestGenerator(
+	analysisResult *model.AnalysisResult,
+	nameGenerator model.NameGenerator,
+	typeFormatter model.TypeFormatter,
+	importManager model.ImportManager,
+) model.TestGenerator {
+	g := &RoundTripTestGenerator{
+		nameGenerator:   nameGenerator,
+		typeFormatter:   typeFormatter,
+		importManager:   importManager,
+		conversionFuncs: analysisResult.ConversionFuncs,
+		helperFuncs:     analysisResult.HelperFuncs,
+		customFuncs:     analysisResult.CustomFuncs,
+		fieldRules:      make(map[string]config.FieldRuleSet),
+		cfg:             analysisResult.ExecutionPlan.FinalConfig,
+	}
+	for _, rule := range analysisResult.ExecutionPlan.ActiveRules {
+		g.fieldRules[rule.SourceType+"->"+rule.TargetType] = rule.FieldRules
+	}
+	return g
+}
+
+// GenerateRoundTripTests generates a round-trip test and a fuzz target for each rule. They
+// fill a source value from their input, convert it to the target type and back, and check
+// the fields that survive the round trip: fields that are ignored, only paired in one
+// direction, converted by user functions or converted with a possible loss of information
+// are left out.
+func (g *RoundTripTestGenerator) GenerateRoundTripTests(rules []*config.ConversionRule, typeInfos map[string]*model.TypeInfo) ([]string, error) {
+	var decls []string
+	for _, rule := range rules {
+		source, target := typeInfos[rule.SourceType], typeInfos[rule.TargetType]
+		if source == nil || target == nil || rule.Direction != config.DirectionBoth ||
+			!source.IsUltimatelyStruct() || !target.IsUltimatelyStruct() || source.UniqueKey() == target.UniqueKey() {
+			continue
+		}
+		decls = append(decls, g.generateRoundTripTest(source, target, rule.FieldRules))
+	}
+	if len(decls) == 0 {
+		return nil, nil
+	}
+	return append([]string{roundTripSeeds}, decls...), nil
+}
+
+func (g *RoundTripTestGenerator) generateRoundTripTest(source, target *model.TypeInfo, rules config.FieldRuleSet) string {
+	var buf strings.Builder
+	name := g.nameGenerator.RoundTripTestName(source, target)
+	sourceTypeStr := g.typeFormatter.Format(source)
+	targetTypeStr := g.typeFormatter.Format(target)
+	forwardFunc := g.nameGenerator.ConversionFunctionName(source, target)
+	reverseFunc := g.nameGenerator.ConversionFunctionName(target, source)
+	testfill := g.importManager.Add(testfillPkg)
+
+	buf.WriteString(fmt.Sprintf("// Test%s checks that %s values converted to %s and back keep their fields.\n", name, sourceTypeStr, targetTypeStr))
+	buf.WriteString(fmt.Sprintf("func Test%s(t *testing.T) {\n", name))
+	buf.WriteString("\tfor _, seed := range roundTripSeeds {\n")
+	buf.WriteString(fmt.Sprintf("\t\tcheck%s(t, seed)\n", name))
+	buf.WriteString("\t}\n")
+	buf.WriteString("}\n\n")
+
+	buf.WriteString(fmt.Sprintf("// Fuzz%s checks the round trip of %s values filled from fuzz input.\n", name, sourceTypeStr))
+	buf.WriteString(fmt.Sprintf("func Fuzz%s(f *testing.F) {\n", name))
+	buf.WriteString("\tfor _, seed := range roundTripSeeds {\n")
+	buf.WriteString("\t\tf.Add(seed)\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString(fmt.Sprintf("\tf.Fuzz(check%s)\n", name))
+	buf.WriteString("}\n\n")
+
+	buf.WriteString(fmt.Sprintf("func check%s(t *testing.T, data []byte) {\n", name))
+	buf.WriteString(fmt.Sprintf("\tvar from %s\n", sourceTypeStr))
+	buf.WriteString(fmt.Sprintf("\t%s.Fill(&from, data)\n\n", testfill))
+	if g.cfg.GlobalBehaviorRules.ErrorMode == config.ErrorModeReturn {
+		// Filled values may be invalid for the conversion, but its result must convert back.
+		buf.WriteString(fmt.Sprintf("\tto, err := %s(&from)\n", forwardFunc))
+		buf.WriteString(fmt.Sprintf("\tif err != nil {\n\t\tt.Skipf(\"%s: %%v\", err)\n\t}\n", forwardFunc))
+		buf.WriteString(fmt.Sprintf("\tback, err := %s(to)\n", reverseFunc))
+		buf.WriteString(fmt.Sprintf("\tif err != nil {\n\t\tt.Fatalf(\"%s: %%v\", err)\n\t}\n", reverseFunc))
+	} else {
+		buf.WriteString(fmt.Sprintf("\tback := %s(%s(&from))\n", reverseFunc, forwardFunc))
+	}
+	buf.WriteString("\tif back == nil {\n\t\tt.Fatal(\"round trip returned nil\")\n\t}\n")
+	for _, field := range g.roundTripFields(source, target, rules) {
+		got, want := "back."+field.Name, "from."+field.Name
+		buf.WriteString(fmt.Sprintf("\tif %s {\n", g.differs(field.Type, got, want)))
+		buf.WriteString(fmt.Sprintf("\t\tt.Errorf(\"%s = %%v after the round trip, want %%v\", %s, %s)\n", field.Name, got, want))
+		buf.WriteString("\t}\n")
+	}
+	buf.WriteString("}\n\n")
+	return buf.String()
+}
+
+// roundTripFields returns the fields of source that are preserved when converting to
+// target and back: they are paired with the same target field in both directions and
+// converted without loss.
+func (g *RoundTripTestGenerator) roundTripFields(source, target *model.TypeInfo, rules config.FieldRuleSet) []*model.FieldInfo {
+	backPairs := make(map[*model.FieldInfo]*model.FieldPair)
+	for _, pair := range model.PairFields(target, source, rules.Reverse()) {
+		backPairs[pair.Source] = pair
+	}
+
+	var fields []*model.FieldInfo
+	for _, pair := range model.PairFields(source, target, rules) {
+		back := backPairs[pair.Target]
+		if back == nil || back.Target != pair.Source || pair.SourcePath != pair.Source.Name || back.SourcePath != pair.Target.Name {
+			continue
+		}
+		zoned := g.zoned(source, pair.Source, target, pair.Target)
+		// Top-level times are compared with Equal, which ignores their location.
+		if pair.Source.Type.UniqueKey() == timeTypeKey && pair.Target.Type.UniqueKey() == timeTypeKey ||
+			g.lossless(pair.Source.Type, pair.Target.Type, zoned, make(map[string]bool)) {
+			fields = append(fields, pair.Source)
+		}
+	}
+	return fields
+}
+
+// zoned reports whether times converted between the fields are normalized to a time zone.
+func (g *RoundTripTestGenerator) zoned(source *model.TypeInfo, sourceField *model.FieldInfo, target *model.TypeInfo, targetField *model.FieldInfo) bool {
+	sourceKey := fieldOptionKey(source, sourceField.Name)
+	targetKey := fieldOptionKey(target, targetField.Name)
+	return g.cfg.TimeOptionsFor(targetKey, sourceKey).Zone != "" || g.cfg.TimeOptionsFor(sourceKey, targetKey).Zone != ""
+}
+
+// lossless reports whether values of type source are unchanged by a conversion to target
+// and back: identical types, values converted to pointers, widening numeric conversions,
+// and slices and structs of such values. Structs must preserve all their exported fields.
+func (g *RoundTripTestGenerator) lossless(source, target *model.TypeInfo, zoned bool, visiting map[string]bool) bool {
+	if source.UniqueKey() == target.UniqueKey() {
+		key := derefType(source).UniqueKey()
+		return !zoned || key != timeTypeKey
+	}
+	key := source.UniqueKey() + "->" + target.UniqueKey()
+	if g.conversionFuncs[key] != nil || g.helperFuncs[key] != nil || g.customFuncs[key] != nil {
+		return false
+	}
+
+	if target.Kind == model.Pointer && source.Kind != model.Pointer && !source.IsUltimatelyStruct() {
+		return target.Underlying.UniqueKey() == source.UniqueKey()
+	}
+
+	effectiveSource, effectiveTarget := getEffectiveTypeInfo(source), getEffectiveTypeInfo(target)
+	switch {
+	case effectiveSource.Kind == model.Primitive && effectiveTarget.Kind == model.Primitive:
+		return widens(effectiveSource.Name, effectiveTarget.Name)
+	case effectiveSource.Kind == model.Slice && effectiveTarget.Kind == model.Slice:
+		return g.lossless(effectiveSource.Underlying, effectiveTarget.Underlying, zoned, visiting)
+	}
+
+	if (source.Kind == model.Pointer) != (target.Kind == model.Pointer) {
+		return false
+	}
+	sourceStruct, targetStruct := derefType(source), derefType(target)
+	if !sourceStruct.IsUltimatelyStruct() || !targetStruct.IsUltimatelyStruct() ||
+		sourceStruct.Name == "" || targetStruct.Name == "" ||
+		isStandardLibrary(sourceStruct.ImportPath) || isStandardLibrary(targetStruct.ImportPath) {
+		return false
+	}
+	pairKey := sourceStruct.UniqueKey() + "->" + targetStruct.UniqueKey()
+	if visiting[pairKey] {
+		return true
+	}
+	visiting[pairKey] = true
+
+	preserved := make(map[*model.FieldInfo]bool)
+	rules := g.fieldRules[pairKey]
+	backPairs := make(map[*model.FieldInfo]*model.FieldPair)
+	for _, pair := range model.PairFields(targetStruct, sourceStruct, rules.Reverse()) {
+		backPairs[pair.Source] = pair
+	}
+	for _, pair := range model.PairFields(sourceStruct, targetStruct, rules) {
+		back := backPairs[pair.Target]
+		if back != nil && back.Target == pair.Source && pair.SourcePath == pair.Source.Name && back.SourcePath == pair.Target.Name &&
+			g.lossless(pair.Source.Type, pair.Target.Type, g.zoned(sourceStruct, pair.Source, targetStruct, pair.Target), visiting) {
+			preserved[pair.Source] = true
+		}
+	}
+	for _, field := range getConcreteType(sourceStruct).Fields {
+		if token.IsExported(field.Name) && !preserved[field] {
+			return false
+		}
+	}
+	return true
+}
+
+// differs returns the condition under which got and want, both of type info, differ.
+func (g *RoundTripTestGenerator) differs(info *model.TypeInfo, got, want string) string {
+	if getEffectiveTypeInfo(info).Kind == model.Primitive {
+		return fmt.Sprintf("%s != %s", got, want)
+	}
+	if info.UniqueKey() == timeTypeKey {
+		return fmt.Sprintf("!%s.Equal(%s)", got, want)
+	}
+	return fmt.Sprintf("!%s.DeepEqual(%s, %s)", g.importManager.Add("reflect"), got, want)
+}
+
+// numericRanges describes the numeric types by kind and size, with int and uint taken as
+// 64 bits wide. Floats have the size of their mantissa, which bounds the integers they
+// represent exactly.
+var numericRanges = map[string]struct {
+	kind byte
+	bits int
+}{
+	"int8": {'i', 8}, "int16": {'i', 16}, "int32": {'i', 32}, "rune": {'i', 32}, "int64": {'i', 64}, "int": {'i', 64},
+	"uint8": {'u', 8}, "byte": {'u', 8}, "uint16": {'u', 16}, "uint32": {'u', 32}, "uint64": {'u', 64}, "uint": {'u', 64},
+	"float32": {'f', 24}, "float64": {'f', 53},
+}
+
+// widens reports whether every value of the numeric type source is represented exactly by
+// the numeric type target.
+func widens(source, target string) bool {
+	from, ok := numericRanges[source]
+	if !ok {
+		return false
+	}
+	to, ok := numericRanges[target]
+	if !ok {
+		return false
+	}
+	switch {
+	case from.kind == 'f':
+		return to.kind == 'f' && to.bits >= from.bits
+	case to.kind == 'f':
+		return to.bits >= from.bits
+	case from.kind == to.kind:
+		return to.bits >= from.bits
+	case from.kind == 'u':
+		return to.bits > from.bits
+	}
+	return false
+}
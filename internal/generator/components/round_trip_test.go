@@ -0,0 +1,29 @@
+package components
+
+import "testing"
+
+func TestWidens(t *testing.T) {
+	testCases := []struct {
+		source, target string
+		want           bool
+	}{
+		{"int32", "int64", true},
+		{"int64", "int32", false},
+		{"int", "int64", true},
+		{"uint8", "int16", true},
+		{"uint16", "int16", false},
+		{"int8", "uint64", false},
+		{"float32", "float64", true},
+		{"float64", "float32", false},
+		{"int16", "float32", true},
+		{"int32", "float32", false},
+		{"int32", "float64", true},
+		{"float64", "int64", false},
+		{"string", "int64", false},
+	}
+	for _, tc := range testCases {
+		if got := widens(tc.source, tc.target); got != tc.want {
+			t.Errorf("widens(%s, %s) = %v, want %v", tc.source, tc.target, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/origadmin/abgen/internal/model"
+)
+
+const sqlPkg = "database/sql"
+
+// sqlNullValueField returns the value field of a database/sql nullable type, such as
+// String for sql.NullString or V for the generic sql.Null[T].
+func sqlNullValueField(info *model.TypeInfo) (*model.FieldInfo, bool) {
+	if info == nil || info.Kind != model.Struct || info.ImportPath != sqlPkg ||
+		!strings.HasPrefix(info.Name, "Null") || len(info.Fields) != 2 {
+		return nil, false
+	}
+	switch {
+	case info.Fields[1].Name == "Valid":
+		return info.Fields[0], true
+	case info.Fields[0].Name == "Valid":
+		return info.Fields[1], true
+	default:
+		return nil, false
+	}
+}
+
+// sqlNullConversion converts between database/sql nullable types and plain values or
+// pointers, converting the wrapped value with the regular conversion rules. An invalid
+// value maps to nil or the zero value; a nil pointer maps to an invalid value and any
+// plain value maps to a valid one. It reports false if neither side is a nullable type.
+func (ce *ConversionEngine) sqlNullConversion(
+	sourceType, targetType *model.TypeInfo, sourceFieldExpr string, opts fieldOptions,
+) (string, []model.Helper, []*model.ConversionTask, []string, bool) {
+	sourceValue, sourceIsNull := sqlNullValueField(sourceType)
+	targetValue, targetIsNull := sqlNullValueField(targetType)
+	if !sourceIsNull && !targetIsNull {
+		return "", nil, nil, nil, false
+	}
+
+	nullVarName := tempVarName("null", sourceFieldExpr)
+	targetTypeStr := ce.typeFormatter.Format(targetType)
+
+	var validExpr, valueExpr string
+	innerSource := sourceType
+	innerTarget := targetType
+	switch {
+	case sourceIsNull:
+		validExpr = sourceFieldExpr + ".Valid"
+		valueExpr = sourceFieldExpr + "." + sourceValue.Name
+		innerSource = sourceValue.Type
+	case sourceType.Kind == model.Pointer:
+		validExpr = sourceFieldExpr + " != nil"
+		valueExpr = "*" + sourceFieldExpr
+		innerSource = sourceType.Underlying
+	default:
+		valueExpr = sourceFieldExpr
+	}
+	if targetIsNull {
+		innerTarget = targetValue.Type
+	} else if targetType.Kind == model.Pointer {
+		innerTarget = targetType.Underlying
+	}
+
+	// The wrapped value is converted with the regular rules. Its temporary variables use
+	// other prefixes, so they do not clash with nullVarName.
+	innerExprRoot := valueExpr
+	if strings.HasPrefix(valueExpr, "*") {
+		innerExprRoot = "(" + valueExpr + ")"
+	}
+	innerExpr, helpers, tasks, innerPre := ce.getConversionExpression(innerSource, innerTarget, innerExprRoot, opts)
+	if innerExpr == innerExprRoot {
+		innerExpr = valueExpr
+	}
+
+	var value string
+	switch {
+	case targetIsNull:
+		value = fmt.Sprintf("%s{%s: %s, Valid: true}", targetTypeStr, targetValue.Name, innerExpr)
+		if sourceIsNull {
+			value = fmt.Sprintf("%s{%s: %s, Valid: %s}", targetTypeStr, targetValue.Name, innerExpr, validExpr)
+			validExpr = ""
+		}
+	case targetType.Kind == model.Pointer:
+		value = "&v"
+		innerPre = append(innerPre, fmt.Sprintf("\tv := %s", innerExpr))
+	default:
+		value = innerExpr
+	}
+
+	if validExpr == "" {
+		return value, helpers, tasks, innerPre, true
+	}
+
+	var buf strings.Builder
+	buf.WriteString(fmt.Sprintf("\tvar %s %s\n", nullVarName, targetTypeStr))
+	buf.WriteString(fmt.Sprintf("\tif %s {\n", validExpr))
+	for _, pre := range innerPre {
+		buf.WriteString("\t" + strings.ReplaceAll(pre, "\n", "\n\t") + "\n")
+	}
+	buf.WriteString(fmt.Sprintf("\t\t%s = %s\n", nullVarName, value))
+	buf.WriteString("\t}")
+	return nullVarName, helpers, tasks, []string{buf.String()}, true
+}
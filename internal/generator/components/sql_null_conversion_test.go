@@ -0,0 +1,52 @@
+package components
+
+import (
+	"testing"
+
+	"github.com/origadmin/abgen/internal/model"
+)
+
+func TestSQLNullValueField(t *testing.T) {
+	newNullType := func(name, valueField string, valueType *model.TypeInfo) *model.TypeInfo {
+		return &model.TypeInfo{
+			Kind:       model.Struct,
+			Name:       name,
+			ImportPath: sqlPkg,
+			Fields: []*model.FieldInfo{
+				{Name: valueField, Type: valueType},
+				{Name: "Valid", Type: newPrimitive("bool")},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		info      *model.TypeInfo
+		wantField string
+		wantOK    bool
+	}{
+		{name: "NullString", info: newNullType("NullString", "String", newPrimitive("string")), wantField: "String", wantOK: true},
+		{name: "generic Null", info: newNullType("Null", "V", newPrimitive("int64")), wantField: "V", wantOK: true},
+		{name: "pointer to NullString", info: newPointer(newNullType("NullString", "String", newPrimitive("string")))},
+		{name: "other sql struct", info: &model.TypeInfo{Kind: model.Struct, Name: "DB", ImportPath: sqlPkg}},
+		{name: "Null type from another package", info: &model.TypeInfo{
+			Kind:       model.Struct,
+			Name:       "NullString",
+			ImportPath: "github.com/acme/db",
+			Fields:     newNullType("NullString", "String", newPrimitive("string")).Fields,
+		}},
+		{name: "primitive", info: newPrimitive("string")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field, ok := sqlNullValueField(tt.info)
+			if ok != tt.wantOK {
+				t.Fatalf("sqlNullValueField() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && field.Name != tt.wantField {
+				t.Errorf("sqlNullValueField() field = %s, want %s", field.Name, tt.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package components
+
+import (
+	"strconv"
+
+	"github.com/origadmin/abgen/internal/config"
+	"github.com/origadmin/abgen/internal/model"
+)
+
+const (
+	timeTypeKey    = "time.Time"
+	timePtrTypeKey = "*time.Time"
+	defaultLayout  = "RFC3339"
+	defaultUnit    = "s"
+)
+
+// timeLayoutConstants lists the layout constants of the time package that may be named
+// in convert:time:layout. Any other value is used as a literal layout.
+var timeLayoutConstants = map[string]bool{
+	"Layout": true, "ANSIC": true, "UnixDate": true, "RubyDate": true,
+	"RFC822": true, "RFC822Z": true, "RFC850": true, "RFC1123": true, "RFC1123Z": true,
+	"RFC3339": true, "RFC3339Nano": true, "Kitchen": true,
+	"Stamp": true, "StampMilli": true, "StampMicro": true, "StampNano": true,
+	"DateTime": true, "DateOnly": true, "TimeOnly": true,
+}
+
+// timeConversion returns the expression converting between time.Time or *time.Time and
+// string or int64, honoring the layout, Unix unit and zone in opts. It reports false for
+// other pairs, and for time.Time -> string without a layout or zone, which keeps using the
+// built-in RFC 3339 helper. Strings are parsed as RFC 3339 by default, returning their errors.
+func (ce *ConversionEngine) timeConversion(
+	sourceType, targetType *model.TypeInfo, sourceFieldExpr string, opts config.TimeOptions,
+) (string, []model.Helper, []string, bool) {
+	source, target := sourceType.UniqueKey(), targetType.UniqueKey()
+	configured := opts.Layout != "" || opts.Zone != ""
+
+	var helperName string
+	var args []string
+	fallible := false
+	switch {
+	case source == timeTypeKey && target == "string" && configured:
+		helperName, args = "FormatTime", []string{ce.timeLayout(opts), strconv.Quote(opts.Zone)}
+	case source == timePtrTypeKey && target == "string":
+		helperName, args = "FormatTimePtr", []string{ce.timeLayout(opts), strconv.Quote(opts.Zone)}
+	case source == "string" && target == timeTypeKey:
+		helperName, args, fallible = "ParseTime", []string{ce.timeLayout(opts), strconv.Quote(opts.Zone)}, true
+	case source == "string" && target == timePtrTypeKey:
+		helperName, args, fallible = "ParseTimePtr", []string{ce.timeLayout(opts), strconv.Quote(opts.Zone)}, true
+	case source == timeTypeKey && target == "int64":
+		helperName, args = "TimeToUnix", []string{timeUnit(opts)}
+	case source == timePtrTypeKey && target == "int64":
+		helperName, args = "TimePtrToUnix", []string{timeUnit(opts)}
+	case source == "int64" && target == timeTypeKey:
+		helperName, args = "UnixToTime", []string{timeUnit(opts), strconv.Quote(opts.Zone)}
+	case source == "int64" && target == timePtrTypeKey:
+		helperName, args = "UnixToTimePtr", []string{timeUnit(opts), strconv.Quote(opts.Zone)}
+	case source == target && source == timeTypeKey && opts.Zone != "":
+		helperName, args = "InZone", []string{strconv.Quote(opts.Zone)}
+	case source == target && source == timePtrTypeKey && opts.Zone != "":
+		helperName, args = "InZonePtr", []string{strconv.Quote(opts.Zone)}
+	default:
+		return "", nil, nil, false
+	}
+
+	helper := ce.timeHelpers[helperName]
+	arg := sourceFieldExpr
+	for _, a := range args {
+		arg += ", " + a
+	}
+	expr, preAssignments := ce.callExpression(ce.helperFuncName(helper), arg, sourceFieldExpr, fallible)
+	return expr, ce.withRequiredHelpers(helper), preAssignments, true
+}
+
+// timeLayout returns the expression for the configured layout, referring to the time
+// package constant when the layout names one.
+func (ce *ConversionEngine) timeLayout(opts config.TimeOptions) string {
+	layout := opts.Layout
+	if layout == "" {
+		layout = defaultLayout
+	}
+	if timeLayoutConstants[layout] {
+		return ce.importManager.Add(timePkg) + "." + layout
+	}
+	return strconv.Quote(layout)
+}
+
+// timeUnit returns the quoted Unix unit, defaulting to seconds.
+func timeUnit(opts config.TimeOptions) string {
+	if opts.Unix == "" {
+		return strconv.Quote(defaultUnit)
+	}
+	return strconv.Quote(opts.Unix)
+}
+
+// withRequiredHelpers returns helper followed by the time helpers its body calls, so that
+// they are emitted together in inline mode.
+func (ce *ConversionEngine) withRequiredHelpers(helper model.Helper) []model.Helper {
+	helpers := []model.Helper{helper}
+	for _, name := range helper.Requires {
+		if required, ok := ce.timeHelpers[name]; ok {
+			if ce.helperMode == config.HelperModeInline {
+				ce.addRequiredImportsForHelper(required)
+			}
+			helpers = append(helpers, ce.withRequiredHelpers(required)...)
+		}
+	}
+	return helpers
+}
@@ -3,6 +3,7 @@ package components
 import (
 	"fmt"
 	"go/types"
+	"strings"
 
 	"github.com/origadmin/abgen/internal/model"
 )
@@ -12,6 +13,7 @@ type TypeFormatter struct {
 	aliasManager  model.AliasManager
 	importManager model.ImportManager
 	typeInfos     map[string]*model.TypeInfo
+	localPath     string
 }
 
 // NewTypeFormatter creates a new TypeFormatter.
@@ -24,6 +26,7 @@ func NewTypeFormatter(
 		aliasManager:  aliasManager,
 		importManager: importManager,
 		typeInfos:     analysisResult.TypeInfos,
+		localPath:     analysisResult.ExecutionPlan.FinalConfig.GenerationContext.PackagePath,
 	}
 }
 
@@ -75,6 +78,9 @@ func (f *TypeFormatter) formatTypeInfo(info *model.TypeInfo, checkAlias bool) st
 		if info.Name != "" {
 			return f.qualifiedNameFromInfo(info)
 		}
+		if info.Original != nil {
+			return types.TypeString(info.Original.Type(), f.qualifier)
+		}
 		return "struct{}"
 	default:
 		if info.Original != nil {
@@ -87,7 +93,7 @@ func (f *TypeFormatter) formatTypeInfo(info *model.TypeInfo, checkAlias bool) st
 // qualifiedNameFromInfo ensures that when a type's qualified name is generated,
 // its package is added to the import manager.
 func (f *TypeFormatter) qualifiedNameFromInfo(info *model.TypeInfo) string {
-	if info.ImportPath == "" {
+	if info.ImportPath == "" || info.ImportPath == f.localPath {
 		return info.Name // Built-in type or local
 	}
 
@@ -95,7 +101,15 @@ func (f *TypeFormatter) qualifiedNameFromInfo(info *model.TypeInfo) string {
 	// The manager handles conflicts and ensures the path is only added once.
 	pkgAlias := f.importManager.Add(info.ImportPath)
 
-	return fmt.Sprintf("%s.%s", pkgAlias, info.Name)
+	name := fmt.Sprintf("%s.%s", pkgAlias, info.Name)
+	if len(info.TypeArgs) > 0 {
+		args := make([]string, len(info.TypeArgs))
+		for i, arg := range info.TypeArgs {
+			args[i] = f.Format(arg)
+		}
+		name += "[" + strings.Join(args, ", ") + "]"
+	}
+	return name
 }
 
 func (f *TypeFormatter) qualifier(pkg *types.Package) string {
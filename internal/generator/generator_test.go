@@ -4,13 +4,18 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/origadmin/abgen/internal/analyzer"
+	"github.com/origadmin/abgen/internal/config"
+	"github.com/origadmin/abgen/internal/diff"
+	"github.com/origadmin/abgen/internal/model"
 )
 
 func init() {
@@ -28,6 +33,9 @@ var testCases = []struct {
 	priority       string
 	category       string
 	assertFunc     func(t *testing.T, generatedCode []byte, stubCode []byte)
+	testAssertFunc func(t *testing.T, testCode []byte)
+	// runTests runs the tests of the directive package against the generated code.
+	runTests bool
 }{
 	{
 		name:          "simple_struct_conversion",
@@ -105,6 +113,22 @@ var testCases = []struct {
 			assertNotContainsPattern(t, generatedStr, `CreatedAt:`)
 			assertContainsPattern(t, generatedStr, `FullName:\s+from.Name,`)
 			assertContainsPattern(t, generatedStr, `UserEmail:\s+from.Email,`)
+			assertContainsPattern(t, generatedStr, `LastUpdate:\s+from.UpdatedAt,`)
+			assertContainsPattern(t, generatedStr, `func ConvertUserDTOToUser\(from \*UserDTO\) \*User`)
+			assertContainsPattern(t, generatedStr, `\bName:\s+from.FullName,`)
+			assertContainsPattern(t, generatedStr, `\bEmail:\s+from.UserEmail,`)
+			assertContainsPattern(t, generatedStr, `UpdatedAt:\s+from.LastUpdate,`)
+		},
+	},
+	{
+		name:          "field_ignore_reverse",
+		directivePath: "../../testdata/02_basic_conversions/field_ignore_reverse",
+		priority:      "P0",
+		category:      "basic_conversions",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertContainsPattern(t, generatedStr, `to := &AccountDTO\{\s+ID:\s+from.ID,\s+Title:\s+from.Name,\s+\}`)
+			assertContainsPattern(t, generatedStr, `to := &Account\{\s+ID:\s+from.ID,\s+Name:\s+from.Title,\s+Secret:\s+from.Secret,\s+\}`)
 		},
 	},
 	{
@@ -134,6 +158,477 @@ var testCases = []struct {
 			assertContainsPattern(t, stubStr, `func ConvertUserStatusToUserCustomStatus\(from int\) string`)
 		},
 	},
+	{
+		name:          "convert_rule_funcs",
+		directivePath: "../../testdata/03_advanced_features/convert_rule_funcs",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertContainsPattern(t, generatedStr, `Price:\s+RoundedCents\(from.Price\),`)
+			assertContainsPattern(t, generatedStr, `tos\[i\] = RoundedCents\(f\)`)
+			assertContainsPattern(t, generatedStr, `Status:\s+StatusLabel\(from.Status\),`)
+			assertContainsPattern(t, generatedStr, `Price:\s+CentsToMoney\(from.Price\),`)
+			assertNotContainsPattern(t, generatedStr, `MoneyToCents|StatusName`)
+			if strings.Contains(string(stubCode), "func ") {
+				t.Errorf("stubs generated for conversions taken over by convert:rule:\n%s", stubCode)
+			}
+		},
+	},
+	{
+		name:          "user_conversion_funcs",
+		directivePath: "../../testdata/03_advanced_features/user_conversion_funcs",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			stubStr := string(stubCode)
+			assertContainsPattern(t, generatedStr, `Price:\s+MoneyToCents\(from.Price\),`)
+			assertContainsPattern(t, generatedStr, `Price:\s+CentsToMoney\(from.Price\),`)
+			assertContainsPattern(t, generatedStr, `tos\[i\] = MoneyToCents\(f\)`)
+			assertContainsPattern(t, generatedStr, `convfromStatus, _ := ParseStatus\(from.Status\)`)
+			assertContainsPattern(t, generatedStr, `Status:\s+convfromStatus,`)
+			assertNotContainsPattern(t, generatedStr, `StatusName|StatusLabel`)
+			assertContainsPattern(t, stubStr, `func ConvertStatusSourceToString\(from StatusSource\) string`)
+		},
+	},
+	{
+		name:          "user_conversion_funcs_error_mode",
+		directivePath: "../../testdata/03_advanced_features/user_conversion_funcs_error_mode",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertContainsPattern(t, generatedStr, `func ConvertOrderTargetToOrderSource\(from \*OrderTarget\) \(\*OrderSource, error\)`)
+			assertContainsPattern(t, generatedStr, `convfromPrice, err := ParseCents\(from.Price\)`)
+			assertContainsPattern(t, generatedStr, `return nil, fmt.Errorf\("convert Status: %w", err\)`)
+			assertContainsPattern(t, generatedStr, `v, err := ParseCents\(f\)`)
+			assertContainsPattern(t, generatedStr, `Items:\s+convfromItems,`)
+			assertContainsPattern(t, generatedStr, `return to, nil`)
+		},
+	},
+	{
+		name:          "helper_packages",
+		directivePath: "../../testdata/03_advanced_features/helper_packages",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertContainsPattern(t, generatedStr, `"github.com/origadmin/abgen/testdata/03_advanced_features/helper_packages/convx"`)
+			assertContainsPattern(t, generatedStr, `Total:\s+convx.AmountToCents\(from.Total\),`)
+			assertContainsPattern(t, generatedStr, `Total:\s+convx.CentsToAmount\(from.Total\),`)
+			assertContainsPattern(t, generatedStr, `CreatedAt:\s+convx.FormatTime\(from.CreatedAt\),`)
+			assertContainsPattern(t, generatedStr, `convfromCreatedAt, _ := convx.ParseTime\(from.CreatedAt\)`)
+			assertContainsPattern(t, generatedStr, `Tenant:\s+TenantIDString\(from.Tenant\),`)
+			assertContainsPattern(t, generatedStr, `DueAt:\s+abgenrt.FormatTime\(from.DueAt, time.DateOnly, ""\),`)
+			assertContainsPattern(t, generatedStr, `convfromDueAt, _ := abgenrt.ParseTime\(from.DueAt, time.DateOnly, ""\)`)
+			assertNotContainsPattern(t, generatedStr, `TenantToString|tenantFromString|convx.\w+\(from.DueAt`)
+		},
+	},
+	{
+		name:          "runtime_helpers",
+		directivePath: "../../testdata/03_advanced_features/runtime_helpers",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertContainsPattern(t, generatedStr, `abgenrt "github.com/origadmin/abgen/runtime"`)
+			assertContainsPattern(t, generatedStr, `StartAt:\s+abgenrt.ConvertTimeToString\(from.StartAt\),`)
+			assertContainsPattern(t, generatedStr, `convfromStartAt, _ := abgenrt.ParseTime\(from.StartAt, time.RFC3339, ""\)`)
+			assertNotContainsPattern(t, generatedStr, `func ConvertTimeToString`)
+		},
+	},
+	{
+		name:          "runtime_helpers_inline",
+		directivePath: "../../testdata/03_advanced_features/runtime_helpers_inline",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertNotContainsPattern(t, generatedStr, `abgenrt`)
+			assertContainsPattern(t, generatedStr, `StartAt:\s+ConvertTimeToString\(from.StartAt\),`)
+			assertContainsPattern(t, generatedStr, `func ConvertTimeToString\(t time.Time\) string`)
+		},
+	},
+	{
+		name:          "config_file",
+		directivePath: "../../testdata/03_advanced_features/config_file",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertContainsPattern(t, generatedStr, `UserDTOModel = target.UserDTO`)
+			assertContainsPattern(t, generatedStr, `func ConvertUserToUserDTOModel\(from \*User\) \*UserDTOModel`)
+			assertContainsPattern(t, generatedStr, `FullName:\s+from.Name,`)
+			assertContainsPattern(t, generatedStr, `UserEmail:\s+from.Email,`)
+			assertContainsPattern(t, generatedStr, `CreatedDate:\s+from.CreatedAt,`)
+			assertNotContainsPattern(t, generatedStr, `Password`)
+			assertNotContainsPattern(t, generatedStr, `func ConvertUserDTOModelToUser`)
+		},
+	},
+	{
+		name:          "time_options",
+		directivePath: "../../testdata/03_advanced_features/time_options",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertContainsPattern(t, generatedStr, `StartAt:\s+abgenrt.FormatTime\(from.StartAt, time.RFC3339Nano, "UTC"\),`)
+			assertContainsPattern(t, generatedStr, `Birthday:\s+abgenrt.FormatTime\(from.Birthday, time.DateOnly, "UTC"\),`)
+			assertContainsPattern(t, generatedStr, `EndAt:\s+abgenrt.FormatTimePtr\(from.EndAt, "2006-01-02 15:04", "UTC"\),`)
+			assertContainsPattern(t, generatedStr, `CreatedAt:\s+abgenrt.TimeToUnix\(from.CreatedAt, "s"\),`)
+			assertContainsPattern(t, generatedStr, `UpdatedAt:\s+abgenrt.TimePtrToUnix\(from.UpdatedAt, "ms"\),`)
+			assertContainsPattern(t, generatedStr, `DeletedAt:\s+abgenrt.InZone\(from.DeletedAt, "UTC"\),`)
+			assertContainsPattern(t, generatedStr, `convfromStartAt, _ := abgenrt.ParseTime\(from.StartAt, time.RFC3339Nano, "UTC"\)`)
+			assertContainsPattern(t, generatedStr, `convfromEndAt, _ := abgenrt.ParseTimePtr\(from.EndAt, "2006-01-02 15:04", "UTC"\)`)
+			assertContainsPattern(t, generatedStr, `UpdatedAt:\s+abgenrt.UnixToTimePtr\(from.UpdatedAt, "ms", "UTC"\),`)
+		},
+	},
+	{
+		name:          "time_parse_errors",
+		directivePath: "../../testdata/03_advanced_features/time_parse_errors",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertContainsPattern(t, generatedStr, `StartAt:\s+abgenrt.ConvertTimeToString\(from.StartAt\),`)
+			assertContainsPattern(t, generatedStr, `convfromStartAt, err := abgenrt.ParseTime\(from.StartAt, time.RFC3339, ""\)`)
+			assertContainsPattern(t, generatedStr, `return nil, fmt.Errorf\("convert StartAt: %w", err\)`)
+			assertNotContainsPattern(t, generatedStr, `ConvertStringToTime`)
+		},
+	},
+	{
+		name:          "time_options_inline",
+		directivePath: "../../testdata/03_advanced_features/time_options_inline",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertNotContainsPattern(t, generatedStr, `abgenrt`)
+			assertContainsPattern(t, generatedStr, `convfromStartAt, err := ParseTime\(from.StartAt, time.DateTime, "Asia/Shanghai"\)`)
+			assertContainsPattern(t, generatedStr, `return nil, fmt.Errorf\("convert StartAt: %w", err\)`)
+			assertContainsPattern(t, generatedStr, `func FormatTimePtr\(t \*time.Time, layout, zone string\) string`)
+			assertContainsPattern(t, generatedStr, `func FormatTime\(t time.Time, layout, zone string\) string`)
+			assertContainsPattern(t, generatedStr, `func InZone\(t time.Time, zone string\) time.Time`)
+			assertContainsPattern(t, generatedStr, `var zoneLocations sync.Map`)
+			assertContainsPattern(t, generatedStr, `func loadLocation\(zone string\) \(\*time.Location, error\)`)
+		},
+	},
+	{
+		name:          "sql_null",
+		directivePath: "../../testdata/03_advanced_features/sql_null",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertContainsPattern(t, generatedStr, `if from.Name.Valid \{\s+v := from.Name.String\s+nullfromName = &v\s+\}`)
+			assertContainsPattern(t, generatedStr, `if from.Age.Valid \{\s+nullfromAge = from.Age.Int32\s+\}`)
+			assertContainsPattern(t, generatedStr, `nullfromScore = int32\(from.Score.Int64\)`)
+			assertContainsPattern(t, generatedStr, `var nullfromNickname sql.Null\[string\]`)
+			assertContainsPattern(t, generatedStr, `if from.Name != nil \{\s+nullfromName = sql.NullString\{String: \*from.Name, Valid: true\}`)
+			assertContainsPattern(t, generatedStr, `Age:\s+sql.NullInt32\{Int32: from.Age, Valid: true\},`)
+			assertContainsPattern(t, generatedStr, `Level:\s+sql.Null\[int64\]\{V: int64\(from.Level.Int16\), Valid: from.Level.Valid\},`)
+			assertNotContainsPattern(t, string(stubCode), `Null`)
+		},
+	},
+	{
+		name:          "json_fields",
+		directivePath: "../../testdata/03_advanced_features/json_fields",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertContainsPattern(t, generatedStr, `convfromSettings, err := json.Marshal\(from.Settings\)\s+if err != nil \{\s+return nil, fmt.Errorf\("convert Settings: %w", err\)`)
+			assertContainsPattern(t, generatedStr, `Tags:\s+string\(convfromTags\),`)
+			assertContainsPattern(t, generatedStr, `Profile:\s+json.RawMessage\(convfromProfile\),`)
+			assertContainsPattern(t, generatedStr, `var convfromSettings SettingsDTO\s+if len\(from.Settings\) > 0 \{\s+if err := json.Unmarshal\(from.Settings, &convfromSettings\); err != nil \{`)
+			assertContainsPattern(t, generatedStr, `json.Unmarshal\(\[\]byte\(from.Tags\), &convfromTags\)`)
+			assertContainsPattern(t, generatedStr, `var convfromProfile \*ProfileDTO`)
+			assertContainsPattern(t, generatedStr, `if from.Extra.Valid \{\s+var convfromExtraString map\[string\]any`)
+			assertNotContainsPattern(t, string(stubCode), `func `)
+		},
+	},
+	{
+		name:          "json_fields_ignore",
+		directivePath: "../../testdata/03_advanced_features/json_fields_ignore",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertContainsPattern(t, generatedStr, `convfromSettings, _ := json.Marshal\(from.Settings\)`)
+			assertContainsPattern(t, generatedStr, `_ = json.Unmarshal\(from.Settings, &convfromSettings\)`)
+			assertContainsPattern(t, string(stubCode), `func ConvertStringToStrings\(from string\) \[\]string`)
+		},
+	},
+	{
+		name:          "structpb_helpers",
+		directivePath: "../../testdata/03_advanced_features/structpb_helpers",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertContainsPattern(t, generatedStr, `Metadata:\s+protoconv.ConvertStructToMap\(from.Metadata\),`)
+			assertContainsPattern(t, generatedStr, `Labels:\s+protoconv.ConvertStructToMap\(from.Labels\),`)
+			assertContainsPattern(t, generatedStr, `Default:\s+protoconv.ConvertValueToAny\(from.Default\),`)
+			assertContainsPattern(t, generatedStr, `Items:\s+protoconv.ConvertListValueToSlice\(from.Items\),`)
+			assertContainsPattern(t, generatedStr, `convfromMetadata, err := protoconv.ConvertMapToStruct\(from.Metadata\)`)
+			assertContainsPattern(t, generatedStr, `convfromLabels, err := protoconv.ConvertMapToStruct\(from.Labels\)`)
+			assertContainsPattern(t, generatedStr, `convfromDefault, err := protoconv.ConvertAnyToValue\(from.Default\)`)
+			assertContainsPattern(t, generatedStr, `convfromItems, err := protoconv.ConvertSliceToListValue\(from.Items\)`)
+			assertNotContainsPattern(t, string(stubCode), `func `)
+		},
+	},
+	{
+		name:          "struct_map",
+		directivePath: "../../testdata/03_advanced_features/struct_map",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertContainsPattern(t, generatedStr, `func ConvertUserToMap\(from \*User\) map\[string\]any \{`)
+			assertContainsPattern(t, generatedStr, `func ConvertMapToUser\(from map\[string\]any\) \(\*User, error\) \{`)
+			assertContainsPattern(t, generatedStr, `"profile":\s+ConvertProfileToMap\(from.Profile\),`)
+			assertContainsPattern(t, generatedStr, `"address":\s+ConvertAddressToMap\(&from.Address\),`)
+			assertContainsPattern(t, generatedStr, `"created_at":\s+from.CreatedAt,`)
+			assertContainsPattern(t, generatedStr, `"Note":\s+from.Note,`)
+			assertContainsPattern(t, generatedStr, `value, ok := v.\(int64\)`)
+			assertContainsPattern(t, generatedStr, `return nil, fmt.Errorf\("convert key %q: got %T, want int64", "id", v\)`)
+			assertContainsPattern(t, generatedStr, `value, err := ConvertMapToAddress\(m\)`)
+			assertNotContainsPattern(t, generatedStr, `Password`)
+			assertNotContainsPattern(t, generatedStr, `ConvertTimeToMap`)
+		},
+	},
+	{
+		name:          "struct_map_remap",
+		directivePath: "../../testdata/03_advanced_features/struct_map_remap",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertContainsPattern(t, generatedStr, `"full_name":\s+from.Name,`)
+			assertContainsPattern(t, generatedStr, `if v, ok := from\["full_name"\]; ok && v != nil \{`)
+			assertContainsPattern(t, generatedStr, `to.Name = value`)
+			assertContainsPattern(t, generatedStr, `if v, ok := from\["tags"\]; ok && v != nil \{`)
+			assertNotContainsPattern(t, generatedStr, `"name"|"tags":`)
+		},
+	},
+	{
+		name:          "struct_map_fields",
+		directivePath: "../../testdata/03_advanced_features/struct_map_fields",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertContainsPattern(t, generatedStr, `convfromBefore, err := ConvertOrderModelToMap\(&from.Before\)`)
+			assertContainsPattern(t, generatedStr, `convfromAfter, err := ConvertMapToOrderModel\(from.After\)`)
+			assertContainsPattern(t, generatedStr, `(?s)var mapfromBefore OrderModel\s+if convfromBefore != nil \{\s+mapfromBefore = \*convfromBefore`)
+			assertContainsPattern(t, generatedStr, `"Attempts":\s+strconv.FormatInt\(int64\(from.Attempts\), 10\),`)
+			assertContainsPattern(t, generatedStr, `"Level":\s+string\(from.Level\),`)
+			assertContainsPattern(t, generatedStr, `value, err := strconv.ParseFloat\(v, 32\)`)
+			assertContainsPattern(t, generatedStr, `to.Ratio = float32\(value\)`)
+			assertContainsPattern(t, generatedStr, `to.Level = LevelModel\(v\)`)
+			assertNotContainsPattern(t, generatedStr, `Owner`)
+			assertNotContainsPattern(t, string(stubCode), `func `)
+		},
+	},
+	{
+		name:          "deep_copy",
+		directivePath: "../../testdata/03_advanced_features/deep_copy",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertContainsPattern(t, generatedStr, `Tags:\s+slices.Clone\(from.Tags\),`)
+			assertContainsPattern(t, generatedStr, `Attrs:\s+maps.Clone\(from.Attrs\),`)
+			assertContainsPattern(t, generatedStr, `Items:\s+DeepCopyItems\(from.Items\),`)
+			assertContainsPattern(t, generatedStr, `Index:\s+DeepCopyItemMap\(from.Index\),`)
+			assertContainsPattern(t, generatedStr, `Primary:\s+DeepCopyItem\(from.Primary\),`)
+			assertContainsPattern(t, generatedStr, `Address:\s+\*DeepCopyAddress\(&from.Address\),`)
+			assertContainsPattern(t, generatedStr, `Note:\s+DeepCopyStringPtr\(from.Note\),`)
+			assertContainsPattern(t, generatedStr, `CreatedAt:\s+from.CreatedAt,`)
+			assertContainsPattern(t, generatedStr, `Extra:\s+from.Extra,`)
+			assertContainsPattern(t, generatedStr, `(?s)func DeepCopyItem\(from \*shared.Item\) \*shared.Item \{.*to := \*from\s+to.Tags = slices.Clone\(from.Tags\)\s+return &to`)
+			assertContainsPattern(t, generatedStr, `Meta:\s+struct\{ Tags \[\]string \}\{Tags: slices.Clone\(from.Meta.Tags\)\},`)
+			assertContainsPattern(t, generatedStr, `Audit:\s+struct\{ Changes \[\]\[\]string \}\{Changes: DeepCopyStringses\(from.Audit.Changes\)\},`)
+			assertNotContainsPattern(t, generatedStr, `DeepCopyLine`)
+		},
+		runTests: true,
+	},
+	{
+		name:          "deepcopy_directive",
+		directivePath: "../../testdata/03_advanced_features/deepcopy_directive",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertContainsPattern(t, generatedStr, `func DeepCopyTree\(from \*Tree\) \*Tree \{`)
+			assertContainsPattern(t, generatedStr, `func DeepCopyLabels\(from Labels\) Labels \{`)
+			assertContainsPattern(t, generatedStr, `to.Children = DeepCopyTrees\(from.Children\)`)
+			assertContainsPattern(t, generatedStr, `to.Root = \*DeepCopyNode\(&from.Root\)`)
+			assertContainsPattern(t, generatedStr, `to.Labels = maps.Clone\(from.Labels\)`)
+			assertContainsPattern(t, generatedStr, `to\[i\] = DeepCopyTree\(v\)`)
+			assertNotContainsPattern(t, generatedStr, `TreeSource|TreeTarget`)
+		},
+	},
+	{
+		name:          "compare_functions",
+		directivePath: "../../testdata/03_advanced_features/compare_functions",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertContainsPattern(t, generatedStr, `func EqualUserAndUserPB\(a \*User, b \*UserPB\) bool`)
+			assertContainsPattern(t, generatedStr, `func DiffUserAndUserPB\(a \*User, b \*UserPB\) \[\]abgenrt\.FieldDiff`)
+			assertContainsPattern(t, generatedStr, `if a\.Name != b\.FullName \{`)
+			assertContainsPattern(t, generatedStr, `FieldDiff\{Path: "Name", TargetPath: "FullName", A: a\.Name, B: b\.FullName\}`)
+			assertContainsPattern(t, generatedStr, `if abgenrt\.ConvertTimeToString\(a\.CreatedAt\) != b\.CreatedAt \{`)
+			assertContainsPattern(t, generatedStr, `abgenrt\.NestDiffs\("Profile", "Profile", DiffProfileAndProfilePB\(a\.Profile, b\.Profile\)\)`)
+			assertContainsPattern(t, generatedStr, `if len\(a\.Roles\) != len\(b\.Roles\) \{\n\t\tdiffs = append\(diffs, abgenrt\.FieldDiff\{Path: "Roles", TargetPath: "Roles", A: a\.Roles, B: b\.Roles\}\)`)
+			assertContainsPattern(t, generatedStr, `abgenrt\.NestDiffs\("Roles\["\+strconv\.Itoa\(i\)\+"\]", "Roles\["\+strconv\.Itoa\(i\)\+"\]", DiffRoleAndRolePB\(a\.Roles\[i\], b\.Roles\[i\]\)\)`)
+			assertContainsPattern(t, generatedStr, `vb, ok := b\.Teams\[k\]`)
+			assertContainsPattern(t, generatedStr, `abgenrt\.NestDiffs\("Teams\["\+fmt\.Sprint\(k\)\+"\]", "Teams\["\+fmt\.Sprint\(k\)\+"\]", DiffRoleAndRolePB\(&va, &vb\)\)`)
+			assertNotContainsPattern(t, generatedStr, `reflect\.DeepEqual\(Convert`)
+			assertNotContainsPattern(t, generatedStr, `Password`)
+			assertNotContainsPattern(t, generatedStr, `func DiffUserPBAndUser`)
+		},
+		testAssertFunc: func(t *testing.T, testCode []byte) {
+			if len(testCode) > 0 {
+				t.Errorf("round-trip tests generated without the test:generate directive:\n%s", testCode)
+			}
+		},
+	},
+	{
+		name:          "compare_functions_inline",
+		directivePath: "../../testdata/03_advanced_features/compare_functions_inline",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertContainsPattern(t, generatedStr, `func DiffUserAndUserPB\(a \*User, b \*UserPB\) \[\]FieldDiff`)
+			assertContainsPattern(t, generatedStr, `NestDiffs\("Roles\["\+strconv\.Itoa\(i\)\+"\]", "Roles\["\+strconv\.Itoa\(i\)\+"\]", DiffRoleAndRolePB\(a\.Roles\[i\], b\.Roles\[i\]\)\)`)
+			assertContainsPattern(t, generatedStr, `func ConvertRolesToRolePBs\(froms Roles\) \(RolePBs, error\)`)
+			assertNotContainsPattern(t, generatedStr, `func DiffUserAndUserPB\(a \*User, b \*UserPB\) \(`)
+			assertContainsPattern(t, generatedStr, `type FieldDiff struct`)
+			assertContainsPattern(t, generatedStr, `func NestDiffs\(path, targetPath string, diffs \[\]FieldDiff\) \[\]FieldDiff`)
+			assertNotContainsPattern(t, generatedStr, `abgenrt`)
+		},
+	},
+	{
+		name:          "round_trip_tests",
+		directivePath: "../../testdata/03_advanced_features/round_trip_tests",
+		priority:      "P0",
+		category:      "advanced_features",
+		testAssertFunc: func(t *testing.T, testCode []byte) {
+			testStr := string(testCode)
+			assertContainsPattern(t, testStr, `func TestRoundTripUserAndUserPB\(t \*testing\.T\)`)
+			assertContainsPattern(t, testStr, `func FuzzRoundTripUserAndUserPB\(f \*testing\.F\)`)
+			assertContainsPattern(t, testStr, `testfill\.Fill\(&from, data\)`)
+			assertContainsPattern(t, testStr, `back := ConvertUserPBToUser\(ConvertUserToUserPB\(&from\)\)`)
+			assertContainsPattern(t, testStr, `if back\.Name != from\.Name \{`)
+			assertContainsPattern(t, testStr, `if back\.Age != from\.Age \{`)
+			assertContainsPattern(t, testStr, `if !back\.UpdatedAt\.Equal\(from\.UpdatedAt\) \{`)
+			assertContainsPattern(t, testStr, `if !reflect\.DeepEqual\(back\.Address, from\.Address\) \{`)
+			assertNotContainsPattern(t, testStr, `back\.(Password|Score|CreatedAt|Profile)\b`)
+		},
+	},
+	{
+		name:          "round_trip_tests_errors",
+		directivePath: "../../testdata/03_advanced_features/round_trip_tests_errors",
+		priority:      "P0",
+		category:      "advanced_features",
+		testAssertFunc: func(t *testing.T, testCode []byte) {
+			testStr := string(testCode)
+			assertContainsPattern(t, testStr, `to, err := ConvertUserToUserPB\(&from\)\n\tif err != nil \{\n\t\tt\.Skipf`)
+			assertContainsPattern(t, testStr, `back, err := ConvertUserPBToUser\(to\)\n\tif err != nil \{\n\t\tt\.Fatalf`)
+		},
+	},
+	{
+		name:          "converter_interface",
+		directivePath: "../../testdata/03_advanced_features/converter_interface",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			stubStr := string(stubCode)
+			assertContainsPattern(t, generatedStr, `type ConverterImpl struct\{\}`)
+			assertContainsPattern(t, generatedStr, `var _ Converter = ConverterImpl\{\}`)
+			assertContainsPattern(t, generatedStr, `func \(ConverterImpl\) UserToPB\(from \*User\) \*UserPB \{\s+return ConvertUserToUserPB\(from\)`)
+			assertContainsPattern(t, generatedStr, `func \(ConverterImpl\) UserFromPB\(from \*UserPB\) \(\*User, error\) \{\s+return ConvertUserPBToUser\(from\), nil`)
+			assertContainsPattern(t, generatedStr, `func \(ConverterImpl\) RolesToPB\(from Roles\) RolePBs \{\s+return ConvertRolesToRolePBs\(from\)`)
+			assertContainsPattern(t, generatedStr, `func \(ConverterImpl\) RoleToPB\(from Role\) RolePB \{\s+return \*ConvertRoleToRolePB\(&from\)`)
+			assertContainsPattern(t, generatedStr, `func \(ConverterImpl\) AgeToPB\(from int32\) int64 \{\s+return int64\(from\)`)
+			assertContainsPattern(t, generatedStr, `func ConvertUserToUserPB\(from \*User\) \*UserPB`)
+			assertContainsPattern(t, stubStr, `// It is needed by Converter.UserToPB.\n.*\nfunc ConvertStatusToState\(from Status\) State`)
+			assertContainsPattern(t, stubStr, `// It is needed by Converter.UserFromPB.\n.*\nfunc ConvertStateToStatus\(from State\) Status`)
+		},
+	},
+	{
+		name:          "converter_interface_errors",
+		directivePath: "../../testdata/03_advanced_features/converter_interface_errors",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertContainsPattern(t, generatedStr, `type RoleMapper struct\{\}`)
+			assertContainsPattern(t, generatedStr, `var _ RoleConverter = RoleMapper\{\}`)
+			assertContainsPattern(t, generatedStr, `convfrom, err := ConvertRoleToRolePB\(&from\)\s+if err != nil \{\s+return RolePB\{\}, err`)
+			assertContainsPattern(t, generatedStr, `convfrom, err := ConvertRolesToRolePBs\(from\)\s+if err != nil \{\s+return nil, err`)
+			assertContainsPattern(t, generatedStr, `func \(RoleMapper\) RoleFromPB\(from \*RolePB\) \*Role \{\s+convfrom, _ := ConvertRolePBToRole\(from\)`)
+		},
+	},
+	{
+		name:          "receiver_methods",
+		directivePath: "../../testdata/03_advanced_features/receiver_methods",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertContainsPattern(t, generatedStr, `func \(from \*UserDTO\) ToPB\(\) \*UserTarget \{`)
+			assertContainsPattern(t, generatedStr, `func \(from \*RoleDTO\) ToPB\(\) \*RoleTarget \{`)
+			assertContainsPattern(t, generatedStr, `tos\[i\] = \(\*RoleDTO\)\.ToPB\(f\)`)
+			assertContainsPattern(t, generatedStr, `func \(to \*UserDTO\) FromPB\(from \*UserTarget\) \{\s+if conv := ConvertUserTargetToUserDTO\(from\); conv != nil \{\s+\*to = \*conv`)
+			assertContainsPattern(t, generatedStr, `func \(to \*RoleDTO\) FromPB\(from \*RoleTarget\)`)
+			assertContainsPattern(t, generatedStr, `func ConvertUserDTOToUserSummaryTarget\(from \*UserDTO\) \*UserSummaryTarget`)
+			assertContainsPattern(t, generatedStr, `func ConvertAccountSourceToAccountTarget\(from \*AccountSource\) \*AccountTarget`)
+			assertNotContainsPattern(t, generatedStr, `receiver_methods"|UserDTOSource|func ConvertUserDTOToUserTarget`)
+		},
+	},
+	{
+		name:          "func_name_template",
+		directivePath: "../../testdata/03_advanced_features/func_name_template",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertContainsPattern(t, generatedStr, `func UserToProto\(from \*User\) \*UserPB \{`)
+			assertContainsPattern(t, generatedStr, `func UserFromProto\(from \*UserPB\) \*User \{`)
+			assertContainsPattern(t, generatedStr, `func RolesToProto\(froms Roles\) RolesPB \{`)
+			assertContainsPattern(t, generatedStr, `tos\[i\] = RoleFromProto\(f\)`)
+			assertContainsPattern(t, generatedStr, `Status:\s+StatusToProto\(from.Status\),`)
+			assertNotContainsPattern(t, generatedStr, `func Convert`)
+
+			stubStr := string(stubCode)
+			assertContainsPattern(t, stubStr, `func StatusToProto\(from Status\) string \{`)
+			assertContainsPattern(t, stubStr, `func StatusFromProto\(from string\) Status \{`)
+		},
+	},
+	{
+		name:          "naming_strategy",
+		directivePath: "../../testdata/03_advanced_features/naming_strategy",
+		priority:      "P0",
+		category:      "advanced_features",
+		assertFunc: func(t *testing.T, generatedCode []byte, stubCode []byte) {
+			generatedStr := string(generatedCode)
+			assertContainsPattern(t, generatedStr, `PersonPB\s+= pb.Person`)
+			assertContainsPattern(t, generatedStr, `Categories\s+= \[\]\*ent.Category`)
+			assertContainsPattern(t, generatedStr, `PeoplePB\s+= \[\]\*pb.Person`)
+			assertContainsPattern(t, generatedStr, `CriteriaPB\s+= \[\]\*pb.Criterion`)
+			assertContainsPattern(t, generatedStr, `func ConvertCategoriesToCategoriesPB\(froms Categories\) CategoriesPB \{`)
+			assertContainsPattern(t, generatedStr, `Friends:\s+ConvertPeopleToPeoplePB\(from.Friends\),`)
+			assertContainsPattern(t, generatedStr, `Filters:\s+ConvertCriteriaPBToCriteria\(from.Filters\),`)
+			assertNotContainsPattern(t, generatedStr, `Categorys|Persons|Criterions|Pb\b`)
+		},
+	},
 	{
 		name:           "array_slice_test",
 		directivePath:  "../../testdata/06_regression/array_slice_test",
@@ -228,6 +723,19 @@ func TestCodeGenerator_Generate(t *testing.T) {
 				}
 			}
 
+			if len(response.TestCode) > 0 {
+				actualTestFile := filepath.Join(tc.directivePath, "actual.gen_test.go")
+				if err := os.WriteFile(actualTestFile, response.TestCode, 0644); err != nil {
+					t.Logf("Failed to save test output to %s: %v", actualTestFile, err)
+				} else {
+					t.Logf("Test output saved to %s for inspection", actualTestFile)
+				}
+			}
+
+			if tc.testAssertFunc != nil {
+				tc.testAssertFunc(t, response.TestCode)
+			}
+
 			if tc.assertFunc != nil {
 				tc.assertFunc(t, generatedCode, stubCode)
 				if t.Failed() {
@@ -235,6 +743,14 @@ func TestCodeGenerator_Generate(t *testing.T) {
 				}
 			}
 
+			if tc.runTests {
+				cmd := exec.Command("go", "test", ".")
+				cmd.Dir = tc.directivePath
+				if output, err := cmd.CombinedOutput(); err != nil {
+					t.Errorf("Tests of %s failed against the generated code: %v\n%s", tc.directivePath, err, output)
+				}
+			}
+
 			if tc.goldenFileName != "" {
 				goldenFile := filepath.Join(tc.directivePath, tc.goldenFileName)
 				if os.Getenv("UPDATE_GOLDEN_FILES") != "" {
@@ -249,12 +765,174 @@ func TestCodeGenerator_Generate(t *testing.T) {
 	}
 }
 
+// TestCodeGenerator_GeneratePackages checks that the directive packages analyzed together,
+// against shared packages and types, generate the same code as when analyzed one by one,
+// even when generated concurrently.
+func TestCodeGenerator_GeneratePackages(t *testing.T) {
+	dirs := []string{
+		"../../testdata/02_basic_conversions/simple_struct",
+		"../../testdata/03_advanced_features/converter_interface",
+		"../../testdata/03_advanced_features/func_name_template",
+		"../../testdata/03_advanced_features/helper_packages",
+		"../../testdata/03_advanced_features/naming_strategy",
+		"../../testdata/03_advanced_features/receiver_methods",
+	}
+
+	want := make([][]byte, len(dirs))
+	for i, dir := range dirs {
+		analysisResult, err := analyzer.NewTypeAnalyzer().Analyze(dir)
+		if err != nil {
+			t.Fatalf("Analyze(%s) failed: %v", dir, err)
+		}
+		response, err := Generate(analysisResult)
+		if err != nil {
+			t.Fatalf("Generate() failed for %s: %v", dir, err)
+		}
+		want[i] = response.GeneratedCode
+	}
+
+	results, err := analyzer.NewTypeAnalyzer().AnalyzePackages(dirs)
+	if err != nil {
+		t.Fatalf("AnalyzePackages() failed: %v", err)
+	}
+	got := make([][]byte, len(dirs))
+	errs := make([]error, len(dirs))
+	var wg sync.WaitGroup
+	for i, result := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := Generate(result)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			got[i] = response.GeneratedCode
+		}()
+	}
+	wg.Wait()
+
+	for i, dir := range dirs {
+		if errs[i] != nil {
+			t.Errorf("Generate() failed for %s: %v", dir, errs[i])
+			continue
+		}
+		if string(got[i]) != string(want[i]) {
+			t.Errorf("generated code of %s differs when analyzed with other packages:\n%s", dir,
+				diff.Unified(filepath.Base(dir)+".gen.go", want[i], got[i]))
+		}
+	}
+}
+
+// TestCodeGenerator_Conversions checks the record of how the generated struct conversions
+// set each target field, which explain prints.
+func TestCodeGenerator_Conversions(t *testing.T) {
+	tests := []struct {
+		name          string
+		directivePath string
+		function      string
+		origin        config.RuleOrigin
+		want          map[string]model.FieldStrategy
+	}{
+		{
+			name:          "ignored and remapped fields",
+			directivePath: "../../testdata/02_basic_conversions/field_ignore_remap",
+			function:      "ConvertUserToUserDTO",
+			origin:        config.OriginDirective,
+			want: map[string]model.FieldStrategy{
+				"ID":          model.StrategyDirect,
+				"FullName":    model.StrategyDirect,
+				"UserEmail":   model.StrategyDirect,
+				"CreatedDate": model.StrategyUnmapped,
+				"LastUpdate":  model.StrategyDirect,
+			},
+		},
+		{
+			name:          "fields ignored by the rule in the reverse conversion",
+			directivePath: "../../testdata/02_basic_conversions/field_ignore_remap",
+			function:      "ConvertUserDTOToUser",
+			origin:        config.OriginDirective,
+			want: map[string]model.FieldStrategy{
+				"ID":        model.StrategyDirect,
+				"Name":      model.StrategyDirect,
+				"Email":     model.StrategyDirect,
+				"Password":  model.StrategyUnmapped,
+				"CreatedAt": model.StrategyUnmapped,
+				"UpdatedAt": model.StrategyDirect,
+			},
+		},
+		{
+			name:          "helpers and stubs",
+			directivePath: "../../testdata/03_advanced_features/simple_field_remap",
+			function:      "ConvertUserToUserRemap",
+			origin:        config.OriginPackagePair,
+			want: map[string]model.FieldStrategy{
+				"Id":        model.StrategyDirect,
+				"Username":  model.StrategyDirect,
+				"Age":       model.StrategyDirect,
+				"Gender":    model.StrategyStub,
+				"Status":    model.StrategyStub,
+				"CreatedAt": model.StrategyHelper,
+			},
+		},
+		{
+			name:          "nested conversions",
+			directivePath: "../../testdata/03_advanced_features/naming_strategy",
+			function:      "ConvertPersonToPersonPB",
+			origin:        config.OriginDirective,
+			want: map[string]model.FieldStrategy{
+				"ID":         model.StrategyDirect,
+				"Name":       model.StrategyDirect,
+				"Categories": model.StrategyNested,
+				"Friends":    model.StrategyNested,
+				"Filters":    model.StrategyNested,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysisResult, err := analyzer.NewTypeAnalyzer().Analyze(tt.directivePath)
+			if err != nil {
+				t.Fatalf("Analyze(%s) failed: %v", tt.directivePath, err)
+			}
+			response, err := Generate(analysisResult)
+			if err != nil {
+				t.Fatalf("Generate() failed: %v", err)
+			}
+
+			var conversion *model.ConversionReport
+			for _, report := range response.Conversions {
+				if report.Function == tt.function {
+					conversion = report
+				}
+			}
+			if conversion == nil {
+				t.Fatalf("no conversion recorded for %s", tt.function)
+			}
+			if conversion.Origin != tt.origin {
+				t.Errorf("%s origin = %q, want %q", tt.function, conversion.Origin, tt.origin)
+			}
+			got := make(map[string]model.FieldStrategy)
+			for _, field := range conversion.Fields {
+				got[field.Target] = field.Strategy
+				if (field.Expression == "") != (field.Strategy == model.StrategyIgnored || field.Strategy == model.StrategyUnmapped) {
+					t.Errorf("%s field %s has expression %q with strategy %s", tt.function, field.Target, field.Expression, field.Strategy)
+				}
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("%s field strategies = %v, want %v", tt.function, got, tt.want)
+			}
+		})
+	}
+}
+
 func cleanTestFiles(t *testing.T, dir string) {
 	files, err := filepath.Glob(filepath.Join(dir, "*.gen.go"))
 	if err != nil {
 		t.Fatalf("Failed to glob for generated files in %s: %v", dir, err)
 	}
-	files = append(files, filepath.Join(dir, "actual.stub.go"))
+	files = append(files, filepath.Join(dir, "actual.stub.go"), filepath.Join(dir, "actual.gen_test.go"))
 	for _, f := range files {
 		if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
 			t.Logf("Warning: Failed to remove old generated file %s: %v", f, err)
@@ -0,0 +1,40 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/origadmin/abgen/internal/model"
+)
+
+// lockHeader starts the mapping lockfile.
+const lockHeader = `# Code generated by abgen. DO NOT EDIT.
+# The mappings of the generated struct conversions, compared by abgen check.
+# Review the changes of the mappings, then refresh this file with abgen -lock.
+`
+
+// LockFile renders the mapping lockfile of a generation response: for every generated struct
+// conversion, the expression and strategy setting each target field, one line per field in
+// target field order. Conversions are sorted by function name, so that the file only changes
+// when the mappings do.
+func LockFile(response *model.GenerationResponse) []byte {
+	conversions := append([]*model.ConversionReport(nil), response.Conversions...)
+	sort.SliceStable(conversions, func(i, j int) bool {
+		return conversions[i].Function < conversions[j].Function
+	})
+
+	var buf bytes.Buffer
+	buf.WriteString(lockHeader)
+	for _, conversion := range conversions {
+		fmt.Fprintf(&buf, "\n%s: %s -> %s\n", conversion.Function, conversion.Source, conversion.Target)
+		for _, field := range conversion.Fields {
+			if field.Expression == "" {
+				fmt.Fprintf(&buf, "\t%s [%s]\n", field.Target, field.Strategy)
+				continue
+			}
+			fmt.Fprintf(&buf, "\t%s = %s [%s]\n", field.Target, field.Expression, field.Strategy)
+		}
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,45 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/origadmin/abgen/internal/model"
+)
+
+func TestLockFile(t *testing.T) {
+	response := &model.GenerationResponse{
+		Conversions: []*model.ConversionReport{
+			{
+				Function: "ConvertUserToUserPB",
+				Source:   "example.com/ent.User",
+				Target:   "example.com/pb.User",
+				Fields: []*model.FieldMapping{
+					{Target: "Id", Source: "ID", Expression: "from.ID", Strategy: model.StrategyDirect},
+					{Target: "Age", Source: "Age", Expression: "int32(from.Age)", Strategy: model.StrategyCast},
+					{Target: "Email", Strategy: model.StrategyUnmapped},
+				},
+			},
+			{
+				Function: "ConvertRoleToRolePB",
+				Source:   "example.com/ent.Role",
+				Target:   "example.com/pb.Role",
+				Fields:   []*model.FieldMapping{},
+			},
+		},
+	}
+
+	want := lockHeader + `
+ConvertRoleToRolePB: example.com/ent.Role -> example.com/pb.Role
+
+ConvertUserToUserPB: example.com/ent.User -> example.com/pb.User
+	Id = from.ID [direct]
+	Age = int32(from.Age) [cast]
+	Email [unmapped]
+`
+	if got := string(LockFile(response)); got != want {
+		t.Errorf("LockFile() =\n%s\nwant:\n%s", got, want)
+	}
+	if got := string(LockFile(&model.GenerationResponse{})); got != lockHeader {
+		t.Errorf("LockFile() without conversions =\n%s\nwant the header only", got)
+	}
+}
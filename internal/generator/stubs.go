@@ -0,0 +1,176 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+
+	"github.com/origadmin/abgen/internal/model"
+)
+
+const (
+	// stubMarker identifies the functions of the custom stubs file written by abgen, whether
+	// or not they have been implemented since.
+	stubMarker = "is a custom conversion function stub."
+	// deprecatedStub is added to the documentation of the stubs no longer referenced by the
+	// generated code.
+	deprecatedStub = "// Deprecated: unused by abgen"
+)
+
+// stubEdit replaces the bytes between start and end of a file with text.
+type stubEdit struct {
+	start, end int
+	text       string
+}
+
+// MergeCustomStubs merges the stubs of a generation response into the existing content of the
+// custom stubs file, so that the functions implemented there survive regeneration. Functions
+// already declared in the file are kept untouched and only the missing stubs are appended,
+// with their imports. Stubs written by abgen that the generated code no longer references are
+// marked deprecated rather than deleted, and unmarked again once they are referenced.
+func MergeCustomStubs(existing []byte, response *model.GenerationResponse) ([]byte, error) {
+	if len(bytes.TrimSpace(existing)) == 0 {
+		return response.CustomStubs, nil
+	}
+
+	fset := token.NewFileSet()
+	existingFile, err := parser.ParseFile(fset, "", existing, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the existing custom stubs file: %w", err)
+	}
+	referenced, err := referencedIdentifiers(response.GeneratedCode)
+	if err != nil {
+		return nil, err
+	}
+
+	var edits []stubEdit
+	declared := make(map[string]bool)
+	for _, decl := range existingFile.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Recv != nil {
+			continue
+		}
+		declared[funcDecl.Name.Name] = true
+		if funcDecl.Doc == nil || !strings.Contains(funcDecl.Doc.Text(), stubMarker) {
+			continue
+		}
+		deprecation := deprecationComments(funcDecl.Doc)
+		switch {
+		case !referenced[funcDecl.Name.Name] && deprecation == nil:
+			offset := lineStart(existing, fset.Position(funcDecl.Pos()).Offset)
+			edits = append(edits, stubEdit{start: offset, end: offset, text: "//\n" + deprecatedStub + "\n"})
+		case referenced[funcDecl.Name.Name] && deprecation != nil:
+			edits = append(edits, stubEdit{
+				start: lineStart(existing, fset.Position(deprecation[0].Pos()).Offset),
+				end:   fset.Position(deprecation[len(deprecation)-1].End()).Offset + 1,
+			})
+		}
+	}
+
+	var appended []byte
+	var newImports []*ast.ImportSpec
+	if len(response.CustomStubs) > 0 {
+		stubFile, err := parser.ParseFile(fset, "", response.CustomStubs, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the generated custom stubs: %w", err)
+		}
+		for _, decl := range stubFile.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || declared[funcDecl.Name.Name] {
+				continue
+			}
+			start := funcDecl.Pos()
+			if funcDecl.Doc != nil {
+				start = funcDecl.Doc.Pos()
+			}
+			appended = append(appended, '\n')
+			appended = append(appended, response.CustomStubs[fset.Position(start).Offset:fset.Position(funcDecl.End()).Offset]...)
+			appended = append(appended, '\n')
+		}
+		if len(appended) > 0 {
+			newImports = stubFile.Imports
+		}
+	}
+
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	merged := append([]byte(nil), existing...)
+	for _, edit := range edits {
+		merged = append(merged[:edit.start], append([]byte(edit.text), merged[edit.end:]...)...)
+	}
+	merged = append(bytes.TrimRight(merged, "\n"), '\n')
+	merged = append(merged, appended...)
+
+	if len(newImports) == 0 {
+		return format.Source(merged)
+	}
+	return addImports(merged, newImports)
+}
+
+// referencedIdentifiers returns the names of the identifiers used by the generated code.
+func referencedIdentifiers(code []byte) (map[string]bool, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", code, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the generated code: %w", err)
+	}
+	referenced := make(map[string]bool)
+	ast.Inspect(file, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok {
+			referenced[ident.Name] = true
+		}
+		return true
+	})
+	return referenced, nil
+}
+
+// deprecationComments returns the comment lines marking a stub deprecated: the deprecation
+// itself and the empty line separating it from the rest of the documentation, if any.
+func deprecationComments(doc *ast.CommentGroup) []*ast.Comment {
+	for i, comment := range doc.List {
+		if comment.Text != deprecatedStub {
+			continue
+		}
+		if i > 0 && doc.List[i-1].Text == "//" {
+			return doc.List[i-1 : i+1]
+		}
+		return doc.List[i : i+1]
+	}
+	return nil
+}
+
+// lineStart returns the offset of the start of the line containing offset.
+func lineStart(src []byte, offset int) int {
+	return bytes.LastIndexByte(src[:offset], '\n') + 1
+}
+
+// addImports adds the imports of the appended stubs to the merged stubs file.
+func addImports(src []byte, specs []*ast.ImportSpec) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the merged custom stubs: %w", err)
+	}
+	for _, spec := range specs {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, err
+		}
+		name := ""
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		astutil.AddNamedImport(fset, file, name, path)
+	}
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, fmt.Errorf("failed to format the merged custom stubs: %w", err)
+	}
+	return buf.Bytes(), nil
+}
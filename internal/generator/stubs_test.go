@@ -0,0 +1,122 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/origadmin/abgen/internal/model"
+)
+
+const existingStubsFile = `// This file is generated by abgen, but you can edit it.
+// More info: https://github.com/origadmin/abgen
+
+package directives
+
+import "strconv"
+
+// ConvertStatusToString is a custom conversion function stub.
+// Please implement this function to complete the conversion.
+func ConvertStatusToString(from Status) string {
+	return strconv.Itoa(int(from))
+}
+
+// ConvertKindToString is a custom conversion function stub.
+// Please implement this function to complete the conversion.
+func ConvertKindToString(from Kind) string {
+	// TODO: Implement this custom conversion
+	panic("stub! not implemented")
+}
+
+// kindNames is not a stub.
+func kindNames() []string {
+	return nil
+}
+`
+
+const generatedStubs = `// This file is generated by abgen, but you can edit it.
+// More info: https://github.com/origadmin/abgen
+
+package directives
+
+import (
+	"time"
+)
+
+// ConvertStringToTime is a custom conversion function stub.
+// Please implement this function to complete the conversion.
+func ConvertStringToTime(from string) time.Time {
+	// TODO: Implement this custom conversion
+	panic("stub! not implemented")
+}
+`
+
+func TestMergeCustomStubs(t *testing.T) {
+	response := &model.GenerationResponse{
+		GeneratedCode: []byte(`package directives
+
+func ConvertUserToUserPB(from *User) *UserPB {
+	return &UserPB{Status: ConvertStatusToString(from.Status), CreatedAt: ConvertStringToTime(from.CreatedAt)}
+}
+`),
+		CustomStubs: []byte(generatedStubs),
+	}
+
+	merged, err := MergeCustomStubs([]byte(existingStubsFile), response)
+	if err != nil {
+		t.Fatalf("MergeCustomStubs() failed: %v", err)
+	}
+	mergedStr := string(merged)
+
+	for _, want := range []string{
+		"func ConvertStatusToString(from Status) string {\n\treturn strconv.Itoa(int(from))\n}",
+		"// Please implement this function to complete the conversion.\n//\n// Deprecated: unused by abgen\nfunc ConvertKindToString(from Kind) string {",
+		"// kindNames is not a stub.\nfunc kindNames() []string {",
+		"// ConvertStringToTime is a custom conversion function stub.\n// Please implement this function to complete the conversion.\nfunc ConvertStringToTime(from string) time.Time {",
+		`"strconv"`,
+		`"time"`,
+	} {
+		if !strings.Contains(mergedStr, want) {
+			t.Errorf("merged stubs do not contain %q:\n%s", want, mergedStr)
+		}
+	}
+	if strings.Count(mergedStr, "Deprecated") != 1 {
+		t.Errorf("expected only ConvertKindToString to be deprecated:\n%s", mergedStr)
+	}
+
+	// Regenerating leaves the merged file as it is: the appended stub is now declared there.
+	again, err := MergeCustomStubs(merged, response)
+	if err != nil {
+		t.Fatalf("MergeCustomStubs() failed on the merged file: %v", err)
+	}
+	if string(again) != mergedStr {
+		t.Errorf("merging again changed the file:\n%s", again)
+	}
+
+	// A deprecated stub referenced again loses its deprecation.
+	response.GeneratedCode = []byte(`package directives
+
+var _ = ConvertKindToString
+`)
+	revived, err := MergeCustomStubs(merged, &model.GenerationResponse{GeneratedCode: response.GeneratedCode})
+	if err != nil {
+		t.Fatalf("MergeCustomStubs() failed: %v", err)
+	}
+	if strings.Contains(string(revived), "Deprecated: unused by abgen\nfunc ConvertKindToString") ||
+		!strings.Contains(string(revived), "// Please implement this function to complete the conversion.\nfunc ConvertKindToString") {
+		t.Errorf("ConvertKindToString is still deprecated:\n%s", revived)
+	}
+	if !strings.Contains(string(revived), "// Deprecated: unused by abgen\nfunc ConvertStatusToString") {
+		t.Errorf("ConvertStatusToString is not deprecated:\n%s", revived)
+	}
+}
+
+func TestMergeCustomStubs_NoExistingFile(t *testing.T) {
+	response := &model.GenerationResponse{CustomStubs: []byte(generatedStubs)}
+	merged, err := MergeCustomStubs(nil, response)
+	if err != nil {
+		t.Fatalf("MergeCustomStubs() failed: %v", err)
+	}
+	if string(merged) != generatedStubs {
+		t.Errorf("MergeCustomStubs() = %s, want the generated stubs", merged)
+	}
+}
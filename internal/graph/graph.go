@@ -0,0 +1,224 @@
+// Package graph builds the graph of the conversions generated by abgen, from the types
+// converted to the types of their fields, and writes it in the DOT and Mermaid formats.
+package graph
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/origadmin/abgen/internal/model"
+)
+
+// Graph is a graph of the types converted by generated code. An edge goes from a struct type
+// to the type of one of its fields, when converting that field needs more than an assignment
+// or a type conversion.
+type Graph struct {
+	// Nodes are the keys of the types of the graph, sorted.
+	Nodes []string
+	Edges []*Edge
+}
+
+// Edge is a field converting from one type of the graph to another. Cycle tells whether the
+// edge belongs to a cycle of conversions.
+type Edge struct {
+	From     string
+	To       string
+	Field    string
+	Strategy model.FieldStrategy
+	Cycle    bool
+}
+
+// Build builds the graph of the given conversions. Each edge is labeled by the path of the
+// field of the source struct it converts.
+func Build(conversions []*model.ConversionReport) *Graph {
+	g := &Graph{}
+	nodes := make(map[string]bool)
+	edges := make(map[string]bool)
+	addNode := func(key string) {
+		if !nodes[key] {
+			nodes[key] = true
+			g.Nodes = append(g.Nodes, key)
+		}
+	}
+	for _, conversion := range conversions {
+		addNode(conversion.Source)
+		for _, field := range conversion.Fields {
+			switch field.Strategy {
+			case model.StrategyNested, model.StrategyCustom, model.StrategyHelper, model.StrategyStub:
+			default:
+				continue
+			}
+			edge := &Edge{From: conversion.Source, To: field.SourceType, Field: field.Source, Strategy: field.Strategy}
+			key := strings.Join([]string{edge.From, edge.To, edge.Field, string(edge.Strategy)}, "\x00")
+			if edges[key] {
+				continue
+			}
+			edges[key] = true
+			addNode(edge.To)
+			g.Edges = append(g.Edges, edge)
+		}
+	}
+
+	sort.Strings(g.Nodes)
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Field < b.Field
+	})
+	g.markCycles()
+	return g
+}
+
+// markCycles marks the edges between the types of a strongly connected component, found
+// with Tarjan's algorithm, and the edges from a type to itself.
+func (g *Graph) markCycles() {
+	successors := make(map[string][]string)
+	for _, edge := range g.Edges {
+		successors[edge.From] = append(successors[edge.From], edge.To)
+	}
+
+	index := make(map[string]int)
+	lowLink := make(map[string]int)
+	onStack := make(map[string]bool)
+	component := make(map[string]int)
+	var stack []string
+	components := 0
+
+	var connect func(node string)
+	connect = func(node string) {
+		index[node] = len(index)
+		lowLink[node] = index[node]
+		stack = append(stack, node)
+		onStack[node] = true
+		for _, next := range successors[node] {
+			if _, visited := index[next]; !visited {
+				connect(next)
+				lowLink[node] = min(lowLink[node], lowLink[next])
+			} else if onStack[next] {
+				lowLink[node] = min(lowLink[node], index[next])
+			}
+		}
+		if lowLink[node] != index[node] {
+			return
+		}
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component[top] = components
+			if top == node {
+				break
+			}
+		}
+		components++
+	}
+	for _, node := range g.Nodes {
+		if _, visited := index[node]; !visited {
+			connect(node)
+		}
+	}
+
+	for _, edge := range g.Edges {
+		edge.Cycle = component[edge.From] == component[edge.To]
+	}
+}
+
+// packagePath matches the directories of the package paths in type keys.
+var packagePath = regexp.MustCompile(`(?:[\w.\-]+/)+`)
+
+// label returns the name of a type as written in Go code, qualified by its package name.
+func label(key string) string {
+	return packagePath.ReplaceAllString(key, "")
+}
+
+// edgeColors are the colors of the edges of each strategy; the other edges are black.
+var edgeColors = map[model.FieldStrategy]string{
+	model.StrategyCustom: "blue",
+	model.StrategyHelper: "gray",
+	model.StrategyStub:   "red",
+}
+
+// dotStyles are the DOT styles of the edges of each strategy; the other edges are solid.
+var dotStyles = map[model.FieldStrategy]string{
+	model.StrategyHelper: "dashed",
+	model.StrategyStub:   "dotted",
+}
+
+// WriteDOT writes the graph in the DOT language of Graphviz. Custom functions are blue,
+// helpers gray and dashed, stubs red and dotted, and the edges of cycles are thick.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var buf strings.Builder
+	buf.WriteString("digraph abgen {\n\trankdir=LR;\n\tnode [shape=box];\n\n")
+	for _, node := range g.Nodes {
+		buf.WriteString(fmt.Sprintf("\t%s [label=%s];\n", strconv.Quote(node), strconv.Quote(label(node))))
+	}
+	if len(g.Edges) > 0 {
+		buf.WriteString("\n")
+	}
+	for _, edge := range g.Edges {
+		attrs := []string{"label=" + strconv.Quote(edge.Field)}
+		if color, ok := edgeColors[edge.Strategy]; ok {
+			attrs = append(attrs, "color="+strconv.Quote(color))
+		}
+		style := dotStyles[edge.Strategy]
+		if edge.Cycle {
+			attrs = append(attrs, "penwidth=2")
+			if style == "" {
+				style = "bold"
+			}
+		}
+		if style != "" {
+			attrs = append(attrs, "style="+strconv.Quote(style))
+		}
+		buf.WriteString(fmt.Sprintf("\t%s -> %s [%s];\n", strconv.Quote(edge.From), strconv.Quote(edge.To), strings.Join(attrs, ", ")))
+	}
+	buf.WriteString("}\n")
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart. Custom functions are blue, helpers
+// gray and dotted, stubs red and dotted, and the edges of cycles are thick.
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	var buf strings.Builder
+	buf.WriteString("flowchart LR\n")
+	ids := make(map[string]string, len(g.Nodes))
+	for i, node := range g.Nodes {
+		ids[node] = fmt.Sprintf("n%d", i)
+		buf.WriteString(fmt.Sprintf("\t%s[%s]\n", ids[node], mermaidText(label(node))))
+	}
+	var linkStyles []string
+	for i, edge := range g.Edges {
+		arrow := "-->"
+		switch {
+		case edge.Cycle:
+			arrow = "==>"
+		case edge.Strategy == model.StrategyHelper || edge.Strategy == model.StrategyStub:
+			arrow = "-.->"
+		}
+		buf.WriteString(fmt.Sprintf("\t%s %s|%s| %s\n", ids[edge.From], arrow, mermaidText(edge.Field), ids[edge.To]))
+		if color, ok := edgeColors[edge.Strategy]; ok {
+			linkStyles = append(linkStyles, fmt.Sprintf("\tlinkStyle %d stroke:%s\n", i, color))
+		}
+	}
+	for _, linkStyle := range linkStyles {
+		buf.WriteString(linkStyle)
+	}
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+// mermaidText quotes a node or edge text of a Mermaid flowchart, so that the brackets and
+// asterisks of type names are not read as syntax.
+func mermaidText(text string) string {
+	return `"` + strings.ReplaceAll(text, `"`, "#quot;") + `"`
+}
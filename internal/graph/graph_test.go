@@ -0,0 +1,120 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/origadmin/abgen/internal/model"
+)
+
+func testConversions() []*model.ConversionReport {
+	return []*model.ConversionReport{
+		{
+			Function: "ConvertUserToUserPB",
+			Source:   "example.com/ent.User",
+			Target:   "example.com/pb.User",
+			Fields: []*model.FieldMapping{
+				{Target: "ID", Source: "ID", SourceType: "int", Strategy: model.StrategyDirect},
+				{Target: "Age", Source: "Age", SourceType: "int", Strategy: model.StrategyCast},
+				{Target: "Roles", Source: "Edges.Roles", SourceType: "example.com/ent.Role", Strategy: model.StrategyNested},
+				{Target: "CreatedAt", Source: "CreatedAt", SourceType: "time.Time", Strategy: model.StrategyHelper},
+				{Target: "Status", Source: "Status", SourceType: "string", Strategy: model.StrategyStub},
+				{Target: "Secret", Strategy: model.StrategyIgnored},
+			},
+		},
+		{
+			Function: "ConvertRoleToRolePB",
+			Source:   "example.com/ent.Role",
+			Target:   "example.com/pb.Role",
+			Fields: []*model.FieldMapping{
+				{Target: "Owner", Source: "Owner", SourceType: "example.com/ent.User", Strategy: model.StrategyNested},
+				{Target: "Code", Source: "Code", SourceType: "string", Strategy: model.StrategyCustom},
+			},
+		},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	g := Build(testConversions())
+
+	wantNodes := []string{"example.com/ent.Role", "example.com/ent.User", "string", "time.Time"}
+	if strings.Join(g.Nodes, " ") != strings.Join(wantNodes, " ") {
+		t.Errorf("Build() nodes = %v, want %v", g.Nodes, wantNodes)
+	}
+
+	type edge struct {
+		from, to, field string
+		strategy        model.FieldStrategy
+		cycle           bool
+	}
+	want := []edge{
+		{"example.com/ent.Role", "example.com/ent.User", "Owner", model.StrategyNested, true},
+		{"example.com/ent.Role", "string", "Code", model.StrategyCustom, false},
+		{"example.com/ent.User", "example.com/ent.Role", "Edges.Roles", model.StrategyNested, true},
+		{"example.com/ent.User", "string", "Status", model.StrategyStub, false},
+		{"example.com/ent.User", "time.Time", "CreatedAt", model.StrategyHelper, false},
+	}
+	if len(g.Edges) != len(want) {
+		t.Fatalf("Build() has %d edges, want %d", len(g.Edges), len(want))
+	}
+	for i, e := range g.Edges {
+		got := edge{e.From, e.To, e.Field, e.Strategy, e.Cycle}
+		if got != want[i] {
+			t.Errorf("Build() edge %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestBuild_SelfReference(t *testing.T) {
+	g := Build([]*model.ConversionReport{{
+		Source: "example.com/ent.Person",
+		Fields: []*model.FieldMapping{
+			{Source: "Friends", SourceType: "example.com/ent.Person", Strategy: model.StrategyNested},
+		},
+	}})
+	if len(g.Edges) != 1 || !g.Edges[0].Cycle {
+		t.Errorf("Build() edges = %+v, want a single cycle", g.Edges)
+	}
+}
+
+func TestGraph_WriteDOT(t *testing.T) {
+	var buf strings.Builder
+	if err := Build(testConversions()).WriteDOT(&buf); err != nil {
+		t.Fatalf("WriteDOT() error = %v", err)
+	}
+	for _, want := range []string{
+		`"example.com/ent.User" [label="ent.User"];`,
+		`"example.com/ent.User" -> "example.com/ent.Role" [label="Edges.Roles", penwidth=2, style="bold"];`,
+		`"example.com/ent.Role" -> "string" [label="Code", color="blue"];`,
+		`"example.com/ent.User" -> "time.Time" [label="CreatedAt", color="gray", style="dashed"];`,
+		`"example.com/ent.User" -> "string" [label="Status", color="red", style="dotted"];`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("WriteDOT() output does not contain %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestGraph_WriteMermaid(t *testing.T) {
+	var buf strings.Builder
+	if err := Build(testConversions()).WriteMermaid(&buf); err != nil {
+		t.Fatalf("WriteMermaid() error = %v", err)
+	}
+	want := `flowchart LR
+	n0["ent.Role"]
+	n1["ent.User"]
+	n2["string"]
+	n3["time.Time"]
+	n0 ==>|"Owner"| n1
+	n0 -->|"Code"| n2
+	n1 ==>|"Edges.Roles"| n0
+	n1 -.->|"Status"| n2
+	n1 -.->|"CreatedAt"| n3
+	linkStyle 1 stroke:blue
+	linkStyle 3 stroke:red
+	linkStyle 4 stroke:gray
+`
+	if buf.String() != want {
+		t.Errorf("WriteMermaid() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,49 @@
+package model
+
+import "github.com/origadmin/abgen/internal/config"
+
+// FieldStrategy tells how the generated code sets a target field.
+type FieldStrategy string
+
+const (
+	// StrategyDirect assigns the source field, or its address or pointed-to value.
+	StrategyDirect FieldStrategy = "direct"
+	// StrategyCast converts the source field with a Go type conversion.
+	StrategyCast FieldStrategy = "cast"
+	// StrategyHelper calls a helper function: a built-in, time, JSON or SQL null helper.
+	StrategyHelper FieldStrategy = "helper"
+	// StrategyNested calls another generated conversion or deep copy function.
+	StrategyNested FieldStrategy = "nested"
+	// StrategyCustom calls a conversion function declared by the user.
+	StrategyCustom FieldStrategy = "custom"
+	// StrategyStub calls a custom conversion stub the user has to implement.
+	StrategyStub FieldStrategy = "stub"
+	// StrategyIgnored leaves a target field ignored by the rules unset.
+	StrategyIgnored FieldStrategy = "ignored"
+	// StrategyUnmapped leaves a target field without a matching source field unset.
+	StrategyUnmapped FieldStrategy = "unmapped"
+)
+
+// FieldMapping records how a target field of a struct conversion is set. SourceType and
+// TargetType are the types converted, those of the fields or of their elements for pointers,
+// slices and arrays. Source, SourceType and Expression are empty for the fields that are
+// left unset.
+type FieldMapping struct {
+	Target     string        `json:"target"`
+	TargetType string        `json:"targetType"`
+	Source     string        `json:"source,omitempty"`
+	SourceType string        `json:"sourceType,omitempty"`
+	Expression string        `json:"expression,omitempty"`
+	Strategy   FieldStrategy `json:"strategy"`
+}
+
+// ConversionReport records a struct conversion function generated by abgen and how it sets
+// each field of its target type. Origin is the origin of the rule the function was generated
+// for, and is empty for the conversions generated because other conversions needed them.
+type ConversionReport struct {
+	Function string            `json:"function"`
+	Source   string            `json:"source"`
+	Target   string            `json:"target"`
+	Origin   config.RuleOrigin `json:"origin,omitempty"`
+	Fields   []*FieldMapping   `json:"fields"`
+}
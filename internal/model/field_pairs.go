@@ -0,0 +1,132 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/origadmin/abgen/internal/config"
+)
+
+// FieldPair is a source field matched with the target field its value converts to.
+// SourcePath is the selector of the source field relative to the source struct, such as
+// "Name", or "Edges.Owner" for fields found in the Edges struct of ent entities.
+type FieldPair struct {
+	Source     *FieldInfo
+	Target     *FieldInfo
+	SourcePath string
+}
+
+// PairFields matches the fields of the source and target structs, in target field order.
+// Remap is keyed by source field names and Ignore names fields of either struct. Fields are
+// otherwise matched by case-insensitive name, falling back to the fields of the source's
+// Edges struct.
+func PairFields(source, target *TypeInfo, rules config.FieldRuleSet) []*FieldPair {
+	source = structOf(source)
+	target = structOf(target)
+	if source == nil || target == nil {
+		return nil
+	}
+
+	remappedFrom := make(map[string]string, len(rules.Remap))
+	for from, to := range rules.Remap {
+		remappedFrom[to] = from
+	}
+	ignored := func(name string) bool {
+		_, ok := rules.Ignore[name]
+		return ok
+	}
+
+	var edges *TypeInfo
+	if edgesField := lookupField(source, "Edges"); edgesField != nil {
+		edges = structOf(edgesField.Type)
+	}
+
+	var pairs []*FieldPair
+	for _, targetField := range target.Fields {
+		if ignored(targetField.Name) {
+			continue
+		}
+
+		sourceName, remapped := remappedFrom[targetField.Name]
+		if !remapped {
+			// A source field remapped to another target field is not matched by name.
+			if to, ok := rules.Remap[targetField.Name]; ok && !strings.EqualFold(to, targetField.Name) {
+				continue
+			}
+			sourceName = targetField.Name
+		}
+
+		pair := &FieldPair{Target: targetField}
+		if sourceField := lookupField(source, sourceName); sourceField != nil {
+			pair.Source = sourceField
+			pair.SourcePath = sourceField.Name
+		} else if edgeField := lookupField(edges, sourceName); edgeField != nil {
+			pair.Source = edgeField
+			pair.SourcePath = "Edges." + edgeField.Name
+		} else {
+			continue
+		}
+		if ignored(pair.Source.Name) {
+			continue
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs
+}
+
+// structOf returns the struct type info behind named types and pointers, or nil.
+func structOf(info *TypeInfo) *TypeInfo {
+	for info != nil && info.Kind != Struct {
+		if info.Kind != Pointer && info.Kind != Named {
+			return nil
+		}
+		info = info.Underlying
+	}
+	return info
+}
+
+// lookupField finds a field of a struct by exact or case-insensitive name.
+func lookupField(info *TypeInfo, name string) *FieldInfo {
+	if info == nil {
+		return nil
+	}
+	for _, field := range info.Fields {
+		if field.Name == name || strings.EqualFold(field.Name, name) {
+			return field
+		}
+	}
+	return nil
+}
+
+// UnpairedFields returns the target fields PairFields matches with no source field, in
+// target field order, split between those ignored by the rules and those left unmapped.
+func UnpairedFields(source, target *TypeInfo, rules config.FieldRuleSet) (ignored, unmapped []*FieldInfo) {
+	target = structOf(target)
+	if structOf(source) == nil || target == nil {
+		return nil, nil
+	}
+	paired := make(map[string]bool)
+	for _, pair := range PairFields(source, target, rules) {
+		paired[pair.Target.Name] = true
+	}
+	remappedFrom := make(map[string]string, len(rules.Remap))
+	for from, to := range rules.Remap {
+		remappedFrom[to] = from
+	}
+	for _, field := range target.Fields {
+		if paired[field.Name] {
+			continue
+		}
+		sourceName, remapped := remappedFrom[field.Name]
+		if !remapped {
+			sourceName = field.Name
+		}
+		_, ignoresTarget := rules.Ignore[field.Name]
+		_, ignoresSource := rules.Ignore[sourceName]
+		if ignoresTarget || ignoresSource {
+			ignored = append(ignored, field)
+		} else {
+			unmapped = append(unmapped, field)
+		}
+	}
+	return ignored, unmapped
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/origadmin/abgen/internal/config"
+)
+
+func TestPairFields(t *testing.T) {
+	str := &TypeInfo{Kind: Primitive, Name: "string"}
+	field := func(name string) *FieldInfo { return &FieldInfo{Name: name, Type: str} }
+	owner := field("Owner")
+
+	user := &TypeInfo{Kind: Struct, Name: "User", ImportPath: "a", Fields: []*FieldInfo{
+		field("ID"), field("Name"), field("Email"), field("Password"),
+		{Name: "Edges", Type: &TypeInfo{Kind: Struct, Name: "UserEdges", ImportPath: "a", Fields: []*FieldInfo{owner}}},
+	}}
+	userDTO := &TypeInfo{Kind: Struct, Name: "UserDTO", ImportPath: "b", Fields: []*FieldInfo{
+		field("Id"), field("FullName"), field("Name"), field("Email"), field("Password"), field("Owner"),
+	}}
+	rules := config.FieldRuleSet{
+		Ignore: map[string]struct{}{"Password": {}},
+		Remap:  map[string]string{"Name": "FullName"},
+	}
+
+	paths := func(pairs []*FieldPair) map[string]string {
+		got := make(map[string]string)
+		for _, pair := range pairs {
+			got[pair.Target.Name] = pair.SourcePath
+		}
+		return got
+	}
+
+	t.Run("forward", func(t *testing.T) {
+		pointer := &TypeInfo{Kind: Pointer, Underlying: user}
+		want := map[string]string{"Id": "ID", "FullName": "Name", "Email": "Email", "Owner": "Edges.Owner"}
+		if got := paths(PairFields(pointer, userDTO, rules)); !reflect.DeepEqual(got, want) {
+			t.Errorf("PairFields() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("reverse", func(t *testing.T) {
+		// Ignored fields are only ignored in the direction of the rule.
+		want := map[string]string{"ID": "Id", "Name": "FullName", "Email": "Email", "Password": "Password"}
+		if got := paths(PairFields(userDTO, user, rules.Reverse())); !reflect.DeepEqual(got, want) {
+			t.Errorf("PairFields() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("non-struct", func(t *testing.T) {
+		if got := PairFields(str, userDTO, rules); got != nil {
+			t.Errorf("PairFields() = %v, want nil", got)
+		}
+	})
+}
+
+func TestUnpairedFields(t *testing.T) {
+	str := &TypeInfo{Kind: Primitive, Name: "string"}
+	field := func(name string) *FieldInfo { return &FieldInfo{Name: name, Type: str} }
+
+	user := &TypeInfo{Kind: Struct, Name: "User", ImportPath: "a", Fields: []*FieldInfo{
+		field("ID"), field("Name"), field("Secret"), field("Password"),
+	}}
+	userDTO := &TypeInfo{Kind: Struct, Name: "UserDTO", ImportPath: "b", Fields: []*FieldInfo{
+		field("ID"), field("FullName"), field("Name"), field("Token"), field("Password"), field("Avatar"),
+	}}
+	rules := config.FieldRuleSet{
+		Ignore: map[string]struct{}{"Password": {}, "Secret": {}},
+		Remap:  map[string]string{"Name": "FullName", "Secret": "Token"},
+	}
+
+	names := func(fields []*FieldInfo) []string {
+		var got []string
+		for _, field := range fields {
+			got = append(got, field.Name)
+		}
+		return got
+	}
+
+	ignored, unmapped := UnpairedFields(user, userDTO, rules)
+	if want := []string{"Token", "Password"}; !reflect.DeepEqual(names(ignored), want) {
+		t.Errorf("UnpairedFields() ignored = %v, want %v", names(ignored), want)
+	}
+	if want := []string{"Name", "Avatar"}; !reflect.DeepEqual(names(unmapped), want) {
+		t.Errorf("UnpairedFields() unmapped = %v, want %v", names(unmapped), want)
+	}
+}
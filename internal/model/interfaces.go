@@ -13,13 +13,19 @@ import (
 type GenerationResponse struct {
 	GeneratedCode    []byte
 	CustomStubs      []byte
+	TestCode         []byte
 	RequiredPackages []string
+	// Conversions records the struct conversions of the generated code, in generation order.
+	Conversions []*ConversionReport
 }
 
 // GeneratedCode holds information about a generated code snippet.
 type GeneratedCode struct {
 	FunctionBody    string
 	RequiredHelpers []Helper
+	// Fields records how a struct conversion sets each target field. It is nil for the
+	// other functions.
+	Fields []*FieldMapping
 }
 
 // AliasRenderInfo holds information for rendering a type alias.
@@ -38,6 +44,8 @@ type Planner interface {
 // TypeAnalyzer defines the interface for the type analysis component.
 type TypeAnalyzer interface {
 	Analyze(sourceDir string) (*AnalysisResult, error)
+	AnalyzePackages(sourceDirs []string) ([]*AnalysisResult, error)
+	AnalyzePackagePair(sourcePath, targetPath string) (*AnalysisResult, error)
 }
 
 // ImportManager defines the interface for managing and generating import statements.
@@ -58,6 +66,18 @@ type AliasLookup interface {
 type NameGenerator interface {
 	ConversionFunctionName(source, target *TypeInfo) string
 	FieldConversionFunctionName(sourceParent, targetParent *TypeInfo, sourceField, targetField *FieldInfo) string
+	DeepCopyFunctionName(info *TypeInfo) string
+	EqualFunctionName(source, target *TypeInfo) string
+	DiffFunctionName(source, target *TypeInfo) string
+	RoundTripTestName(source, target *TypeInfo) string
+	Err() error
+}
+
+// NamingStrategy defines the interface for turning type names into the words of generated
+// identifiers.
+type NamingStrategy interface {
+	CamelCase(name string) string
+	Plural(name string) string
 }
 
 // AliasManager defines the interface for creating and managing local type aliases.
@@ -77,9 +97,16 @@ type AliasManager interface {
 type ConversionEngine interface {
 	GenerateConversionFunction(source, target *TypeInfo, rule *config.ConversionRule) (*GeneratedCode, []*ConversionTask, error)
 	GenerateSliceConversion(source, target *TypeInfo) (*GeneratedCode, []*ConversionTask, error)
+	GenerateComparisonFunctions(source, target *TypeInfo, rule *config.ConversionRule) (*GeneratedCode, []*ConversionTask, error)
+	GenerateConverterImplementation(converter *ConverterInterface) (*GeneratedCode, []*ConversionTask, error)
 	GetStubsToGenerate() map[string]*ConversionTask
 }
 
+// TestGenerator defines the interface for generating the tests of generated conversions.
+type TestGenerator interface {
+	GenerateRoundTripTests(rules []*config.ConversionRule, typeInfos map[string]*TypeInfo) ([]string, error)
+}
+
 // CodeEmitter defines the interface for writing the various sections of the final
 // generated Go file.
 type CodeEmitter interface {
@@ -33,22 +33,103 @@ type AnalysisResult struct {
 	TypeInfos         map[string]*TypeInfo
 	ExistingFunctions map[string]bool
 	ExistingAliases   map[string]string
+	ConversionFuncs   map[string]*ConversionFunc
+	HelperFuncs       map[string]*ConversionFunc
+	CustomFuncs       map[string]*ConversionFunc
+	Converters        []*ConverterInterface
 	ExecutionPlan     *ExecutionPlan
 }
 
 // ExecutionPlan contains the finalized configuration and rules for generation.
+// Methods holds the conversions generated as methods, keyed by "<source FQN>-><target FQN>".
 type ExecutionPlan struct {
 	FinalConfig *config.Config
 	ActiveRules []*config.ConversionRule
+	Methods     map[string]*ConversionMethod
+}
+
+// ConversionMethod describes a conversion generated as a method of a struct type defined in
+// the directive package. A To method converts its receiver, of the source type, to the
+// target type. A From method sets its receiver, of the target type, to the conversion of its
+// argument.
+type ConversionMethod struct {
+	Name string
+	From bool
+}
+
+// ConversionMethod returns the method generated for the conversion from source to target,
+// or nil if the conversion is a free function. Pointers to structs share the method of
+// their struct type.
+func (p *ExecutionPlan) ConversionMethod(source, target *TypeInfo) *ConversionMethod {
+	if p == nil || len(p.Methods) == 0 || source == nil || target == nil {
+		return nil
+	}
+	if source.Kind == Pointer {
+		source = source.Underlying
+	}
+	if target.Kind == Pointer {
+		target = target.Underlying
+	}
+	return p.Methods[source.FQN()+"->"+target.FQN()]
 }
 
 // Helper represents a built-in conversion function.
+// Package is the import path of the runtime package that provides the helper; Body is
+// its source, which is copied into the generated file when helpers are emitted inline.
+// Requires names the other helpers that Body calls. ReturnsError is set for helpers
+// returning (T, error), whose errors are handled according to the error mode.
 type Helper struct {
 	Name         string
 	SourceType   string
 	TargetType   string
 	Body         string
+	Package      string
 	Dependencies []string
+	Requires     []string
+	ReturnsError bool
+}
+
+// ConversionFunc describes a user-defined function whose signature is func(A) B or
+// func(A) (B, error), making it usable to convert A to B.
+// SourceType and TargetType hold the unique keys of A and B. ImportPath is empty for
+// functions in the directive package and holds the package path for helper packages.
+type ConversionFunc struct {
+	Name         string
+	ImportPath   string
+	SourceType   string
+	TargetType   string
+	ReturnsError bool
+}
+
+// QualifiedName returns the function name prefixed with its import path, if any.
+func (cf *ConversionFunc) QualifiedName() string {
+	if cf.ImportPath == "" {
+		return cf.Name
+	}
+	return cf.ImportPath + "." + cf.Name
+}
+
+// ConverterInterface describes an interface of the directive package marked with
+// //go:abgen:implement. StructName is the name of the generated struct implementing it.
+type ConverterInterface struct {
+	Name       string
+	StructName string
+	Methods    []*ConverterMethod
+}
+
+// ConverterMethod describes a method of a converter interface, whose signature is
+// func(A) B or func(A) (B, error). Interface is the name of the interface declaring it.
+type ConverterMethod struct {
+	Interface    string
+	Name         string
+	Source       *TypeInfo
+	Target       *TypeInfo
+	ReturnsError bool
+}
+
+// QualifiedName returns the method name prefixed with the name of its interface.
+func (cm *ConverterMethod) QualifiedName() string {
+	return cm.Interface + "." + cm.Name
 }
 
 // TypeInfo represents the detailed information of a resolved Go type.
@@ -63,13 +144,28 @@ type TypeInfo struct {
 	Fields     []*FieldInfo
 	Methods    []*MethodInfo
 	Original   types.Object
+	TypeArgs   []*TypeInfo // Type arguments of an instantiated generic type, such as sql.Null[string].
 }
 
 // ConversionTask represents a task for the code generator to create a conversion function.
+// Compare marks tasks creating the Equal and Diff functions of the type pair instead.
+// Origin names the converter interface method the task was created for, if any.
 type ConversionTask struct {
-	Source *TypeInfo
-	Target *TypeInfo
-	Rule   *config.ConversionRule
+	Source  *TypeInfo
+	Target  *TypeInfo
+	Rule    *config.ConversionRule
+	Compare bool
+	Origin  string
+}
+
+// AnyMapType returns the type information for map[string]any, the map type that struct
+// types named by convert:map directives are converted to and from.
+func AnyMapType() *TypeInfo {
+	return &TypeInfo{
+		Kind:       Map,
+		KeyType:    &TypeInfo{Kind: Primitive, Name: "string"},
+		Underlying: &TypeInfo{Kind: Interface, Name: "interface{}"},
+	}
 }
 
 // GetElementType returns the ultimate element type of pointers, slices, and arrays.
@@ -176,6 +272,15 @@ func (ti *TypeInfo) Equals(other *TypeInfo) bool {
 		return false
 	}
 
+	if len(ti.TypeArgs) != len(other.TypeArgs) {
+		return false
+	}
+	for i, arg := range ti.TypeArgs {
+		if !arg.Equals(other.TypeArgs[i]) {
+			return false
+		}
+	}
+
 	if ti.Kind == Named && other.Kind == Named {
 		return ti.FQN() == other.FQN()
 	}
@@ -272,7 +377,7 @@ func (ti *TypeInfo) UniqueKey() string {
 	}
 
 	if ti.ImportPath != "" && ti.Name != "" {
-		return ti.FQN()
+		return ti.FQN() + ti.typeArgsString(true)
 	}
 
 	return ti.buildTypeString(true)
@@ -288,7 +393,7 @@ func (ti *TypeInfo) buildTypeString(isUniqueKeyMode bool) string {
 	}
 
 	if isUniqueKeyMode && ti.ImportPath != "" && ti.Name != "" {
-		return ti.FQN()
+		return ti.FQN() + ti.typeArgsString(true)
 	}
 
 	var sb strings.Builder
@@ -368,6 +473,19 @@ func (ti *TypeInfo) buildTypeName(sb *strings.Builder, isUniqueKeyMode bool) {
 		}
 	}
 	sb.WriteString(ti.Name)
+	sb.WriteString(ti.typeArgsString(isUniqueKeyMode))
+}
+
+// typeArgsString renders the type arguments of an instantiated generic type, e.g. "[string]".
+func (ti *TypeInfo) typeArgsString(isUniqueKeyMode bool) string {
+	if len(ti.TypeArgs) == 0 {
+		return ""
+	}
+	args := make([]string, len(ti.TypeArgs))
+	for i, arg := range ti.TypeArgs {
+		args[i] = ti.getTypeRepresentation(arg, isUniqueKeyMode)
+	}
+	return "[" + strings.Join(args, ", ") + "]"
 }
 
 // MethodInfo represents a single method of a type.
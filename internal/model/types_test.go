@@ -383,6 +383,35 @@ func TestTypeInfoStringRepresentations(t *testing.T) {
 	}
 }
 
+// TestTypeInfoTypeArgs tests that instantiated generic types keep their type arguments
+func TestTypeInfoTypeArgs(t *testing.T) {
+	newNull := func(arg string) *TypeInfo {
+		return &TypeInfo{
+			Kind:       Struct,
+			Name:       "Null",
+			ImportPath: "database/sql",
+			TypeArgs:   []*TypeInfo{{Kind: Primitive, Name: arg}},
+		}
+	}
+	nullString := newNull("string")
+
+	if got, want := nullString.UniqueKey(), "database/sql.Null[string]"; got != want {
+		t.Errorf("UniqueKey() = %v, want %v", got, want)
+	}
+	if got, want := nullString.TypeString(), "sql.Null[string]"; got != want {
+		t.Errorf("TypeString() = %v, want %v", got, want)
+	}
+	if got, want := nullString.FQN(), "database/sql.Null"; got != want {
+		t.Errorf("FQN() = %v, want %v", got, want)
+	}
+	if !nullString.Equals(newNull("string")) {
+		t.Error("Equals() = false for identical instantiations")
+	}
+	if nullString.Equals(newNull("int64")) {
+		t.Error("Equals() = true for different type arguments")
+	}
+}
+
 // TestTypeInfoComplexTypes tests string representations for complex types
 func TestTypeInfoComplexTypes(t *testing.T) {
 	// Create base types
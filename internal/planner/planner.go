@@ -2,6 +2,7 @@ package planner
 
 import (
 	"fmt"
+	"go/types"
 	"log/slog"
 	"sort"
 	"strings"
@@ -27,11 +28,18 @@ func (p *Planner) Plan(initialConfig *config.Config, typeInfos map[string]*model
 	finalConfig := initialConfig.Clone()
 	slog.Debug("Planner: Starting to create execution plan", "initial_rules", len(finalConfig.ConversionRules))
 
-	// Start with implicitly discovered rules from package pairs
-	allRules := p.findSeedRules(typeInfos, finalConfig.PackagePairs)
+	// Start with explicit rules from the config, which take precedence over the rules
+	// discovered for the same types, so that their field rules apply
+	allRules := append([]*config.ConversionRule(nil), finalConfig.ConversionRules...)
 
-	// Add explicit rules from the config
-	allRules = append(allRules, finalConfig.ConversionRules...)
+	// Add implicitly discovered rules from package pairs
+	allRules = append(allRules, p.findSeedRules(typeInfos, finalConfig.PackagePairs)...)
+
+	// Add struct <-> map[string]any rules for the types named by convert:map
+	allRules = append(allRules, p.mapRules(finalConfig.MapTypes, typeInfos)...)
+
+	// Add the rules generating DeepCopy functions for the types named by deepcopy
+	allRules = append(allRules, p.deepCopyRules(finalConfig.DeepCopyTypes, typeInfos)...)
 
 	// Expand all rules to find dependencies by analyzing struct fields
 	activeRules := p.expandRulesByDependencyAnalysis(allRules, typeInfos)
@@ -53,6 +61,7 @@ func (p *Planner) Plan(initialConfig *config.Config, typeInfos map[string]*model
 	return &model.ExecutionPlan{
 		FinalConfig: finalConfig,
 		ActiveRules: activeRules,
+		Methods:     p.conversionMethods(finalConfig, activeRules, typeInfos),
 	}
 }
 
@@ -80,26 +89,9 @@ func (p *Planner) expandRulesByDependencyAnalysis(seedRules []*config.Conversion
 			continue
 		}
 
-		for _, sourceField := range sourceInfo.Fields {
-			targetFieldName := sourceField.Name
-			if remappedName, ok := rule.FieldRules.Remap[sourceField.Name]; ok {
-				targetFieldName = remappedName
-			}
-
-			var targetField *model.FieldInfo
-			for _, tf := range targetInfo.Fields {
-				if tf.Name == targetFieldName || strings.EqualFold(tf.Name, targetFieldName) {
-					targetField = tf
-					break
-				}
-			}
-
-			if targetField == nil {
-				continue
-			}
-
-			baseSourceType := p.typeConverter.GetElementType(sourceField.Type)
-			baseTargetType := p.typeConverter.GetElementType(targetField.Type)
+		for _, pair := range model.PairFields(sourceInfo, targetInfo, rule.FieldRules) {
+			baseSourceType := p.typeConverter.GetElementType(pair.Source.Type)
+			baseTargetType := p.typeConverter.GetElementType(pair.Target.Type)
 
 			if baseSourceType == nil || baseTargetType == nil || baseSourceType.UniqueKey() == baseTargetType.UniqueKey() {
 				continue
@@ -109,6 +101,7 @@ func (p *Planner) expandRulesByDependencyAnalysis(seedRules []*config.Conversion
 				SourceType: baseSourceType.UniqueKey(),
 				TargetType: baseTargetType.UniqueKey(),
 				Direction:  config.DirectionBoth,
+				Origin:     config.OriginDependency,
 			}
 
 			key := fmt.Sprintf("%s->%s", newRule.SourceType, newRule.TargetType)
@@ -154,6 +147,7 @@ func (p *Planner) findSeedRules(typeInfos map[string]*model.TypeInfo, packagePai
 					SourceType: sourceType.UniqueKey(),
 					TargetType: targetType.UniqueKey(),
 					Direction:  config.DirectionBoth,
+					Origin:     config.OriginPackagePair,
 				}
 				slog.Debug("Planner: Created seed rule", "source", rule.SourceType, "target", rule.TargetType, "direction", rule.Direction)
 				seedRules = append(seedRules, rule)
@@ -163,6 +157,129 @@ func (p *Planner) findSeedRules(typeInfos map[string]*model.TypeInfo, packagePai
 	return seedRules
 }
 
+// mapRules creates the rules converting each struct type in mapTypes to and from map[string]any.
+// Nested structs are converted by tasks the conversion engine creates while generating these rules.
+func (p *Planner) mapRules(mapTypes []string, typeInfos map[string]*model.TypeInfo) []*config.ConversionRule {
+	var rules []*config.ConversionRule
+	mapKey := model.AnyMapType().UniqueKey()
+	for _, fqn := range mapTypes {
+		if info := typeInfos[fqn]; info == nil || !info.IsUltimatelyStruct() {
+			slog.Warn("Planner: convert:map requires a struct type, skipping", "type", fqn)
+			continue
+		}
+		rules = append(rules, &config.ConversionRule{
+			SourceType: fqn,
+			TargetType: mapKey,
+			Direction:  config.DirectionBoth,
+			Origin:     config.OriginMap,
+		})
+	}
+	return rules
+}
+
+// deepCopyRules creates a rule from each type in deepCopyTypes to itself. A conversion
+// between identical types is generated as a deep copy.
+func (p *Planner) deepCopyRules(deepCopyTypes []string, typeInfos map[string]*model.TypeInfo) []*config.ConversionRule {
+	var rules []*config.ConversionRule
+	for _, fqn := range deepCopyTypes {
+		if typeInfos[fqn] == nil {
+			slog.Warn("Planner: deepcopy type not found, skipping", "type", fqn)
+			continue
+		}
+		rules = append(rules, &config.ConversionRule{
+			SourceType: fqn,
+			TargetType: fqn,
+			Direction:  config.DirectionOneway,
+			Origin:     config.OriginDeepCopy,
+		})
+	}
+	return rules
+}
+
+// conversionMethods decides which struct conversions are generated as methods when
+// convert:methods is enabled. A conversion from a struct type of the directive package
+// becomes a To method of that type, and a conversion to one a From method; conversions
+// between foreign types stay free functions. A conversion whose method name is already
+// taken on its receiver, by a field, a declared method or another conversion, is reported
+// and also stays a free function.
+func (p *Planner) conversionMethods(cfg *config.Config, rules []*config.ConversionRule, typeInfos map[string]*model.TypeInfo) map[string]*model.ConversionMethod {
+	if !cfg.GlobalBehaviorRules.GenerateMethods {
+		return nil
+	}
+
+	pairs := make(map[string][2]*model.TypeInfo)
+	for _, rule := range rules {
+		source, target := typeInfos[rule.SourceType], typeInfos[rule.TargetType]
+		if source == nil || target == nil || !source.IsUltimatelyStruct() || !target.IsUltimatelyStruct() ||
+			source.FQN() == "" || target.FQN() == "" || source.FQN() == target.FQN() {
+			continue
+		}
+		pairs[source.FQN()+"->"+target.FQN()] = [2]*model.TypeInfo{source, target}
+		if rule.Direction == config.DirectionBoth {
+			pairs[target.FQN()+"->"+source.FQN()] = [2]*model.TypeInfo{target, source}
+		}
+	}
+	keys := make([]string, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	localPath := cfg.GenerationContext.PackagePath
+	methods := make(map[string]*model.ConversionMethod)
+	taken := make(map[string]bool)
+	for _, key := range keys {
+		source, target := pairs[key][0], pairs[key][1]
+		var receiver *model.TypeInfo
+		var method *model.ConversionMethod
+		switch {
+		case isLocalType(source, localPath):
+			receiver = source
+			method = &model.ConversionMethod{Name: methodName(cfg.NamingRules.ToMethod, "To", target)}
+		case isLocalType(target, localPath):
+			receiver = target
+			method = &model.ConversionMethod{Name: methodName(cfg.NamingRules.FromMethod, "From", source), From: true}
+		default:
+			continue
+		}
+
+		receiverMethod := receiver.FQN() + "." + method.Name
+		if taken[receiverMethod] || declaresFieldOrMethod(receiver, method.Name) {
+			slog.Warn("Planner: conversion method name already taken, generating a function instead",
+				"conversion", key, "receiver", receiver.FQN(), "method", method.Name)
+			continue
+		}
+		taken[receiverMethod] = true
+		methods[key] = method
+	}
+	return methods
+}
+
+// isLocalType reports whether info is a type defined, not aliased, in the directive package.
+func isLocalType(info *model.TypeInfo, localPath string) bool {
+	return localPath != "" && info.ImportPath == localPath && !info.IsAlias
+}
+
+// methodName returns the configured method name, or prefix followed by the name of the
+// other type of the conversion.
+func methodName(configured, prefix string, other *model.TypeInfo) string {
+	if configured != "" {
+		return configured
+	}
+	return prefix + other.Name
+}
+
+// declaresFieldOrMethod reports whether the type info already has a field or a method,
+// declared by the user, named name.
+func declaresFieldOrMethod(info *model.TypeInfo, name string) bool {
+	obj, ok := info.Original.(*types.TypeName)
+	if !ok {
+		return false
+	}
+	found, _, _ := types.LookupFieldOrMethod(types.NewPointer(obj.Type()), false, obj.Pkg(), name)
+	return found != nil
+}
+
 // needsDisambiguation checks if any rules have source and target types with the same base name.
 func (p *Planner) needsDisambiguation(rules []*config.ConversionRule) bool {
 	for _, rule := range rules {
@@ -176,7 +293,7 @@ func (p *Planner) needsDisambiguation(rules []*config.ConversionRule) bool {
 			targetBaseName = rule.TargetType[lastDot+1:]
 		}
 
-		if sourceBaseName != "" && sourceBaseName == targetBaseName {
+		if sourceBaseName != "" && sourceBaseName == targetBaseName && rule.SourceType != rule.TargetType {
 			return true
 		}
 	}
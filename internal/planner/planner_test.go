@@ -98,3 +98,107 @@ func TestPlanner_Plan(t *testing.T) {
 		t.Errorf("Did not find all expected rules. Missing: %v", reflect.ValueOf(expectedRules).MapKeys())
 	}
 }
+
+func TestPlanner_Plan_ExplicitRules(t *testing.T) {
+	user := newStruct("User", "source/ent", []*model.FieldInfo{
+		{Name: "ID", Type: &model.TypeInfo{Name: "int", Kind: model.Primitive}},
+		{Name: "Name", Type: &model.TypeInfo{Name: "string", Kind: model.Primitive}},
+		{Name: "Password", Type: &model.TypeInfo{Name: "string", Kind: model.Primitive}},
+	})
+	userDTO := newStruct("User", "target/dto", []*model.FieldInfo{
+		{Name: "ID", Type: &model.TypeInfo{Name: "int", Kind: model.Primitive}},
+		{Name: "FullName", Type: &model.TypeInfo{Name: "string", Kind: model.Primitive}},
+	})
+	typeInfos := map[string]*model.TypeInfo{
+		"source/ent.User": user,
+		"target/dto.User": userDTO,
+	}
+
+	initialConfig := config.NewConfig()
+	initialConfig.PackagePairs = append(initialConfig.PackagePairs, &config.PackagePair{
+		SourcePath: "source/ent",
+		TargetPath: "target/dto",
+	})
+	fieldRules := config.FieldRuleSet{
+		Ignore: map[string]struct{}{"Password": {}},
+		Remap:  map[string]string{"Name": "FullName"},
+	}
+	initialConfig.ConversionRules = append(initialConfig.ConversionRules, &config.ConversionRule{
+		SourceType: "source/ent.User",
+		TargetType: "target/dto.User",
+		Direction:  config.DirectionBoth,
+		FieldRules: fieldRules,
+		Origin:     config.OriginDirective,
+	})
+
+	plan := NewPlanner(components.NewTypeConverter()).Plan(initialConfig, typeInfos)
+
+	// The explicit rule takes precedence over the package pair rule for the same types.
+	if len(plan.ActiveRules) != 1 {
+		t.Fatalf("Expected 1 active rule, got %d", len(plan.ActiveRules))
+	}
+	rule := plan.ActiveRules[0]
+	if rule.Origin != config.OriginDirective || !reflect.DeepEqual(rule.FieldRules, fieldRules) {
+		t.Errorf("Active rule = %+v, want the explicit rule with its field rules", rule)
+	}
+}
+
+func TestPlanner_Plan_MapTypes(t *testing.T) {
+	user := newStruct("User", "source/ent", []*model.FieldInfo{
+		{Name: "ID", Type: &model.TypeInfo{Name: "int", Kind: model.Primitive}},
+	})
+	mapType := model.AnyMapType()
+	typeInfos := map[string]*model.TypeInfo{
+		"source/ent.User":   user,
+		mapType.UniqueKey(): mapType,
+	}
+
+	initialConfig := config.NewConfig()
+	initialConfig.MapTypes = []string{"source/ent.User", "source/ent.Missing"}
+
+	plan := NewPlanner(components.NewTypeConverter()).Plan(initialConfig, typeInfos)
+
+	if len(plan.ActiveRules) != 1 {
+		t.Fatalf("Expected 1 active rule, got %d", len(plan.ActiveRules))
+	}
+	rule := plan.ActiveRules[0]
+	if rule.SourceType != "source/ent.User" || rule.TargetType != mapType.UniqueKey() || rule.Direction != config.DirectionBoth {
+		t.Errorf("Unexpected map rule: %+v", rule)
+	}
+}
+
+func TestPlanner_Plan_ConversionMethods(t *testing.T) {
+	localUser := newStruct("UserDTO", "app/api", nil)
+	pbUser := newStruct("User", "app/pb", nil)
+	pbSummary := newStruct("UserSummary", "app/pb", nil)
+	entUser := newStruct("User", "app/ent", nil)
+	typeInfos := map[string]*model.TypeInfo{
+		"app/api.UserDTO":    localUser,
+		"app/pb.User":        pbUser,
+		"app/pb.UserSummary": pbSummary,
+		"app/ent.User":       entUser,
+	}
+
+	initialConfig := config.NewConfig()
+	initialConfig.GenerationContext.PackagePath = "app/api"
+	initialConfig.GlobalBehaviorRules.GenerateMethods = true
+	initialConfig.NamingRules.ToMethod = "ToPB"
+	initialConfig.ConversionRules = []*config.ConversionRule{
+		{SourceType: "app/api.UserDTO", TargetType: "app/pb.User", Direction: config.DirectionBoth},
+		{SourceType: "app/api.UserDTO", TargetType: "app/pb.UserSummary", Direction: config.DirectionOneway},
+		{SourceType: "app/ent.User", TargetType: "app/pb.User", Direction: config.DirectionBoth},
+	}
+
+	plan := NewPlanner(components.NewTypeConverter()).Plan(initialConfig, typeInfos)
+
+	expected := map[string]*model.ConversionMethod{
+		"app/api.UserDTO->app/pb.User": {Name: "ToPB"},
+		"app/pb.User->app/api.UserDTO": {Name: "FromUser", From: true},
+	}
+	if !reflect.DeepEqual(plan.Methods, expected) {
+		t.Errorf("Unexpected conversion methods:\ngot:  %v\nwant: %v", plan.Methods, expected)
+	}
+	if method := plan.ConversionMethod(&model.TypeInfo{Kind: model.Pointer, Underlying: localUser}, pbUser); method == nil || method.Name != "ToPB" {
+		t.Errorf("Expected pointers to share the ToPB method, got %v", method)
+	}
+}
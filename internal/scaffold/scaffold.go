@@ -0,0 +1,380 @@
+// Package scaffold writes a first directives file for converting the types of one package to
+// those of another, as abgen init does.
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// typeAffixes are the words commonly added to type names to tell the layers of an application
+// apart, ignored when pairing types by name: UserEntity and UserDTO both pair with User.
+var typeAffixes = []string{"dto", "entity", "ent", "model", "object", "proto", "pb", "vo", "po", "do", "info", "data", "message", "msg"}
+
+// fieldSuffixes are the words commonly ending the names of time and identifier fields,
+// ignored when pairing fields by name: CreatedAt pairs with CreatedTime.
+var fieldSuffixes = []string{"at", "time", "date", "on"}
+
+// Options configures the directives file to write.
+type Options struct {
+	// SourcePath and TargetPath are the import paths of the packages converted.
+	SourcePath string
+	TargetPath string
+	// OutDir is the directory of the directives file, where the packages are loaded from. It
+	// may not exist yet, in which case they are loaded from its nearest existing parent.
+	OutDir string
+}
+
+// typePair is a source type paired with a target type, with the suggested field rules.
+type typePair struct {
+	source, target *types.TypeName
+	// exact tells whether both types have the same name, so the package pair converts them.
+	exact  bool
+	ignore []string
+	remap  [][2]string
+}
+
+// Directives loads the source and target packages and returns the content of a directives
+// file for the package in OutDir. The file names both packages with package:path directives
+// and pairs them with pair:packages, which converts the types of the same name. Types whose
+// names differ by a common affix, such as User and UserDTO, get a convert directive. For the
+// paired types whose fields do not all match, a commented-out convert directive suggests the
+// fields to ignore and remap.
+func Directives(opts Options) ([]byte, error) {
+	sourcePkg, targetPkg, err := loadPackages(opts)
+	if err != nil {
+		return nil, err
+	}
+	sourceAlias, targetAlias := sourcePkg.Name(), targetPkg.Name()
+	if sourceAlias == targetAlias {
+		sourceAlias, targetAlias = "source", "target"
+	}
+	pairs, unpaired := pairTypes(structTypes(sourcePkg), structTypes(targetPkg))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "package %s\n\n", packageName(opts.OutDir))
+	fmt.Fprintf(&buf, "import (\n\t_ %q\n\t_ %q\n)\n\n", opts.SourcePath, opts.TargetPath)
+	fmt.Fprintf(&buf, "//go:abgen:package:path=%s,alias=%s\n", opts.SourcePath, sourceAlias)
+	fmt.Fprintf(&buf, "//go:abgen:package:path=%s,alias=%s\n\n", opts.TargetPath, targetAlias)
+	buf.WriteString("// Types of the same name in both packages are converted by the package pair.\n")
+	fmt.Fprintf(&buf, "//go:abgen:pair:packages=\"%s,%s\"\n", sourceAlias, targetAlias)
+
+	convertRule := func(pair *typePair, withFields bool) string {
+		rule := fmt.Sprintf("source=%s.%s,target=%s.%s", sourceAlias, pair.source.Name(), targetAlias, pair.target.Name())
+		if withFields && len(pair.ignore) > 0 {
+			rule += ",ignore=" + strings.Join(pair.ignore, ";")
+		}
+		if withFields && len(pair.remap) > 0 {
+			remaps := make([]string, len(pair.remap))
+			for i, remap := range pair.remap {
+				remaps[i] = remap[0] + ":" + remap[1]
+			}
+			rule += ",remap=" + strings.Join(remaps, ";")
+		}
+		return fmt.Sprintf("//go:abgen:convert=%q", rule)
+	}
+
+	var nearMatches, suggestions []*typePair
+	for _, pair := range pairs {
+		if !pair.exact {
+			nearMatches = append(nearMatches, pair)
+		}
+		if len(pair.ignore) > 0 || len(pair.remap) > 0 {
+			suggestions = append(suggestions, pair)
+		}
+	}
+	if len(nearMatches) > 0 {
+		buf.WriteString("\n// Types paired by name, ignoring common affixes such as DTO or Entity.\n")
+		for _, pair := range nearMatches {
+			buf.WriteString(convertRule(pair, false) + "\n")
+		}
+	}
+	if len(suggestions) > 0 {
+		buf.WriteString("\n// Fields that do not match by name. To ignore or remap them, uncomment and adjust these\n")
+		buf.WriteString("// directives, removing the convert directives of the same types above.\n")
+		for _, pair := range suggestions {
+			buf.WriteString("// " + convertRule(pair, true) + "\n")
+		}
+	}
+	if len(unpaired) > 0 {
+		names := make([]string, len(unpaired))
+		for i, typeName := range unpaired {
+			alias := sourceAlias
+			if typeName.Pkg() == targetPkg {
+				alias = targetAlias
+			}
+			names[i] = alias + "." + typeName.Name()
+		}
+		fmt.Fprintf(&buf, "\n// Types without a pair: %s.\n", strings.Join(names, ", "))
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// loadPackages loads the source and target packages from the output directory, so that
+// import paths are resolved by its module.
+func loadPackages(opts Options) (*types.Package, *types.Package, error) {
+	loadCfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes |
+			packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps,
+		Dir: existingDir(opts.OutDir),
+	}
+	pkgs, err := packages.Load(loadCfg, opts.SourcePath, opts.TargetPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+	byPath := make(map[string]*types.Package, len(pkgs))
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 {
+			return nil, nil, fmt.Errorf("failed to load package %s: %v", pkg.PkgPath, pkg.Errors[0])
+		}
+		byPath[pkg.PkgPath] = pkg.Types
+	}
+	sourcePkg, targetPkg := byPath[opts.SourcePath], byPath[opts.TargetPath]
+	if sourcePkg == nil || targetPkg == nil {
+		return nil, nil, fmt.Errorf("failed to load packages %s and %s", opts.SourcePath, opts.TargetPath)
+	}
+	return sourcePkg, targetPkg, nil
+}
+
+// structTypes returns the exported struct types of pkg, sorted by name.
+func structTypes(pkg *types.Package) []*types.TypeName {
+	var typeNames []*types.TypeName
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		typeName, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || !typeName.Exported() || typeName.IsAlias() {
+			continue
+		}
+		if _, ok := typeName.Type().Underlying().(*types.Struct); ok {
+			typeNames = append(typeNames, typeName)
+		}
+	}
+	return typeNames
+}
+
+// pairTypes pairs the source types with the target types of the same name, then with those
+// of the same name once common affixes are removed. It returns the pairs in source type
+// order, and the types left without a pair.
+func pairTypes(sourceTypes, targetTypes []*types.TypeName) ([]*typePair, []*types.TypeName) {
+	targetsByName := make(map[string]*types.TypeName, len(targetTypes))
+	paired := make(map[*types.TypeName]bool)
+	for _, target := range targetTypes {
+		targetsByName[target.Name()] = target
+	}
+
+	var pairs []*typePair
+	for _, source := range sourceTypes {
+		if target, ok := targetsByName[source.Name()]; ok {
+			pairs = append(pairs, &typePair{source: source, target: target, exact: true})
+			paired[source], paired[target] = true, true
+		}
+	}
+	for _, source := range sourceTypes {
+		if paired[source] {
+			continue
+		}
+		stem := typeStem(source.Name())
+		for _, target := range targetTypes {
+			if !paired[target] && typeStem(target.Name()) == stem {
+				pairs = append(pairs, &typePair{source: source, target: target})
+				paired[source], paired[target] = true, true
+				break
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].source.Name() < pairs[j].source.Name() })
+
+	for _, pair := range pairs {
+		pair.ignore, pair.remap = suggestFieldRules(pair.source, pair.target)
+	}
+
+	var unpaired []*types.TypeName
+	for _, typeName := range append(append([]*types.TypeName(nil), sourceTypes...), targetTypes...) {
+		if !paired[typeName] {
+			unpaired = append(unpaired, typeName)
+		}
+	}
+	return pairs, unpaired
+}
+
+// typeStem returns the lower case name of a type without the common affixes starting or
+// ending it as words of their own: UserDTO and PbUser become user, but Domain stays domain.
+func typeStem(name string) string {
+	for _, affix := range typeAffixes {
+		n := len(affix)
+		if len(name) > n && strings.EqualFold(name[len(name)-n:], affix) && unicode.IsUpper(rune(name[len(name)-n])) {
+			name = name[:len(name)-n]
+		}
+		if len(name) > n && strings.EqualFold(name[:n], affix) && unicode.IsUpper(rune(name[n])) {
+			name = name[n:]
+		}
+	}
+	return strings.ToLower(name)
+}
+
+// suggestFieldRules compares the exported fields of two struct types as abgen pairs them, by
+// case-insensitive name, looking into the Edges struct of the source for ent entities. It
+// suggests remapping each unmatched source field to an unmatched target field of a similar
+// name, and ignoring the fields left.
+func suggestFieldRules(source, target *types.TypeName) (ignore []string, remap [][2]string) {
+	sourceFields := exportedFields(source.Type())
+	targetFields := exportedFields(target.Type())
+	if edges := lookupField(sourceFields, "Edges"); edges != nil {
+		sourceFields = append(sourceFields, exportedFields(edges.Type())...)
+	}
+
+	var unmatchedSources, unmatchedTargets []string
+	for _, field := range sourceFields {
+		if field.Name() != "Edges" && lookupField(targetFields, field.Name()) == nil {
+			unmatchedSources = append(unmatchedSources, field.Name())
+		}
+	}
+	for _, field := range targetFields {
+		if lookupField(sourceFields, field.Name()) == nil {
+			unmatchedTargets = append(unmatchedTargets, field.Name())
+		}
+	}
+
+	remapped := make(map[string]bool)
+	for _, sourceName := range unmatchedSources {
+		var match string
+		for _, similar := range []func(a, b string) bool{sameFieldStem, containsFieldName} {
+			for _, targetName := range unmatchedTargets {
+				if !remapped[targetName] && similar(sourceName, targetName) {
+					match = targetName
+					break
+				}
+			}
+			if match != "" {
+				break
+			}
+		}
+		if match == "" {
+			ignore = append(ignore, sourceName)
+			continue
+		}
+		remapped[sourceName], remapped[match] = true, true
+		remap = append(remap, [2]string{sourceName, match})
+	}
+	for _, targetName := range unmatchedTargets {
+		if !remapped[targetName] {
+			ignore = append(ignore, targetName)
+		}
+	}
+	return ignore, remap
+}
+
+// exportedFields returns the exported fields of a struct type, or nil for other types.
+func exportedFields(t types.Type) []*types.Var {
+	if pointer, ok := t.Underlying().(*types.Pointer); ok {
+		t = pointer.Elem()
+	}
+	structType, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+	var fields []*types.Var
+	for i := 0; i < structType.NumFields(); i++ {
+		if field := structType.Field(i); field.Exported() {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// lookupField finds a field by case-insensitive name, as abgen matches fields.
+func lookupField(fields []*types.Var, name string) *types.Var {
+	for _, field := range fields {
+		if strings.EqualFold(field.Name(), name) {
+			return field
+		}
+	}
+	return nil
+}
+
+// sameFieldStem reports whether two field names are the same once underscores, case and
+// common time suffixes are ignored: CreatedAt and Created_Time.
+func sameFieldStem(a, b string) bool {
+	return fieldStem(a) == fieldStem(b)
+}
+
+// fieldStem returns the lower case name of a field without underscores and common suffixes.
+func fieldStem(name string) string {
+	stem := strings.ToLower(strings.ReplaceAll(name, "_", ""))
+	for _, suffix := range fieldSuffixes {
+		if len(stem) > len(suffix) && strings.HasSuffix(stem, suffix) {
+			return strings.TrimSuffix(stem, suffix)
+		}
+	}
+	return stem
+}
+
+// containsFieldName reports whether one field name contains the other, of at least three
+// letters, ignoring case: Name and FullName, Email and UserEmail.
+func containsFieldName(a, b string) bool {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	return len(a) >= 3 && strings.Contains(b, a)
+}
+
+// existingDir returns dir if it exists, otherwise its nearest existing parent.
+func existingDir(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	for {
+		if info, err := os.Stat(abs); err == nil && info.IsDir() {
+			return abs
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return abs
+		}
+		abs = parent
+	}
+}
+
+// packageName returns the name of the package of a directory: the package of its Go files
+// if it has any, otherwise its base name, lower case, with the characters not allowed in
+// identifiers removed.
+func packageName(dir string) string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		if f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.PackageClauseOnly); err == nil {
+			return f.Name.Name
+		}
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, filepath.Base(abs))
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "convert" + name
+	}
+	return name
+}
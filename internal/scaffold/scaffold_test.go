@@ -0,0 +1,126 @@
+package scaffold
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDirectives(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		contains []string
+		excludes []string
+	}{
+		{
+			name: "near match with remaps",
+			opts: Options{
+				SourcePath: "github.com/origadmin/abgen/testdata/02_basic_conversions/field_ignore_remap/source",
+				TargetPath: "github.com/origadmin/abgen/testdata/02_basic_conversions/field_ignore_remap/target",
+				OutDir:     ".",
+			},
+			contains: []string{
+				"package scaffold\n",
+				`_ "github.com/origadmin/abgen/testdata/02_basic_conversions/field_ignore_remap/source"`,
+				"//go:abgen:package:path=github.com/origadmin/abgen/testdata/02_basic_conversions/field_ignore_remap/source,alias=source\n",
+				"//go:abgen:package:path=github.com/origadmin/abgen/testdata/02_basic_conversions/field_ignore_remap/target,alias=target\n",
+				`//go:abgen:pair:packages="source,target"`,
+				"\n//go:abgen:convert=\"source=source.User,target=target.UserDTO\"\n",
+				`// //go:abgen:convert="source=source.User,target=target.UserDTO,ignore=Password;UpdatedAt;LastUpdate,remap=Name:FullName;Email:UserEmail;CreatedAt:CreatedDate"`,
+			},
+			excludes: []string{"Types without a pair"},
+		},
+		{
+			name: "directory to create",
+			opts: Options{
+				SourcePath: "github.com/origadmin/abgen/testdata/02_basic_conversions/field_ignore_remap/source",
+				TargetPath: "github.com/origadmin/abgen/testdata/02_basic_conversions/field_ignore_remap/target",
+				OutDir:     "convert/user-api",
+			},
+			contains: []string{"package userapi\n", `//go:abgen:pair:packages="source,target"`},
+		},
+		{
+			name: "same names with ent edges",
+			opts: Options{
+				SourcePath: "github.com/origadmin/abgen/testdata/fixtures/ent",
+				TargetPath: "github.com/origadmin/abgen/testdata/fixtures/types",
+				OutDir:     ".",
+			},
+			contains: []string{
+				`//go:abgen:pair:packages="ent,types"`,
+				"// Types without a pair: ent.Permission, ent.PermissionResource, ent.ResourceEdges, types.Edges.",
+				`// //go:abgen:convert="source=ent.User,target=types.User,ignore=Password;Salt;UpdatedAt;RoleIDs;Roles;Children;Parent;PermissionResources;Permissions"`,
+			},
+			excludes: []string{
+				"\n//go:abgen:convert=",
+				"target=types.Role",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := Directives(tt.opts)
+			if err != nil {
+				t.Fatalf("Directives() error = %v", err)
+			}
+			if _, err := parser.ParseFile(token.NewFileSet(), "directives.go", content, parser.ParseComments); err != nil {
+				t.Fatalf("Directives() is not valid Go: %v\n%s", err, content)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(string(content), want) {
+					t.Errorf("Directives() does not contain %q:\n%s", want, content)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(string(content), unwanted) {
+					t.Errorf("Directives() contains %q:\n%s", unwanted, content)
+				}
+			}
+		})
+	}
+}
+
+func TestTypeStem(t *testing.T) {
+	tests := map[string]string{
+		"User":       "user",
+		"UserDTO":    "user",
+		"UserEntity": "user",
+		"PbUser":     "user",
+		"UserInfoPB": "user",
+		"Domain":     "domain",
+		"Document":   "document",
+	}
+	for name, want := range tests {
+		if got := typeStem(name); got != want {
+			t.Errorf("typeStem(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestPackageName(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "user-api")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := packageName(dir); got != "userapi" {
+		t.Errorf("packageName() of an empty directory = %q, want userapi", got)
+	}
+
+	// The package of the Go files wins over the name of the directory, but not their tests.
+	for name, content := range map[string]string{
+		"api_test.go": "package api_test\n",
+		"api.go":      "// Package api serves users.\npackage api\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := packageName(dir); got != "api" {
+		t.Errorf("packageName() = %q, want the package api of the directory", got)
+	}
+}
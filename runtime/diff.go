@@ -0,0 +1,30 @@
+package runtime
+
+// FieldDiff describes a field whose values differ between two values of paired types, as
+// reported by the generated Diff functions. Path is the dotted path of the field in the
+// first value and TargetPath the path of the field it is paired with in the second; they
+// differ for remapped fields. Both paths are empty when only one of the values is nil.
+type FieldDiff struct {
+	Path       string
+	TargetPath string
+	A          any
+	B          any
+}
+
+// NestDiffs prefixes the paths of diffs found in nested values with the paths of the
+// fields holding them.
+func NestDiffs(path, targetPath string, diffs []FieldDiff) []FieldDiff {
+	for i := range diffs {
+		if diffs[i].Path == "" {
+			diffs[i].Path = path
+		} else {
+			diffs[i].Path = path + "." + diffs[i].Path
+		}
+		if diffs[i].TargetPath == "" {
+			diffs[i].TargetPath = targetPath
+		} else {
+			diffs[i].TargetPath = targetPath + "." + diffs[i].TargetPath
+		}
+	}
+	return diffs
+}
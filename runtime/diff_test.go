@@ -0,0 +1,23 @@
+package runtime
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNestDiffs(t *testing.T) {
+	diffs := []FieldDiff{
+		{A: nil, B: "x"},
+		{Path: "Name", TargetPath: "FullName", A: "a", B: "b"},
+	}
+	want := []FieldDiff{
+		{Path: "Profile", TargetPath: "Info", A: nil, B: "x"},
+		{Path: "Profile.Name", TargetPath: "Info.FullName", A: "a", B: "b"},
+	}
+	if got := NestDiffs("Profile", "Info", diffs); !reflect.DeepEqual(got, want) {
+		t.Errorf("NestDiffs() = %+v, want %+v", got, want)
+	}
+	if got := NestDiffs("Profile", "Info", nil); got != nil {
+		t.Errorf("NestDiffs(nil) = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,13 @@
+// Package runtime provides the helper functions called by code generated by abgen.
+//
+// Helpers are split by dependency so that generated code only pulls in what it uses:
+//
+//   - runtime: helpers that depend on the standard library only
+//   - runtime/uuidconv: helpers for github.com/google/uuid
+//   - runtime/protoconv: helpers for the protobuf well-known types
+//   - runtime/testfill: fills values from fuzz input in generated round-trip tests
+//
+// Generated code imports this package as abgenrt. Set the
+// //go:abgen:helpers:mode="inline" directive to copy helper bodies into the
+// generated file instead.
+package runtime
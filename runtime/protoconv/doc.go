@@ -0,0 +1,2 @@
+// Package protoconv provides abgen runtime helpers for the protobuf well-known types.
+package protoconv
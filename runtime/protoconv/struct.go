@@ -0,0 +1,51 @@
+package protoconv
+
+import "google.golang.org/protobuf/types/known/structpb"
+
+// ConvertMapToStruct converts a map to a Struct, mapping nil to nil.
+// It fails if a value cannot be represented as a structpb.Value.
+func ConvertMapToStruct(m map[string]any) (*structpb.Struct, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return structpb.NewStruct(m)
+}
+
+// ConvertStructToMap converts a Struct to a map, mapping nil to nil.
+func ConvertStructToMap(s *structpb.Struct) map[string]any {
+	if s == nil {
+		return nil
+	}
+	return s.AsMap()
+}
+
+// ConvertAnyToValue converts a Go value to a Value; nil becomes a null Value.
+// It fails if the value cannot be represented as a structpb.Value.
+func ConvertAnyToValue(v any) (*structpb.Value, error) {
+	return structpb.NewValue(v)
+}
+
+// ConvertValueToAny converts a Value to a Go value, mapping nil to nil.
+func ConvertValueToAny(v *structpb.Value) any {
+	if v == nil {
+		return nil
+	}
+	return v.AsInterface()
+}
+
+// ConvertSliceToListValue converts a slice to a ListValue, mapping nil to nil.
+// It fails if an element cannot be represented as a structpb.Value.
+func ConvertSliceToListValue(l []any) (*structpb.ListValue, error) {
+	if l == nil {
+		return nil, nil
+	}
+	return structpb.NewList(l)
+}
+
+// ConvertListValueToSlice converts a ListValue to a slice, mapping nil to nil.
+func ConvertListValueToSlice(l *structpb.ListValue) []any {
+	if l == nil {
+		return nil
+	}
+	return l.AsSlice()
+}
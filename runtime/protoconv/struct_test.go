@@ -0,0 +1,46 @@
+package protoconv
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStructConversions(t *testing.T) {
+	m := map[string]any{"name": "abgen", "tags": []any{"a", "b"}, "count": float64(2)}
+	s, err := ConvertMapToStruct(m)
+	if err != nil {
+		t.Fatalf("ConvertMapToStruct() error = %v", err)
+	}
+	if got := ConvertStructToMap(s); !reflect.DeepEqual(got, m) {
+		t.Errorf("round trip = %v, want %v", got, m)
+	}
+
+	if _, err := ConvertMapToStruct(map[string]any{"ch": make(chan int)}); err == nil {
+		t.Error("ConvertMapToStruct() expected an error for an unsupported value")
+	}
+	if _, err := ConvertSliceToListValue([]any{struct{}{}}); err == nil {
+		t.Error("ConvertSliceToListValue() expected an error for an unsupported value")
+	}
+}
+
+func TestStructConversions_Nil(t *testing.T) {
+	if s, err := ConvertMapToStruct(nil); s != nil || err != nil {
+		t.Errorf("ConvertMapToStruct(nil) = %v, %v, want nil, nil", s, err)
+	}
+	if m := ConvertStructToMap(nil); m != nil {
+		t.Errorf("ConvertStructToMap(nil) = %v, want nil", m)
+	}
+	if l, err := ConvertSliceToListValue(nil); l != nil || err != nil {
+		t.Errorf("ConvertSliceToListValue(nil) = %v, %v, want nil, nil", l, err)
+	}
+	if v := ConvertListValueToSlice(nil); v != nil {
+		t.Errorf("ConvertListValueToSlice(nil) = %v, want nil", v)
+	}
+	if v := ConvertValueToAny(nil); v != nil {
+		t.Errorf("ConvertValueToAny(nil) = %v, want nil", v)
+	}
+	v, err := ConvertAnyToValue(nil)
+	if err != nil || v.GetNullValue() != 0 || v.GetKind() == nil {
+		t.Errorf("ConvertAnyToValue(nil) = %v, %v, want a null value", v, err)
+	}
+}
@@ -0,0 +1,37 @@
+package protoconv
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ConvertTimeToTimestamp converts a time.Time to a timestamp, mapping the zero time to nil.
+func ConvertTimeToTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// ConvertTimestampToTime converts a timestamp to a time.Time, mapping nil to the zero time.
+func ConvertTimestampToTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+// ConvertDurationToDurationpb converts a time.Duration to a protobuf duration.
+func ConvertDurationToDurationpb(d time.Duration) *durationpb.Duration {
+	return durationpb.New(d)
+}
+
+// ConvertDurationpbToDuration converts a protobuf duration to a time.Duration, mapping nil to zero.
+func ConvertDurationpbToDuration(d *durationpb.Duration) time.Duration {
+	if d == nil {
+		return 0
+	}
+	return d.AsDuration()
+}
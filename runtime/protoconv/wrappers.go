@@ -0,0 +1,120 @@
+package protoconv
+
+import "google.golang.org/protobuf/types/known/wrapperspb"
+
+// ConvertStringToStringValue wraps a string in a StringValue.
+func ConvertStringToStringValue(s string) *wrapperspb.StringValue {
+	return wrapperspb.String(s)
+}
+
+// ConvertStringValueToString unwraps a StringValue, mapping nil to the zero string.
+func ConvertStringValueToString(v *wrapperspb.StringValue) string {
+	if v == nil {
+		return ""
+	}
+	return v.GetValue()
+}
+
+// ConvertInt32ToInt32Value wraps an int32 in an Int32Value.
+func ConvertInt32ToInt32Value(i int32) *wrapperspb.Int32Value {
+	return wrapperspb.Int32(i)
+}
+
+// ConvertInt32ValueToInt32 unwraps an Int32Value, mapping nil to the zero int32.
+func ConvertInt32ValueToInt32(v *wrapperspb.Int32Value) int32 {
+	if v == nil {
+		return 0
+	}
+	return v.GetValue()
+}
+
+// ConvertInt64ToInt64Value wraps an int64 in an Int64Value.
+func ConvertInt64ToInt64Value(i int64) *wrapperspb.Int64Value {
+	return wrapperspb.Int64(i)
+}
+
+// ConvertInt64ValueToInt64 unwraps an Int64Value, mapping nil to the zero int64.
+func ConvertInt64ValueToInt64(v *wrapperspb.Int64Value) int64 {
+	if v == nil {
+		return 0
+	}
+	return v.GetValue()
+}
+
+// ConvertUInt32ToUInt32Value wraps a uint32 in a UInt32Value.
+func ConvertUInt32ToUInt32Value(i uint32) *wrapperspb.UInt32Value {
+	return wrapperspb.UInt32(i)
+}
+
+// ConvertUInt32ValueToUInt32 unwraps a UInt32Value, mapping nil to the zero uint32.
+func ConvertUInt32ValueToUInt32(v *wrapperspb.UInt32Value) uint32 {
+	if v == nil {
+		return 0
+	}
+	return v.GetValue()
+}
+
+// ConvertUInt64ToUInt64Value wraps a uint64 in a UInt64Value.
+func ConvertUInt64ToUInt64Value(i uint64) *wrapperspb.UInt64Value {
+	return wrapperspb.UInt64(i)
+}
+
+// ConvertUInt64ValueToUInt64 unwraps a UInt64Value, mapping nil to the zero uint64.
+func ConvertUInt64ValueToUInt64(v *wrapperspb.UInt64Value) uint64 {
+	if v == nil {
+		return 0
+	}
+	return v.GetValue()
+}
+
+// ConvertFloatToFloatValue wraps a float32 in a FloatValue.
+func ConvertFloatToFloatValue(f float32) *wrapperspb.FloatValue {
+	return wrapperspb.Float(f)
+}
+
+// ConvertFloatValueToFloat unwraps a FloatValue, mapping nil to the zero float32.
+func ConvertFloatValueToFloat(v *wrapperspb.FloatValue) float32 {
+	if v == nil {
+		return 0.0
+	}
+	return v.GetValue()
+}
+
+// ConvertDoubleToDoubleValue wraps a float64 in a DoubleValue.
+func ConvertDoubleToDoubleValue(d float64) *wrapperspb.DoubleValue {
+	return wrapperspb.Double(d)
+}
+
+// ConvertDoubleValueToDouble unwraps a DoubleValue, mapping nil to the zero float64.
+func ConvertDoubleValueToDouble(v *wrapperspb.DoubleValue) float64 {
+	if v == nil {
+		return 0.0
+	}
+	return v.GetValue()
+}
+
+// ConvertBoolToBoolValue wraps a bool in a BoolValue.
+func ConvertBoolToBoolValue(b bool) *wrapperspb.BoolValue {
+	return wrapperspb.Bool(b)
+}
+
+// ConvertBoolValueToBool unwraps a BoolValue, mapping nil to the zero bool.
+func ConvertBoolValueToBool(v *wrapperspb.BoolValue) bool {
+	if v == nil {
+		return false
+	}
+	return v.GetValue()
+}
+
+// ConvertBytesToBytesValue wraps a []byte in a BytesValue.
+func ConvertBytesToBytesValue(b []byte) *wrapperspb.BytesValue {
+	return wrapperspb.Bytes(b)
+}
+
+// ConvertBytesValueToBytes unwraps a BytesValue, mapping nil to a nil slice.
+func ConvertBytesValueToBytes(v *wrapperspb.BytesValue) []byte {
+	if v == nil {
+		return nil
+	}
+	return v.GetValue()
+}
@@ -0,0 +1,118 @@
+// Package testfill fills values with data derived from fuzz input. It is used by the
+// round-trip tests generated by abgen with the //go:abgen:test:generate directive.
+package testfill
+
+import (
+	"reflect"
+	"time"
+)
+
+// maxDepth bounds the nesting of filled values, so that recursive types terminate.
+const maxDepth = 4
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Fill sets the exported fields of the value v points to from data, which is consumed
+// byte by byte; once it is exhausted, the remaining values are left at their zero value.
+// The same data always produces the same value. Floats hold small multiples of 1/4 and
+// times whole seconds in UTC, so that they survive text and numeric encodings; strings
+// hold ASCII letters. Channels, functions and interfaces are not filled.
+func Fill(v any, data []byte) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return
+	}
+	f := &filler{data: data}
+	f.fill(rv.Elem(), 0)
+}
+
+type filler struct {
+	data []byte
+}
+
+// next returns the next byte of data, or 0 once it is exhausted.
+func (f *filler) next() byte {
+	if len(f.data) == 0 {
+		return 0
+	}
+	b := f.data[0]
+	f.data = f.data[1:]
+	return b
+}
+
+// bits returns the next n bits of data, read little-endian one byte at a time.
+func (f *filler) bits(n int) uint64 {
+	var u uint64
+	for i := 0; i < n; i += 8 {
+		u |= uint64(f.next()) << i
+	}
+	return u
+}
+
+func (f *filler) fill(v reflect.Value, depth int) {
+	if depth > maxDepth {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(f.next()&1 == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		shift := 64 - v.Type().Bits()
+		v.SetInt(int64(f.bits(v.Type().Bits())<<shift) >> shift)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(f.bits(v.Type().Bits()))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(float64(int16(f.bits(16))) / 4)
+	case reflect.String:
+		letters := make([]byte, f.next()%16)
+		for i := range letters {
+			letters[i] = 'a' + f.next()%26
+		}
+		v.SetString(string(letters))
+	case reflect.Pointer:
+		if f.next()&1 == 0 {
+			return
+		}
+		elem := reflect.New(v.Type().Elem())
+		f.fill(elem.Elem(), depth+1)
+		v.Set(elem)
+	case reflect.Slice:
+		n := int(f.next() % 4)
+		if n == 0 {
+			return
+		}
+		s := reflect.MakeSlice(v.Type(), n, n)
+		for i := 0; i < n; i++ {
+			f.fill(s.Index(i), depth+1)
+		}
+		v.Set(s)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			f.fill(v.Index(i), depth+1)
+		}
+	case reflect.Map:
+		n := int(f.next() % 4)
+		if n == 0 {
+			return
+		}
+		m := reflect.MakeMapWithSize(v.Type(), n)
+		for i := 0; i < n; i++ {
+			key := reflect.New(v.Type().Key()).Elem()
+			value := reflect.New(v.Type().Elem()).Elem()
+			f.fill(key, depth+1)
+			f.fill(value, depth+1)
+			m.SetMapIndex(key, value)
+		}
+		v.Set(m)
+	case reflect.Struct:
+		if v.Type() == timeType {
+			v.Set(reflect.ValueOf(time.Unix(int64(int32(f.bits(32))), 0).UTC()))
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				f.fill(v.Field(i), depth+1)
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package testfill
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type sample struct {
+	ID       int64
+	Small    int8
+	Count    uint16
+	Score    float64
+	Name     string
+	Active   bool
+	Tags     []string
+	Labels   map[string]int
+	Created  time.Time
+	Parent   *sample
+	Children []*sample
+	hidden   string
+}
+
+func TestFill(t *testing.T) {
+	data := []byte("\xfe\xff\xff\xff\xff\xff\xff\xff\x80\x01\x02abgen round trip fuzz input of some length")
+
+	var a, b sample
+	Fill(&a, data)
+	Fill(&b, data)
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("Fill is not deterministic:\n%+v\n%+v", a, b)
+	}
+	if a.ID != -2 {
+		t.Errorf("ID = %d, want -2", a.ID)
+	}
+	if a.Small != -128 {
+		t.Errorf("Small = %d, want -128", a.Small)
+	}
+	if a.hidden != "" {
+		t.Errorf("hidden = %q, want unexported fields left unset", a.hidden)
+	}
+	if a.Created.Location() != time.UTC || a.Created.Nanosecond() != 0 {
+		t.Errorf("Created = %v, want whole seconds in UTC", a.Created)
+	}
+}
+
+func TestFill_EmptyData(t *testing.T) {
+	var got sample
+	Fill(&got, nil)
+	if !reflect.DeepEqual(got, sample{Created: time.Unix(0, 0).UTC()}) {
+		t.Errorf("Fill(nil) = %+v, want zero values", got)
+	}
+	Fill(nil, []byte("ignored"))
+	Fill(got, []byte("ignored"))
+}
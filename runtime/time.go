@@ -0,0 +1,150 @@
+package runtime
+
+import (
+	"sync"
+	"time"
+)
+
+// ConvertStringToTime parses an RFC 3339 string into a time.Time.
+func ConvertStringToTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return t
+}
+
+// ConvertTimeToString formats a time.Time as an RFC 3339 string.
+func ConvertTimeToString(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+// zoneLocations caches the locations of the zones by name, as loading a named zone reads
+// the zoneinfo database.
+var zoneLocations sync.Map
+
+// loadLocation returns the location of the named zone, loading it once.
+func loadLocation(zone string) (*time.Location, error) {
+	if loc, ok := zoneLocations.Load(zone); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return nil, err
+	}
+	zoneLocations.Store(zone, loc)
+	return loc, nil
+}
+
+// InZone returns t in the named zone: "UTC", "Local" or an IANA name such as
+// "Asia/Shanghai". An empty or unknown zone returns t unchanged.
+func InZone(t time.Time, zone string) time.Time {
+	if zone == "" {
+		return t
+	}
+	loc, err := loadLocation(zone)
+	if err != nil {
+		return t
+	}
+	return t.In(loc)
+}
+
+// InZonePtr is InZone for optional times, returning nil for nil.
+func InZonePtr(t *time.Time, zone string) *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := InZone(*t, zone)
+	return &v
+}
+
+// FormatTime moves t to zone and formats it with layout.
+func FormatTime(t time.Time, layout, zone string) string {
+	return InZone(t, zone).Format(layout)
+}
+
+// FormatTimePtr is FormatTime for optional times, mapping nil to the empty string.
+func FormatTimePtr(t *time.Time, layout, zone string) string {
+	if t == nil {
+		return ""
+	}
+	return FormatTime(*t, layout, zone)
+}
+
+// ParseTime parses s with layout. When zone is set, values without zone information
+// are interpreted in that zone and the result is moved to it; otherwise it behaves
+// like time.Parse.
+func ParseTime(s, layout, zone string) (time.Time, error) {
+	if zone == "" {
+		return time.Parse(layout, s)
+	}
+	loc, err := loadLocation(zone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.ParseInLocation(layout, s, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(loc), nil
+}
+
+// ParseTimePtr is ParseTime for optional times, mapping the empty string to nil.
+func ParseTimePtr(s, layout, zone string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := ParseTime(s, layout, zone)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// TimeToUnix returns t as a Unix timestamp in unit, which is "s", "ms" or "ns".
+// The zero time maps to 0.
+func TimeToUnix(t time.Time, unit string) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	switch unit {
+	case "ms":
+		return t.UnixMilli()
+	case "ns":
+		return t.UnixNano()
+	default:
+		return t.Unix()
+	}
+}
+
+// TimePtrToUnix is TimeToUnix for optional times, mapping nil to 0.
+func TimePtrToUnix(t *time.Time, unit string) int64 {
+	if t == nil {
+		return 0
+	}
+	return TimeToUnix(*t, unit)
+}
+
+// UnixToTime converts a Unix timestamp in unit, which is "s", "ms" or "ns", to a time
+// in zone. 0 maps to the zero time.
+func UnixToTime(v int64, unit, zone string) time.Time {
+	if v == 0 {
+		return time.Time{}
+	}
+	var t time.Time
+	switch unit {
+	case "ms":
+		t = time.UnixMilli(v)
+	case "ns":
+		t = time.Unix(0, v)
+	default:
+		t = time.Unix(v, 0)
+	}
+	return InZone(t, zone)
+}
+
+// UnixToTimePtr is UnixToTime for optional times, mapping 0 to nil.
+func UnixToTimePtr(v int64, unit, zone string) *time.Time {
+	if v == 0 {
+		return nil
+	}
+	t := UnixToTime(v, unit, zone)
+	return &t
+}
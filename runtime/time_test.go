@@ -0,0 +1,94 @@
+package runtime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAndFormatTime(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  string
+		layout string
+		zone   string
+		want   string
+	}{
+		{name: "keeps offset without zone", input: "2024-05-01T10:00:00+08:00", layout: time.RFC3339, want: "2024-05-01T10:00:00+08:00"},
+		{name: "normalizes to UTC", input: "2024-05-01T10:00:00+08:00", layout: time.RFC3339, zone: "UTC", want: "2024-05-01T02:00:00Z"},
+		{name: "date interpreted in zone", input: "2024-05-01", layout: time.DateOnly, zone: "Asia/Shanghai", want: "2024-05-01"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parsed, err := ParseTime(tc.input, tc.layout, tc.zone)
+			if err != nil {
+				t.Fatalf("ParseTime() error = %v", err)
+			}
+			if got := FormatTime(parsed, tc.layout, tc.zone); got != tc.want {
+				t.Errorf("FormatTime() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	if _, err := ParseTime("not a time", time.RFC3339, ""); err == nil {
+		t.Error("ParseTime() expected an error for invalid input")
+	}
+}
+
+func TestOptionalTimes(t *testing.T) {
+	if got := FormatTimePtr(nil, time.RFC3339, ""); got != "" {
+		t.Errorf("FormatTimePtr(nil) = %q, want empty string", got)
+	}
+	if got, err := ParseTimePtr("", time.RFC3339, ""); got != nil || err != nil {
+		t.Errorf("ParseTimePtr(\"\") = %v, %v, want nil, nil", got, err)
+	}
+	if got := TimePtrToUnix(nil, "s"); got != 0 {
+		t.Errorf("TimePtrToUnix(nil) = %d, want 0", got)
+	}
+	if got := UnixToTimePtr(0, "s", ""); got != nil {
+		t.Errorf("UnixToTimePtr(0) = %v, want nil", got)
+	}
+}
+
+func TestUnixRoundTrip(t *testing.T) {
+	instant := time.Date(2024, 5, 1, 2, 0, 0, 123456789, time.UTC)
+	testCases := []struct {
+		unit string
+		want time.Time
+	}{
+		{unit: "s", want: instant.Truncate(time.Second)},
+		{unit: "ms", want: instant.Truncate(time.Millisecond)},
+		{unit: "ns", want: instant},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.unit, func(t *testing.T) {
+			got := UnixToTime(TimeToUnix(instant, tc.unit), tc.unit, "UTC")
+			if !got.Equal(tc.want) || got.Location() != time.UTC {
+				t.Errorf("round trip = %v, want %v in UTC", got, tc.want)
+			}
+		})
+	}
+
+	if got := TimeToUnix(time.Time{}, "s"); got != 0 {
+		t.Errorf("TimeToUnix(zero) = %d, want 0", got)
+	}
+	if got := UnixToTime(0, "s", ""); !got.IsZero() {
+		t.Errorf("UnixToTime(0) = %v, want the zero time", got)
+	}
+}
+
+func TestLoadLocation(t *testing.T) {
+	first, err := loadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Fatalf("loadLocation() error = %v", err)
+	}
+	second, err := loadLocation("Asia/Shanghai")
+	if err != nil || second != first {
+		t.Errorf("loadLocation() = %p, %v, want the cached location %p", second, err, first)
+	}
+	if _, err := loadLocation("Nowhere/Unknown"); err == nil {
+		t.Error("loadLocation() of an unknown zone succeeded")
+	}
+	if _, ok := zoneLocations.Load("Nowhere/Unknown"); ok {
+		t.Error("loadLocation() cached an unknown zone")
+	}
+}
@@ -0,0 +1,15 @@
+// Package uuidconv provides abgen runtime helpers for github.com/google/uuid.
+package uuidconv
+
+import "github.com/google/uuid"
+
+// ConvertStringToUUID parses a string into a uuid.UUID, returning the zero UUID if it is invalid.
+func ConvertStringToUUID(s string) uuid.UUID {
+	u, _ := uuid.Parse(s)
+	return u
+}
+
+// ConvertUUIDToString returns the string form of a uuid.UUID.
+func ConvertUUIDToString(u uuid.UUID) string {
+	return u.String()
+}
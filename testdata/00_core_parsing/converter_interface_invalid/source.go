@@ -0,0 +1,8 @@
+package converter_interface_invalid
+
+// Converter has a method that is not a conversion, which the analyzer reports.
+//
+//go:abgen:implement
+type Converter interface {
+	Merge(a, b string) string
+}
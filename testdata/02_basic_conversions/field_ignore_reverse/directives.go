@@ -0,0 +1,15 @@
+package field_ignore_reverse
+
+import (
+	_ "github.com/origadmin/abgen/testdata/02_basic_conversions/field_ignore_reverse/source"
+	_ "github.com/origadmin/abgen/testdata/02_basic_conversions/field_ignore_reverse/target"
+)
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/02_basic_conversions/field_ignore_reverse/source,alias=source
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/02_basic_conversions/field_ignore_reverse/target,alias=target
+
+//go:abgen:convert="source=source.Account,target=target.AccountDTO,ignore=Secret,remap=Name:Title"
+
+// Expected: ConvertAccountToAccountDTO sets Title from Name and leaves Secret out, while
+// ConvertAccountDTOToAccount sets Name from Title and converts Secret, which the ignore of
+// the rule does not name in that direction.
@@ -0,0 +1,7 @@
+package source
+
+type Account struct {
+	ID     int64
+	Name   string
+	Secret string
+}
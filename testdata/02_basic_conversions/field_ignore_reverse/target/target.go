@@ -0,0 +1,7 @@
+package target
+
+type AccountDTO struct {
+	ID     int64
+	Title  string
+	Secret string
+}
@@ -0,0 +1,12 @@
+package directives
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/compare_functions/source,alias=source
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/compare_functions/target,alias=target
+
+//go:abgen:convert="source=source.User,target=target.UserPB,ignore=Password,remap=Name:FullName"
+//go:abgen:compare:generate="true"
+
+// Expected: EqualUserAndUserPB and DiffUserAndUserPB compare the fields paired by the
+// converters, skipping Password and comparing Name to FullName. Profile is compared by
+// DiffProfileAndProfilePB, the elements of Roles and Teams by DiffRoleAndRolePB, and the
+// other fields after converting them as the converters do.
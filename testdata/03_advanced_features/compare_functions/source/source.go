@@ -0,0 +1,26 @@
+package source
+
+import "time"
+
+// User is the domain model of a user.
+type User struct {
+	ID        int64
+	Name      string
+	Password  string
+	CreatedAt time.Time
+	Tags      []string
+	Profile   *Profile
+	Roles     []*Role
+	Teams     map[string]Role
+}
+
+// Profile holds the optional details of a user.
+type Profile struct {
+	Bio string
+	Age int
+}
+
+// Role is a role granted to a user.
+type Role struct {
+	Name string
+}
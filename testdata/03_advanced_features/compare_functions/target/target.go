@@ -0,0 +1,23 @@
+package target
+
+// UserPB is the transport representation of a user.
+type UserPB struct {
+	ID        int64
+	FullName  string
+	CreatedAt string
+	Tags      []string
+	Profile   *ProfilePB
+	Roles     []*RolePB
+	Teams     map[string]RolePB
+}
+
+// ProfilePB is the transport representation of a profile.
+type ProfilePB struct {
+	Bio string
+	Age int32
+}
+
+// RolePB is the transport representation of a role.
+type RolePB struct {
+	Name string
+}
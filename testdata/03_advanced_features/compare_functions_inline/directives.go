@@ -0,0 +1,13 @@
+package directives
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/compare_functions/source,alias=source
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/compare_functions/target,alias=target
+
+//go:abgen:convert="source=source.User,target=target.UserPB,ignore=Password,remap=Name:FullName"
+//go:abgen:compare:generate="true"
+//go:abgen:convert:error="return"
+//go:abgen:helpers:mode="inline"
+
+// Expected: the comparison functions do not return the errors of the converters, comparing
+// Roles and Teams element by element, and FieldDiff and NestDiffs are declared in the
+// generated file.
@@ -0,0 +1,12 @@
+package directives
+
+import (
+	_ "github.com/origadmin/abgen/testdata/02_basic_conversions/field_ignore_remap/source"
+	_ "github.com/origadmin/abgen/testdata/02_basic_conversions/field_ignore_remap/target"
+)
+
+// Tests: the settings of abgen.yaml merged with the directives of the package
+// The packages, the conversion rule and its field rules come from abgen.yaml.
+
+// The directives take precedence over the settings of the configuration file.
+//go:abgen:convert:target:suffix="Model"
@@ -0,0 +1,55 @@
+package directives
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/origadmin/abgen/testdata/03_advanced_features/user_conversion_funcs/source"
+)
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/user_conversion_funcs/source,alias=source
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/user_conversion_funcs/target,alias=target
+
+//go:abgen:convert="source=source.Order,target=target.Order,direction=both"
+//go:abgen:convert:source:suffix="Source"
+//go:abgen:convert:target:suffix="Target"
+//go:abgen:convert:rule="source:source.Status,target:builtin.string,func:StatusLabel"
+//go:abgen:convert:rule="source:source.Money,target:builtin.int64,func:RoundedCents"
+
+// Expected:
+// 1. Status -> string is matched by two functions; the rule picks StatusLabel.
+// 2. Money -> int64 fields and slice elements use RoundedCents, named by the rule, rather
+//    than MoneyToCents, which has the same signature.
+// 3. The reverse conversions, without rules, use the functions discovered by their signature.
+
+// MoneyToCents converts an amount of money to cents.
+func MoneyToCents(m source.Money) int64 {
+	return int64(m.Amount * 100)
+}
+
+// RoundedCents converts an amount of money to cents, rounded to the nearest cent.
+func RoundedCents(m source.Money) int64 {
+	return int64(math.Round(m.Amount * 100))
+}
+
+// CentsToMoney converts cents to an amount of money.
+func CentsToMoney(cents int64) source.Money {
+	return source.Money{Amount: float64(cents) / 100}
+}
+
+// ParseStatus parses a status name.
+func ParseStatus(s string) (source.Status, error) {
+	var status source.Status
+	_, err := fmt.Sscan(s, &status)
+	return status, err
+}
+
+// StatusName returns the name of a status.
+func StatusName(s source.Status) string {
+	return fmt.Sprint(s)
+}
+
+// StatusLabel returns the display label of a status.
+func StatusLabel(s source.Status) string {
+	return "status " + fmt.Sprint(s)
+}
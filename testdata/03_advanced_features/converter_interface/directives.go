@@ -0,0 +1,24 @@
+package directives
+
+import (
+	"github.com/origadmin/abgen/testdata/03_advanced_features/converter_interface/source"
+	"github.com/origadmin/abgen/testdata/03_advanced_features/converter_interface/target"
+)
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/converter_interface/source,alias=source
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/converter_interface/target,alias=target
+
+// Converter declares the conversions between users and their wire representation.
+//
+// Expected: ConverterImpl implements Converter with methods named after the interface.
+// User and Role conversions are generated for the methods, and Status to State has no
+// conversion, so its stub is reported against Converter.UserToPB.
+//
+//go:abgen:implement
+type Converter interface {
+	UserToPB(*source.User) *target.UserPB
+	UserFromPB(*target.UserPB) (*source.User, error)
+	RolesToPB([]*source.Role) []*target.RolePB
+	RoleToPB(source.Role) target.RolePB
+	AgeToPB(int32) int64
+}
@@ -0,0 +1,18 @@
+package source
+
+// Status is the state of a user account.
+type Status int
+
+// User is the domain model of a user.
+type User struct {
+	ID     int64
+	Name   string
+	Age    int32
+	Status Status
+	Roles  []*Role
+}
+
+// Role is a role granted to a user.
+type Role struct {
+	Name string
+}
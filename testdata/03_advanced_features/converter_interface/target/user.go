@@ -0,0 +1,20 @@
+package target
+
+// State is the state of a user account as sent over the wire.
+type State struct {
+	Code string
+}
+
+// UserPB is the wire representation of a user.
+type UserPB struct {
+	ID     int64
+	Name   string
+	Age    int64
+	Status State
+	Roles  []*RolePB
+}
+
+// RolePB is the wire representation of a role.
+type RolePB struct {
+	Name string
+}
@@ -0,0 +1,21 @@
+package directives
+
+import (
+	"github.com/origadmin/abgen/testdata/03_advanced_features/converter_interface/source"
+	"github.com/origadmin/abgen/testdata/03_advanced_features/converter_interface/target"
+)
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/converter_interface/source,alias=source
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/converter_interface/target,alias=target
+
+//go:abgen:convert:error="return"
+
+// Expected: methods returning an error return the errors of the conversions they call,
+// with the zero value of their result type; the others discard them.
+//
+//go:abgen:implement=RoleMapper
+type RoleConverter interface {
+	RolesToPB([]*source.Role) ([]*target.RolePB, error)
+	RoleToPB(source.Role) (target.RolePB, error)
+	RoleFromPB(*target.RolePB) *source.Role
+}
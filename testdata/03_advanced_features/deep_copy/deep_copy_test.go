@@ -0,0 +1,28 @@
+//go:build !abgen_source
+
+package directives
+
+import (
+	"testing"
+
+	"github.com/origadmin/abgen/testdata/03_advanced_features/deep_copy/source"
+)
+
+// TestConvertOrderModelToOrderDTO checks that the fields of anonymous structs are copied,
+// so changing the source after the conversion leaves the target unchanged.
+func TestConvertOrderModelToOrderDTO(t *testing.T) {
+	from := &source.Order{}
+	from.Meta.Tags = []string{"new"}
+	from.Audit.Changes = [][]string{{"created"}}
+
+	to := ConvertOrderModelToOrderDTO(from)
+	from.Meta.Tags[0] = "changed"
+	from.Audit.Changes[0][0] = "changed"
+
+	if to.Meta.Tags[0] != "new" {
+		t.Errorf("to.Meta.Tags[0] = %q, want %q", to.Meta.Tags[0], "new")
+	}
+	if to.Audit.Changes[0][0] != "created" {
+		t.Errorf("to.Audit.Changes[0][0] = %q, want %q", to.Audit.Changes[0][0], "created")
+	}
+}
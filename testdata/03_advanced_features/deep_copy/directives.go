@@ -0,0 +1,12 @@
+package directives
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/deep_copy/source,alias=source
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/deep_copy/target,alias=target
+
+//go:abgen:convert="source=source.Order,target=target.Order"
+//go:abgen:convert:source:suffix="Model"
+//go:abgen:convert:target:suffix="DTO"
+//go:abgen:convert:copy="deep"
+
+// Expected: fields of identical types are deep copied, so slices, maps and pointers are
+// not shared between the source and the converted target.
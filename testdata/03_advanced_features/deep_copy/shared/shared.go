@@ -0,0 +1,18 @@
+package shared
+
+// Item is referenced by pointer.
+type Item struct {
+	SKU  string
+	Tags []string
+}
+
+// Line only holds values, so it is copied by assignment.
+type Line struct {
+	SKU      string
+	Quantity int
+}
+
+// Address is embedded by value and holds a slice.
+type Address struct {
+	Lines []string
+}
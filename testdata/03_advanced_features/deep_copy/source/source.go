@@ -0,0 +1,25 @@
+package source
+
+import (
+	"time"
+
+	"github.com/origadmin/abgen/testdata/03_advanced_features/deep_copy/shared"
+)
+
+// Order shares its field types with target.Order.
+type Order struct {
+	ID        int64
+	Tags      []string
+	Items     []*shared.Item
+	Lines     []shared.Line
+	Attrs     map[string]string
+	Index     map[string]*shared.Item
+	Primary   *shared.Item
+	Address   shared.Address
+	Note      *string
+	CreatedAt time.Time
+	Window    [2][]int
+	Meta      struct{ Tags []string }
+	Audit     struct{ Changes [][]string }
+	Extra     any
+}
@@ -0,0 +1,7 @@
+package directives
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/deepcopy_directive/source,alias=source
+
+//go:abgen:deepcopy="source.Tree,source.Labels"
+
+// Expected: DeepCopy functions are generated for the listed types and the types they reference.
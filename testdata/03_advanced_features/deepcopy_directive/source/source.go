@@ -0,0 +1,18 @@
+package source
+
+// Tree is a recursive structure.
+type Tree struct {
+	Name     string
+	Children []*Tree
+	Attrs    map[string][]string
+	Root     Node
+	Labels   Labels
+}
+
+// Node is held by value.
+type Node struct {
+	Path []string
+}
+
+// Labels is a named map type.
+type Labels map[string]string
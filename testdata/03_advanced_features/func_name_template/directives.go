@@ -0,0 +1,14 @@
+package directives
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/func_name_template/ent,alias=ent
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/func_name_template/pb,alias=pb
+
+//go:abgen:convert="source=ent.User,target=pb.User"
+//go:abgen:convert:target:suffix="PB"
+//go:abgen:convert:func:name="{{if eq .Direction "reverse"}}{{.Target}}FromProto{{else}}{{.Source}}ToProto{{end}}"
+
+// Expected:
+// 1. Struct and slice conversions are named by the template: UserToProto, UserFromProto,
+//    RolesToProto and RolesFromProto.
+// 2. The stubs converting Status are named by the template too: StatusToProto converts
+//    ent.Status to a string, and StatusFromProto a string to ent.Status.
@@ -0,0 +1,17 @@
+package ent
+
+// Status is the state of a user account.
+type Status int
+
+// User is the storage representation of a user.
+type User struct {
+	ID     int64
+	Name   string
+	Status Status
+	Roles  []*Role
+}
+
+// Role is the storage representation of a role.
+type Role struct {
+	Name string
+}
@@ -0,0 +1,14 @@
+package pb
+
+// User is the wire representation of a user.
+type User struct {
+	ID     int64
+	Name   string
+	Status string
+	Roles  []*Role
+}
+
+// Role is the wire representation of a role.
+type Role struct {
+	Name string
+}
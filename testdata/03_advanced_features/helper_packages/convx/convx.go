@@ -0,0 +1,38 @@
+// Package convx simulates a shared company package of conversion helpers.
+package convx
+
+import (
+	"time"
+
+	"github.com/origadmin/abgen/testdata/03_advanced_features/helper_packages/source"
+)
+
+// AmountToCents converts an amount to cents.
+func AmountToCents(a source.Amount) int64 {
+	return int64(a.Value * 100)
+}
+
+// CentsToAmount converts cents to an amount.
+func CentsToAmount(cents int64) source.Amount {
+	return source.Amount{Value: float64(cents) / 100}
+}
+
+// FormatTime takes precedence over the built-in time.Time -> string helper.
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// ParseTime takes precedence over the built-in string -> time.Time helper.
+func ParseTime(s string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+// TenantToString is shadowed by the function declared in the directive package.
+func TenantToString(t source.TenantID) string {
+	return string(t)
+}
+
+// tenantFromString is unexported and therefore never used.
+func tenantFromString(s string) source.TenantID {
+	return source.TenantID(s)
+}
@@ -0,0 +1,25 @@
+package directives
+
+import (
+	"strings"
+
+	"github.com/origadmin/abgen/testdata/03_advanced_features/helper_packages/source"
+)
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/helper_packages/source,alias=source
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/helper_packages/target,alias=target
+//go:abgen:helpers:package="github.com/origadmin/abgen/testdata/03_advanced_features/helper_packages/convx"
+
+//go:abgen:convert="source=source.Invoice,target=target.Invoice"
+//go:abgen:convert:source:suffix="Source"
+//go:abgen:convert:target:suffix="Target"
+//go:abgen:convert:time:layout="source.Invoice#DueAt=DateOnly"
+
+// Expected: Total and CreatedAt use the convx helpers, while Tenant uses TenantIDString
+// because functions in the directive package take precedence over helper packages. DueAt
+// uses the runtime time helpers, as its layout takes precedence over the convx helpers.
+
+// TenantIDString normalizes tenant IDs to lower case.
+func TenantIDString(t source.TenantID) string {
+	return strings.ToLower(string(t))
+}
@@ -0,0 +1,20 @@
+package source
+
+import "time"
+
+// Amount is a monetary amount in the domain model.
+type Amount struct {
+	Value float64
+}
+
+// TenantID identifies a tenant.
+type TenantID string
+
+// Invoice is the domain representation of an invoice.
+type Invoice struct {
+	ID        string
+	Total     Amount
+	Tenant    TenantID
+	CreatedAt time.Time
+	DueAt     time.Time
+}
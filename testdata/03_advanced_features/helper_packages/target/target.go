@@ -0,0 +1,10 @@
+package target
+
+// Invoice is the transport representation of an invoice.
+type Invoice struct {
+	ID        string
+	Total     int64
+	Tenant    string
+	CreatedAt string
+	DueAt     string
+}
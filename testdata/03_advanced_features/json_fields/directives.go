@@ -0,0 +1,12 @@
+package directives
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/json_fields/source,alias=source
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/json_fields/target,alias=target
+
+//go:abgen:convert="source=source.User,target=target.User"
+//go:abgen:convert:source:suffix="Model"
+//go:abgen:convert:target:suffix="DTO"
+//go:abgen:convert:json="source.User#Settings,Tags,Profile,Extra"
+//go:abgen:convert:error="return"
+
+// Expected: JSON columns are marshaled and unmarshaled, and invalid JSON is returned as an error.
@@ -0,0 +1,15 @@
+package source
+
+import (
+	"database/sql"
+	"encoding/json"
+)
+
+// User stores its nested data as JSON columns.
+type User struct {
+	ID       int64
+	Settings []byte
+	Tags     string
+	Profile  json.RawMessage
+	Extra    sql.NullString
+}
@@ -0,0 +1,21 @@
+package target
+
+// Settings holds user preferences.
+type Settings struct {
+	Theme    string `json:"theme"`
+	Language string `json:"language"`
+}
+
+// Profile holds public profile data.
+type Profile struct {
+	Bio string `json:"bio"`
+}
+
+// User is the API representation of a user.
+type User struct {
+	ID       int64
+	Settings Settings
+	Tags     []string
+	Profile  *Profile
+	Extra    map[string]any
+}
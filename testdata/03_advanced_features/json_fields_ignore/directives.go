@@ -0,0 +1,11 @@
+package directives
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/json_fields/source,alias=source
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/json_fields/target,alias=target
+
+//go:abgen:convert="source=source.User,target=target.User"
+//go:abgen:convert:source:suffix="Model"
+//go:abgen:convert:target:suffix="DTO"
+//go:abgen:convert:json="target.User#Settings"
+
+// Expected: only Settings is decoded as JSON and its errors are ignored; Tags falls back to a stub.
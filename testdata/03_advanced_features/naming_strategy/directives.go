@@ -0,0 +1,14 @@
+package directives
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/naming_strategy/ent,alias=ent
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/naming_strategy/pb,alias=pb
+
+//go:abgen:convert="source=ent.Person,target=pb.Person"
+//go:abgen:convert:target:suffix="pb"
+//go:abgen:naming:acronyms="ID,PB"
+//go:abgen:naming:plurals="Criterion:Criteria"
+
+// Expected:
+// 1. The pb suffix is written as the PB acronym: PersonPB, CategoryPB.
+// 2. Slices are named with English plurals: Categories, People, and Criteria from the
+//    plurals table, both in aliases and in function names.
@@ -0,0 +1,20 @@
+package ent
+
+// Person is the storage representation of a person.
+type Person struct {
+	ID         int64
+	Name       string
+	Categories []*Category
+	Friends    []*Person
+	Filters    []*Criterion
+}
+
+// Category is the storage representation of a category.
+type Category struct {
+	Name string
+}
+
+// Criterion is the storage representation of a search criterion.
+type Criterion struct {
+	Field string
+}
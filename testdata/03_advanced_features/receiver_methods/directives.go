@@ -0,0 +1,29 @@
+package directives
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/receiver_methods/pb,alias=pb
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/receiver_methods/model,alias=model
+
+//go:abgen:convert="source=UserDTO,target=pb.User"
+//go:abgen:convert="source=UserDTO,target=pb.UserSummary,direction=oneway"
+//go:abgen:convert="source=model.Account,target=pb.Account"
+//go:abgen:convert:methods="true"
+//go:abgen:convert:method:to="ToPB"
+//go:abgen:convert:method:from="FromPB"
+
+// Expected:
+// 1. UserDTO and RoleDTO get ToPB and FromPB methods, and the conversions of their fields
+//    and slices call them.
+// 2. UserDTO to pb.UserSummary would be a second ToPB method, so it stays a free function.
+// 3. model.Account and pb.Account are both foreign, so they are converted by free functions.
+
+// UserDTO is the user representation of the API layer.
+type UserDTO struct {
+	ID    int64
+	Name  string
+	Roles []*RoleDTO
+}
+
+// RoleDTO is the role representation of the API layer.
+type RoleDTO struct {
+	Name string
+}
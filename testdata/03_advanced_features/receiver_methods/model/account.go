@@ -0,0 +1,6 @@
+package model
+
+// Account is the domain model of an account.
+type Account struct {
+	Owner string
+}
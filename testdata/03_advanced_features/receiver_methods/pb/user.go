@@ -0,0 +1,24 @@
+package pb
+
+// User is the wire representation of a user.
+type User struct {
+	ID    int64
+	Name  string
+	Roles []*Role
+}
+
+// Role is the wire representation of a role.
+type Role struct {
+	Name string
+}
+
+// UserSummary is the short wire representation of a user.
+type UserSummary struct {
+	ID   int64
+	Name string
+}
+
+// Account is the wire representation of an account.
+type Account struct {
+	Owner string
+}
@@ -0,0 +1,12 @@
+package directives
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/round_trip_tests/source,alias=source
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/round_trip_tests/target,alias=target
+
+//go:abgen:convert="source=source.User,target=target.UserPB,ignore=Password,remap=Name:FullName"
+//go:abgen:test:generate="true"
+
+// Expected: the generated tests check ID, Name (through FullName), Age, Nickname, Tags,
+// UpdatedAt and Address after the round trip. Password is ignored, Score is narrowed to a
+// float32, CreatedAt is formatted as a string and Profile holds such a field, so they are
+// left out.
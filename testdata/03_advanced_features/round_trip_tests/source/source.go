@@ -0,0 +1,30 @@
+package source
+
+import "time"
+
+// User is the domain model of a user.
+type User struct {
+	ID        int64
+	Name      string
+	Password  string
+	Age       int32
+	Score     float64
+	Nickname  string
+	Tags      []string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Address   *Address
+	Profile   Profile
+}
+
+// Address is the postal address of a user.
+type Address struct {
+	Street string
+	Zip    uint16
+}
+
+// Profile holds the optional details of a user.
+type Profile struct {
+	Bio      string
+	JoinedAt time.Time
+}
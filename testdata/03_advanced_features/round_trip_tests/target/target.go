@@ -0,0 +1,29 @@
+package target
+
+import "time"
+
+// UserPB is the transport representation of a user.
+type UserPB struct {
+	ID        int64
+	FullName  string
+	Age       int64
+	Score     float32
+	Nickname  *string
+	Tags      []string
+	CreatedAt string
+	UpdatedAt time.Time
+	Address   *AddressPB
+	Profile   ProfilePB
+}
+
+// AddressPB is the transport representation of an address.
+type AddressPB struct {
+	Street string
+	Zip    uint32
+}
+
+// ProfilePB is the transport representation of a profile.
+type ProfilePB struct {
+	Bio      string
+	JoinedAt string
+}
@@ -0,0 +1,11 @@
+package directives
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/round_trip_tests/source,alias=source
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/round_trip_tests/target,alias=target
+
+//go:abgen:convert="source=source.User,target=target.UserPB,ignore=Password,remap=Name:FullName"
+//go:abgen:convert:error="return"
+//go:abgen:test:generate="true"
+
+// Expected: the round-trip tests skip inputs the forward conversion rejects and fail when
+// its result cannot be converted back.
@@ -0,0 +1,9 @@
+package source
+
+import "time"
+
+// Event is the domain representation of a scheduled event.
+type Event struct {
+	ID      string
+	StartAt time.Time
+}
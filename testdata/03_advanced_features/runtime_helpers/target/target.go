@@ -0,0 +1,7 @@
+package target
+
+// Event is the transport representation of a scheduled event.
+type Event struct {
+	ID      string
+	StartAt string
+}
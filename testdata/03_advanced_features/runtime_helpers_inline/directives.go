@@ -0,0 +1,11 @@
+package directives
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/runtime_helpers/source,alias=source
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/runtime_helpers/target,alias=target
+
+//go:abgen:convert="source=source.Event,target=target.Event"
+//go:abgen:convert:source:suffix="Source"
+//go:abgen:convert:target:suffix="Target"
+//go:abgen:helpers:mode="inline"
+
+// Expected: time.Time <-> string helpers are copied into the generated file.
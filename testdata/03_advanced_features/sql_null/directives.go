@@ -0,0 +1,11 @@
+package directives
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/sql_null/source,alias=source
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/sql_null/target,alias=target
+
+//go:abgen:convert="source=source.Account,target=target.Account"
+//go:abgen:convert:source:suffix="Row"
+//go:abgen:convert:target:suffix="DTO"
+
+// Expected: sql.Null* fields map Valid to nil or the zero value, and their wrapped values
+// are converted with the regular rules.
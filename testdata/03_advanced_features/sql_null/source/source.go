@@ -0,0 +1,16 @@
+package source
+
+import "database/sql"
+
+// Account mirrors a row model generated by sqlc.
+type Account struct {
+	ID        int64
+	Name      sql.NullString
+	Age       sql.NullInt32
+	Balance   sql.NullFloat64
+	Active    sql.NullBool
+	DeletedAt sql.NullTime
+	Nickname  sql.Null[string]
+	Score     sql.NullInt64
+	Level     sql.NullInt16
+}
@@ -0,0 +1,19 @@
+package target
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Account is the transport representation of an account.
+type Account struct {
+	ID        int64
+	Name      *string
+	Age       int32
+	Balance   float64
+	Active    *bool
+	DeletedAt *time.Time
+	Nickname  *string
+	Score     int32
+	Level     sql.Null[int64]
+}
@@ -0,0 +1,9 @@
+package directives
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/struct_map/source,alias=source
+
+//go:abgen:convert:map="source.User"
+//go:abgen:convert:map:key="json"
+
+// Expected: User is converted to and from map[string]any using json tag names as keys,
+// nested structs become nested maps and mismatched map values are reported as errors.
@@ -0,0 +1,25 @@
+package source
+
+import "time"
+
+// User is recorded in audit logs as a map.
+type User struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name,omitempty"`
+	Password  string    `json:"-"`
+	Profile   *Profile  `json:"profile"`
+	Address   Address   `json:"address"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+	Note      string
+}
+
+// Profile holds optional user details.
+type Profile struct {
+	Bio string `json:"bio"`
+}
+
+// Address is embedded by value.
+type Address struct {
+	City string `json:"city"`
+}
@@ -0,0 +1,12 @@
+package directives
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/struct_map_fields/source,alias=source
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/struct_map_fields/target,alias=target
+
+//go:abgen:convert="source=source.Event,target=target.Event"
+//go:abgen:convert:source:suffix="Model"
+//go:abgen:convert:target:suffix="DTO"
+//go:abgen:convert:error="return"
+
+// Expected: struct fields are converted to map fields and back, with string maps
+// formatting and parsing scalar values.
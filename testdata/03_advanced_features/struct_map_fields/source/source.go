@@ -0,0 +1,27 @@
+package source
+
+// Level is a named string type.
+type Level string
+
+// Event is an audit event with typed payloads.
+type Event struct {
+	ID     int64
+	Before Order
+	After  *Order
+	Labels Labels
+}
+
+// Order is the audited entity.
+type Order struct {
+	Number string
+	Total  float64
+}
+
+// Labels are flattened into a map[string]string.
+type Labels struct {
+	Level    Level
+	Attempts int
+	Ratio    float32
+	Retried  bool
+	Owner    *string
+}
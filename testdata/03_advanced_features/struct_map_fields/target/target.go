@@ -0,0 +1,9 @@
+package target
+
+// Event stores its payloads as free-form maps.
+type Event struct {
+	ID     int64
+	Before map[string]any
+	After  map[string]any
+	Labels map[string]string
+}
@@ -0,0 +1,9 @@
+package directives
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/struct_map/source,alias=source
+
+//go:abgen:convert="source=source.User,target=map[string]any,remap=Name:full_name,ignore=Tags"
+//go:abgen:convert:map:key="json"
+
+// Expected: Name is stored under the full_name key it is remapped to and read back from it,
+// and Tags is left out of the map, as the rule ignores it in that direction.
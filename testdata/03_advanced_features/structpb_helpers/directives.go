@@ -0,0 +1,11 @@
+package directives
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/structpb_helpers/source,alias=source
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/structpb_helpers/target,alias=target
+
+//go:abgen:convert="source=source.Resource,target=target.Resource"
+//go:abgen:convert:source:suffix="Source"
+//go:abgen:convert:target:suffix="PB"
+//go:abgen:convert:error="return"
+
+// Expected: free-form metadata uses the structpb helpers, and their errors are returned.
@@ -0,0 +1,10 @@
+package source
+
+// Resource is the domain representation of a resource with free-form metadata.
+type Resource struct {
+	ID       string
+	Metadata map[string]any
+	Labels   map[string]interface{}
+	Default  any
+	Items    []any
+}
@@ -0,0 +1,12 @@
+package target
+
+import "google.golang.org/protobuf/types/known/structpb"
+
+// Resource mirrors a protobuf message carrying free-form metadata.
+type Resource struct {
+	ID       string
+	Metadata *structpb.Struct
+	Labels   *structpb.Struct
+	Default  *structpb.Value
+	Items    *structpb.ListValue
+}
@@ -0,0 +1,17 @@
+package directives
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/time_options/source,alias=source
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/time_options/target,alias=target
+
+//go:abgen:convert="source=source.Event,target=target.Event"
+//go:abgen:convert:source:suffix="Source"
+//go:abgen:convert:target:suffix="Target"
+
+//go:abgen:convert:time:layout="RFC3339Nano"
+//go:abgen:convert:time:layout="source.Event#Birthday=DateOnly"
+//go:abgen:convert:time:layout="target.Event#EndAt=2006-01-02 15:04"
+//go:abgen:convert:time:unix="source.Event#UpdatedAt=ms"
+//go:abgen:convert:time:zone="UTC"
+
+// Expected: times use RFC3339Nano in UTC, except Birthday (DateOnly) and EndAt (literal layout);
+// CreatedAt is stored in Unix seconds and UpdatedAt in Unix milliseconds.
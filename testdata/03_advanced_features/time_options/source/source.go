@@ -0,0 +1,14 @@
+package source
+
+import "time"
+
+// Event is the domain representation of a scheduled event.
+type Event struct {
+	ID        string
+	StartAt   time.Time
+	Birthday  time.Time
+	EndAt     *time.Time
+	CreatedAt time.Time
+	UpdatedAt *time.Time
+	DeletedAt time.Time
+}
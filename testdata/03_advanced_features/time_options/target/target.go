@@ -0,0 +1,14 @@
+package target
+
+import "time"
+
+// Event is the transport representation of a scheduled event.
+type Event struct {
+	ID        string
+	StartAt   string
+	Birthday  string
+	EndAt     string
+	CreatedAt int64
+	UpdatedAt int64
+	DeletedAt time.Time
+}
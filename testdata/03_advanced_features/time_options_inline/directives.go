@@ -0,0 +1,16 @@
+package directives
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/time_options/source,alias=source
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/time_options/target,alias=target
+
+//go:abgen:convert="source=source.Event,target=target.Event"
+//go:abgen:convert:source:suffix="Source"
+//go:abgen:convert:target:suffix="Target"
+
+//go:abgen:convert:time:layout="DateTime"
+//go:abgen:convert:time:zone="Asia/Shanghai"
+//go:abgen:convert:error="return"
+//go:abgen:helpers:mode="inline"
+
+// Expected: the time helpers and the helpers they call are copied into the generated file,
+// and parse errors are returned.
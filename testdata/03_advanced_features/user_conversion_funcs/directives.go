@@ -0,0 +1,50 @@
+package directives
+
+import (
+	"fmt"
+
+	"github.com/origadmin/abgen/testdata/03_advanced_features/user_conversion_funcs/source"
+)
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/user_conversion_funcs/source,alias=source
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/user_conversion_funcs/target,alias=target
+
+//go:abgen:convert="source=source.Order,target=target.Order,direction=both"
+//go:abgen:convert:source:suffix="Source"
+//go:abgen:convert:target:suffix="Target"
+
+// Expected:
+// 1. Money <-> int64 fields and slice elements use MoneyToCents/CentsToMoney, whatever their names.
+// 2. ParseStatus returns an error, which is discarded in the default error mode.
+// 3. Status -> string is matched by two functions, so it is ambiguous and falls back to a stub.
+
+// MoneyToCents converts an amount of money to cents.
+func MoneyToCents(m source.Money) int64 {
+	return int64(m.Amount * 100)
+}
+
+// CentsToMoney converts cents to an amount of money.
+func CentsToMoney(cents int64) source.Money {
+	return source.Money{Amount: float64(cents) / 100}
+}
+
+// ParseStatus parses a status name.
+func ParseStatus(s string) (source.Status, error) {
+	switch s {
+	case "active":
+		return 1, nil
+	case "inactive":
+		return 2, nil
+	}
+	return 0, fmt.Errorf("unknown status %q", s)
+}
+
+// StatusName returns the name of a status.
+func StatusName(s source.Status) string {
+	return fmt.Sprint(s)
+}
+
+// StatusLabel returns the display label of a status.
+func StatusLabel(s source.Status) string {
+	return fmt.Sprint(s)
+}
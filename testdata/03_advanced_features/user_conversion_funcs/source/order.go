@@ -0,0 +1,18 @@
+package source
+
+// Money is an amount of money in major units.
+type Money struct {
+	Amount   float64
+	Currency string
+}
+
+// Status is an order status code.
+type Status int
+
+// Order represents an order in the source system.
+type Order struct {
+	ID     int
+	Price  Money
+	Status Status
+	Items  []Money
+}
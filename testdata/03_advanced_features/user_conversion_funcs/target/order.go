@@ -0,0 +1,9 @@
+package target
+
+// Order represents an order in the target system.
+type Order struct {
+	ID     int
+	Price  int64
+	Status string
+	Items  []int64
+}
@@ -0,0 +1,33 @@
+package directives
+
+import (
+	"fmt"
+
+	"github.com/origadmin/abgen/testdata/03_advanced_features/user_conversion_funcs/source"
+)
+
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/user_conversion_funcs/source,alias=source
+//go:abgen:package:path=github.com/origadmin/abgen/testdata/03_advanced_features/user_conversion_funcs/target,alias=target
+
+//go:abgen:convert="source=target.Order,target=source.Order,direction=oneway"
+//go:abgen:convert:source:suffix="Target"
+//go:abgen:convert:target:suffix="Source"
+//go:abgen:convert:error="return"
+
+// Expected: converters return (T, error) and errors from ParseCents and ParseStatus are returned.
+
+// ParseCents converts cents to an amount of money, rejecting negative amounts.
+func ParseCents(cents int64) (source.Money, error) {
+	if cents < 0 {
+		return source.Money{}, fmt.Errorf("negative amount %d", cents)
+	}
+	return source.Money{Amount: float64(cents) / 100}, nil
+}
+
+// ParseStatus parses a status name.
+func ParseStatus(s string) (source.Status, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty status")
+	}
+	return 1, nil
+}